@@ -0,0 +1,312 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nirajan1111/routiney/bs"
+	db "github.com/nirajan1111/routiney/db/sqlc"
+)
+
+type academicTermRequest struct {
+	Year int32  `json:"year" binding:"required"`
+	Term int16  `json:"term" binding:"required,min=1"`
+	Name string `json:"name" binding:"max=50"`
+	// StartDate and EndDate are AD dates. The BS dates are kept as written,
+	// and worked out from the AD ones when left out.
+	StartDate   string `json:"start_date" binding:"required"`
+	EndDate     string `json:"end_date" binding:"required"`
+	StartDateBs string `json:"start_date_bs" binding:"max=10"`
+	EndDateBs   string `json:"end_date_bs" binding:"max=10"`
+}
+
+type listAcademicTermsRequest struct {
+	Year int32 `form:"year"`
+}
+
+type academicTermResponse struct {
+	ID          int64  `json:"id"`
+	Year        int32  `json:"year"`
+	Term        int16  `json:"term"`
+	Name        string `json:"name"`
+	StartDate   string `json:"start_date"`
+	EndDate     string `json:"end_date"`
+	StartDateBs string `json:"start_date_bs,omitempty"`
+	EndDateBs   string `json:"end_date_bs,omitempty"`
+	Schedules   *int64 `json:"schedules,omitempty"`
+}
+
+func newAcademicTermResponse(t db.AcademicTerm) academicTermResponse {
+	res := academicTermResponse{
+		ID:          t.ID,
+		Year:        t.Year,
+		Term:        t.Term,
+		Name:        t.Name,
+		StartDate:   t.StartDate.Format(dateLayout),
+		EndDate:     t.EndDate.Format(dateLayout),
+		StartDateBs: t.StartDateBs.String,
+		EndDateBs:   t.EndDateBs.String,
+	}
+	if !t.StartDateBs.Valid {
+		res.StartDateBs = bsDate(t.StartDate)
+	}
+	if !t.EndDateBs.Valid {
+		res.EndDateBs = bsDate(t.EndDate)
+	}
+	return res
+}
+
+// params validates the request's dates and names the term "Term n" when no
+// name is given.
+func (req academicTermRequest) params() (db.CreateAcademicTermParams, error) {
+	start, err := parseDate("start_date", req.StartDate)
+	if err != nil {
+		return db.CreateAcademicTermParams{}, err
+	}
+	end, err := parseDate("end_date", req.EndDate)
+	if err != nil {
+		return db.CreateAcademicTermParams{}, err
+	}
+	if end.Before(start) {
+		return db.CreateAcademicTermParams{}, fmt.Errorf("end_date is before start_date")
+	}
+	if req.Name == "" {
+		req.Name = fmt.Sprintf("Term %d", req.Term)
+	}
+	if req.StartDateBs == "" {
+		req.StartDateBs = bsDate(start)
+	}
+	if req.EndDateBs == "" {
+		req.EndDateBs = bsDate(end)
+	}
+	return db.CreateAcademicTermParams{
+		Year:        req.Year,
+		Term:        req.Term,
+		Name:        req.Name,
+		StartDate:   start,
+		EndDate:     end,
+		StartDateBs: StringToSQLNullString(req.StartDateBs),
+		EndDateBs:   StringToSQLNullString(req.EndDateBs),
+	}, nil
+}
+
+// termFromQuery reads the ?term= query parameter. Without it the term is
+// the one running on date (today when date is zero) if that falls in year,
+// and the year's first term otherwise.
+func (server *Server) termFromQuery(ctx *gin.Context, year int32, date time.Time) (int16, error) {
+	if termStr := ctx.Query("term"); termStr != "" {
+		term, err := strconv.Atoi(termStr)
+		if err != nil || term < 1 {
+			return 0, fmt.Errorf("invalid term")
+		}
+		return int16(term), nil
+	}
+	return server.termOn(ctx, year, date)
+}
+
+// termOn returns the term of year running on date. When no term set up
+// covers the date it falls back to the half of the BS year the date is in,
+// and to 1 for dates outside year.
+func (server *Server) termOn(ctx *gin.Context, year int32, date time.Time) (int16, error) {
+	if date.IsZero() {
+		date = time.Now()
+	}
+	t, err := server.store.GetAcademicTermOn(ctx, date)
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && t.Year != year) {
+		if d, err := bs.FromAD(date); err == nil && int32(d.Year) == year {
+			return int16(d.Term()), nil
+		}
+		return 1, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return t.Term, nil
+}
+
+// checkTerm makes sure a term has been set up before schedules are written
+// to it.
+func (server *Server) checkTerm(ctx *gin.Context, year int32, term int16) (int, error) {
+	_, err := server.store.GetAcademicTerm(ctx, db.GetAcademicTermParams{Year: year, Term: term})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return http.StatusNotFound, fmt.Errorf("term %d of %d has not been set up", term, year)
+		}
+		return http.StatusInternalServerError, err
+	}
+	return http.StatusOK, nil
+}
+
+// academicTerm returns a term of year. A term that has not been set up runs
+// over its half of the BS year, see bs.TermBounds, so views of an empty
+// term still work.
+func (server *Server) academicTerm(ctx *gin.Context, year int32, term int16) (db.AcademicTerm, error) {
+	t, err := server.store.GetAcademicTerm(ctx, db.GetAcademicTermParams{Year: year, Term: term})
+	if errors.Is(err, sql.ErrNoRows) {
+		start, end := bs.TermBounds(int(year), int(term))
+		return db.AcademicTerm{Year: year, Term: term, Name: fmt.Sprintf("Term %d", term), StartDate: start, EndDate: end}, nil
+	}
+	return t, err
+}
+
+// listAcademicTerms lists the terms, of one ?year= or all of them, with the
+// number of draft schedules in each.
+func (server *Server) listAcademicTerms(ctx *gin.Context) {
+	var req listAcademicTermsRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	terms, err := server.store.ListAcademicTerms(ctx, sql.NullInt32{Int32: req.Year, Valid: req.Year != 0})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	res := make([]academicTermResponse, 0, len(terms))
+	for _, t := range terms {
+		term := newAcademicTermResponse(db.AcademicTerm{
+			ID:          t.ID,
+			Year:        t.Year,
+			Term:        t.Term,
+			Name:        t.Name,
+			StartDate:   t.StartDate,
+			EndDate:     t.EndDate,
+			StartDateBs: t.StartDateBs,
+			EndDateBs:   t.EndDateBs,
+		})
+		term.Schedules = &t.Schedules
+		res = append(res, term)
+	}
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (server *Server) createAcademicTerm(ctx *gin.Context) {
+	var req academicTermRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	adminStatus, err := checkAdmin(ctx, "admin")
+	if err != nil {
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return
+	}
+	if !adminStatus {
+		ctx.JSON(http.StatusForbidden, errorResponse(fmt.Errorf("not authorized to edit academic terms")))
+		return
+	}
+
+	arg, err := req.params()
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	term, err := server.store.CreateAcademicTerm(ctx, arg)
+	if err != nil {
+		if isUniqueViolation(err) {
+			ctx.JSON(http.StatusConflict, errorResponse(fmt.Errorf("term %d of %d already exists", req.Term, req.Year)))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	ctx.JSON(http.StatusOK, newAcademicTermResponse(term))
+}
+
+// updateAcademicTerm renames a term or moves its dates. The year and term
+// number identify its schedules and cannot be changed.
+func (server *Server) updateAcademicTerm(ctx *gin.Context) {
+	var uri getScheduleRequest
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	var req academicTermRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	adminStatus, err := checkAdmin(ctx, "admin")
+	if err != nil {
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return
+	}
+	if !adminStatus {
+		ctx.JSON(http.StatusForbidden, errorResponse(fmt.Errorf("not authorized to edit academic terms")))
+		return
+	}
+
+	current, err := server.store.GetAcademicTermByID(ctx, uri.ID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(http.StatusNotFound, errorResponse(fmt.Errorf("academic term not found")))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	if req.Year != current.Year || req.Term != current.Term {
+		ctx.JSON(http.StatusBadRequest, errorResponse(fmt.Errorf("the year and term of an academic term cannot be changed")))
+		return
+	}
+
+	arg, err := req.params()
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	term, err := server.store.UpdateAcademicTerm(ctx, db.UpdateAcademicTermParams{
+		ID:          uri.ID,
+		Name:        arg.Name,
+		StartDate:   arg.StartDate,
+		EndDate:     arg.EndDate,
+		StartDateBs: arg.StartDateBs,
+		EndDateBs:   arg.EndDateBs,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	ctx.JSON(http.StatusOK, newAcademicTermResponse(term))
+}
+
+func (server *Server) deleteAcademicTerm(ctx *gin.Context) {
+	var uri getScheduleRequest
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	adminStatus, err := checkAdmin(ctx, "admin")
+	if err != nil {
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return
+	}
+	if !adminStatus {
+		ctx.JSON(http.StatusForbidden, errorResponse(fmt.Errorf("not authorized to edit academic terms")))
+		return
+	}
+
+	n, err := server.store.DeleteAcademicTerm(ctx, uri.ID)
+	if err != nil {
+		if isForeignKeyViolation(err) {
+			ctx.JSON(http.StatusConflict, errorResponse(fmt.Errorf("the term still has classes scheduled")))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	if n == 0 {
+		ctx.JSON(http.StatusNotFound, errorResponse(fmt.Errorf("academic term not found")))
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"message": "Academic term deleted successfully"})
+}
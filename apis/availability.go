@@ -0,0 +1,151 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/nirajan1111/routiney/db/sqlc"
+	"github.com/nirajan1111/routiney/timeslot"
+)
+
+type createAvailabilityRequest struct {
+	Kind     string `json:"kind" binding:"required,oneof=unavailable preferred disliked"`
+	TimeSlot string `json:"time_slot" binding:"required"`
+	Note     string `json:"note"`
+}
+
+type availabilityResponse struct {
+	ID           int64     `json:"id"`
+	TeacherEmail string    `json:"teacher_email"`
+	Kind         string    `json:"kind"`
+	TimeSlot     string    `json:"time_slot"`
+	Note         string    `json:"note,omitempty"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+func newAvailabilityResponse(a db.TeacherAvailability) availabilityResponse {
+	return availabilityResponse{
+		ID:           a.ID,
+		TeacherEmail: a.TeacherEmail,
+		Kind:         string(a.Kind),
+		TimeSlot:     timeslot.TimeSlot{Day: timeslot.Day(a.DayOfWeek), Start: a.StartMinute, End: a.EndMinute}.String(),
+		Note:         a.Note.String,
+		CreatedAt:    a.CreatedAt,
+	}
+}
+
+// availabilityTeacher resolves whose availability a request is about: the
+// :email parameter, or the caller on the /get_me_teacher routes. Anyone may
+// read it; only the teacher themselves or an admin may change it.
+func availabilityTeacher(ctx *gin.Context, write bool) (string, bool) {
+	payload, err := payloadFromContext(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return "", false
+	}
+	email := ctx.Param("email")
+	if email == "" {
+		return payload.Email, true
+	}
+	if write && email != payload.Email {
+		adminStatus, err := checkAdmin(ctx, "admin")
+		if err != nil {
+			ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+			return "", false
+		}
+		if !adminStatus {
+			ctx.JSON(http.StatusForbidden, errorResponse(fmt.Errorf("not authorized to change the availability of %s", email)))
+			return "", false
+		}
+	}
+	return email, true
+}
+
+func (server *Server) listTeacherAvailability(ctx *gin.Context) {
+	email, ok := availabilityTeacher(ctx, false)
+	if !ok {
+		return
+	}
+
+	entries, err := server.store.ListTeacherAvailability(ctx, email)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	res := make([]availabilityResponse, 0, len(entries))
+	for _, a := range entries {
+		res = append(res, newAvailabilityResponse(a))
+	}
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (server *Server) createTeacherAvailability(ctx *gin.Context) {
+	var req createAvailabilityRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	email, ok := availabilityTeacher(ctx, true)
+	if !ok {
+		return
+	}
+
+	slot, err := timeslot.Parse(req.TimeSlot)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	if _, err := server.store.GetTeacherByEmail(ctx, email); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(http.StatusNotFound, errorResponse(fmt.Errorf("teacher not found")))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	entry, err := server.store.CreateTeacherAvailability(ctx, db.CreateTeacherAvailabilityParams{
+		TeacherEmail: email,
+		Kind:         db.AvailabilityKind(req.Kind),
+		DayOfWeek:    int16(slot.Day),
+		StartMinute:  slot.Start,
+		EndMinute:    slot.End,
+		Note:         StringToSQLNullString(req.Note),
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	ctx.JSON(http.StatusOK, newAvailabilityResponse(entry))
+}
+
+func (server *Server) deleteTeacherAvailability(ctx *gin.Context) {
+	id, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(fmt.Errorf("invalid availability ID")))
+		return
+	}
+	email, ok := availabilityTeacher(ctx, true)
+	if !ok {
+		return
+	}
+
+	n, err := server.store.DeleteTeacherAvailability(ctx, db.DeleteTeacherAvailabilityParams{
+		ID:           id,
+		TeacherEmail: email,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	if n == 0 {
+		ctx.JSON(http.StatusNotFound, errorResponse(fmt.Errorf("availability entry not found")))
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"message": "Availability entry deleted successfully"})
+}
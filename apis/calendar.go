@@ -0,0 +1,292 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/nirajan1111/routiney/db/sqlc"
+	"github.com/nirajan1111/routiney/ical"
+	"github.com/nirajan1111/routiney/timeslot"
+	"github.com/nirajan1111/routiney/token"
+	"github.com/nirajan1111/routiney/utils"
+)
+
+// calendarTokenBytes is the entropy of a feed token, hex encoded to twice
+// as many characters.
+const calendarTokenBytes = 24
+
+type calendarTokenResponse struct {
+	Token string            `json:"token"`
+	Feeds map[string]string `json:"feeds"`
+}
+
+func newCalendarTokenResponse(t db.CalendarToken) calendarTokenResponse {
+	prefix := "/calendar/" + t.Token
+	return calendarTokenResponse{
+		Token: t.Token,
+		Feeds: map[string]string{
+			"teacher": prefix + "/teacher/{email}.ics",
+			"room":    prefix + "/room/{room_id}.ics",
+			"group":   prefix + "/group/{group_id}.ics",
+		},
+	}
+}
+
+func payloadFromContext(ctx *gin.Context) (*token.Payload, error) {
+	payload, ok := ctx.Value("user").(*token.Payload)
+	if !ok {
+		return nil, fmt.Errorf("not authenticated")
+	}
+	return payload, nil
+}
+
+// getCalendarToken returns the caller's feed token, creating one on first use.
+func (server *Server) getCalendarToken(ctx *gin.Context) {
+	payload, err := payloadFromContext(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return
+	}
+
+	calendarToken, err := server.store.GetCalendarTokenByEmail(ctx, payload.Email)
+	if err == nil {
+		ctx.JSON(http.StatusOK, newCalendarTokenResponse(calendarToken))
+		return
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	server.issueCalendarToken(ctx, payload.Email)
+}
+
+// rotateCalendarToken replaces the caller's feed token, so that every feed
+// URL shared before stops working.
+func (server *Server) rotateCalendarToken(ctx *gin.Context) {
+	payload, err := payloadFromContext(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return
+	}
+	server.issueCalendarToken(ctx, payload.Email)
+}
+
+func (server *Server) issueCalendarToken(ctx *gin.Context, email string) {
+	secret, err := utils.RandomToken(calendarTokenBytes)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	calendarToken, err := server.store.UpsertCalendarToken(ctx, db.UpsertCalendarTokenParams{
+		Email: email,
+		Token: secret,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	ctx.JSON(http.StatusOK, newCalendarTokenResponse(calendarToken))
+}
+
+// checkCalendarToken aborts with 404 unless the :token parameter is a live
+// feed token. Unknown tokens are not distinguished from unknown feeds.
+func (server *Server) checkCalendarToken(ctx *gin.Context) bool {
+	_, err := server.store.GetCalendarToken(ctx, ctx.Param("token"))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(http.StatusNotFound, errorResponse(fmt.Errorf("calendar not found")))
+			return false
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return false
+	}
+	return true
+}
+
+// feedParam reads a path parameter, dropping the optional .ics extension
+// calendar apps like to see.
+func feedParam(ctx *gin.Context, name string) string {
+	return strings.TrimSuffix(ctx.Param(name), ".ics")
+}
+
+func (server *Server) teacherCalendar(ctx *gin.Context) {
+	if !server.checkCalendarToken(ctx) {
+		return
+	}
+	year, err := yearFromQuery(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	term, err := server.termFromQuery(ctx, year, time.Time{})
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	email := feedParam(ctx, "email")
+
+	schedules, err := server.teacherSchedules(ctx, email, year, term, false)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	name := email
+	if len(schedules) > 0 && schedules[0].TeacherName != "" {
+		name = schedules[0].TeacherName
+	}
+	server.writeCalendar(ctx, "Routine "+name, year, term, schedules)
+}
+
+func (server *Server) roomCalendar(ctx *gin.Context) {
+	if !server.checkCalendarToken(ctx) {
+		return
+	}
+	year, err := yearFromQuery(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	term, err := server.termFromQuery(ctx, year, time.Time{})
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	roomID, err := strconv.ParseInt(feedParam(ctx, "room_id"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(fmt.Errorf("invalid room ID")))
+		return
+	}
+
+	schedules, err := server.roomSchedules(ctx, roomID, year, term, false)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	t, err := server.academicTerm(ctx, year, term)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	bookings, err := server.roomBookings(ctx, roomID, t.StartDate, t.EndDate)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	name := "room " + strconv.FormatInt(roomID, 10)
+	if len(schedules) > 0 && schedules[0].RoomCode != "" {
+		name = schedules[0].RoomCode
+	}
+	server.writeCalendar(ctx, "Routine "+name, year, term, schedules, bookingEvents(bookings)...)
+}
+
+func (server *Server) groupCalendar(ctx *gin.Context) {
+	if !server.checkCalendarToken(ctx) {
+		return
+	}
+	year, err := yearFromQuery(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	term, err := server.termFromQuery(ctx, year, time.Time{})
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	groupID, err := strconv.ParseInt(feedParam(ctx, "group_id"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(fmt.Errorf("invalid group ID")))
+		return
+	}
+
+	schedules, err := server.groupSchedules(ctx, groupID, year, term, false)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	name := "group " + strconv.FormatInt(groupID, 10)
+	// combined classes booked for another section carry its name
+	for _, s := range schedules {
+		if s.GroupID == groupID && s.GroupName != "" {
+			name = s.GroupName
+			break
+		}
+	}
+	server.writeCalendar(ctx, "Routine "+name, year, term, schedules)
+}
+
+func newCalendarEvent(s detailedScheduleResponse, slot timeslot.TimeSlot) ical.Event {
+	summary := strings.TrimSpace(s.SubjectCode + " " + s.SubjectName)
+	group := sectionNames(s)
+	if s.SubGroupName != "" {
+		group = strings.TrimSpace(group + " " + s.SubGroupName)
+	}
+	if group != "" {
+		summary += " (" + group + ")"
+	}
+	location := s.RoomCode
+	if s.BlockNo != "" {
+		location += ", Block " + s.BlockNo
+	}
+	var description []string
+	if s.TeacherName != "" {
+		description = append(description, "Teacher: "+s.TeacherName)
+	}
+	if group != "" {
+		description = append(description, "Group: "+group)
+	}
+	return ical.Event{
+		UID:         fmt.Sprintf("schedule-%d@routiney", s.ID),
+		Summary:     summary,
+		Location:    location,
+		Description: strings.Join(description, "\n"),
+		Slot:        slot,
+	}
+}
+
+// writeCalendar serves the schedules as a weekly feed for the academic
+// term, leaving out holidays, exam weeks and closures. Dated events, such as
+// room bookings, are added as they are.
+func (server *Server) writeCalendar(ctx *gin.Context, name string, year int32, term int16, schedules []detailedScheduleResponse, dated ...ical.Event) {
+	t, err := server.academicTerm(ctx, year, term)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	cal := ical.Calendar{
+		Name:  fmt.Sprintf("%s %d %s", name, year, t.Name),
+		Start: t.StartDate,
+		End:   t.EndDate,
+	}
+	holidays, err := server.noClassDays(ctx, year)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	for _, h := range holidays {
+		cal.Skip = append(cal.Skip, ical.DateRange{Start: h.StartDate, End: h.EndDate})
+	}
+	for _, s := range schedules {
+		slot, err := timeslot.Parse(s.TimeSlot)
+		if err != nil {
+			continue
+		}
+		cal.Events = append(cal.Events, newCalendarEvent(s, slot))
+	}
+	cal.Events = append(cal.Events, dated...)
+
+	var buf bytes.Buffer
+	if err := ical.Write(&buf, cal); err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	ctx.Header("Content-Disposition", `inline; filename="routine.ics"`)
+	ctx.Data(http.StatusOK, "text/calendar; charset=utf-8", buf.Bytes())
+}
@@ -0,0 +1,300 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nirajan1111/routiney/bs"
+	"github.com/nirajan1111/routiney/curriculum"
+	db "github.com/nirajan1111/routiney/db/sqlc"
+)
+
+type curriculumRequirementRequest struct {
+	Program        string `json:"program" binding:"required,max=10"`
+	Semester       int32  `json:"semester" binding:"required,min=1,max=12"`
+	SubjectID      int64  `json:"subject_id" binding:"required,min=1"`
+	SessionType    string `json:"session_type" binding:"omitempty,oneof=lecture lab tutorial"`
+	PeriodsPerWeek int32  `json:"periods_per_week" binding:"required,min=1"`
+}
+
+type listCurriculumRequest struct {
+	Program  string `form:"program"`
+	Semester int32  `form:"semester" binding:"min=0,max=12"`
+}
+
+type curriculumRequirementResponse struct {
+	ID             int64  `json:"id"`
+	Program        string `json:"program"`
+	Semester       int32  `json:"semester"`
+	SubjectID      int64  `json:"subject_id"`
+	SubjectCode    string `json:"subject_code,omitempty"`
+	SubjectName    string `json:"subject_name,omitempty"`
+	SessionType    string `json:"session_type"`
+	PeriodsPerWeek int32  `json:"periods_per_week"`
+}
+
+func (server *Server) listCurriculumRequirements(ctx *gin.Context) {
+	var req listCurriculumRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	rows, err := server.store.ListCurriculumRequirements(ctx, db.ListCurriculumRequirementsParams{
+		Program:  StringToSQLNullString(req.Program),
+		Semester: sql.NullInt32{Int32: req.Semester, Valid: req.Semester != 0},
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	res := make([]curriculumRequirementResponse, 0, len(rows))
+	for _, row := range rows {
+		res = append(res, curriculumRequirementResponse{
+			ID:             row.ID,
+			Program:        row.Program,
+			Semester:       row.Semester,
+			SubjectID:      row.SubjectID,
+			SubjectCode:    row.SubjectCode.String,
+			SubjectName:    row.SubjectName.String,
+			SessionType:    string(row.SessionType),
+			PeriodsPerWeek: row.PeriodsPerWeek,
+		})
+	}
+	ctx.JSON(http.StatusOK, res)
+}
+
+// setCurriculumRequirement creates the requirement, or changes its periods
+// when the program, semester, subject and session type already have one.
+func (server *Server) setCurriculumRequirement(ctx *gin.Context) {
+	var req curriculumRequirementRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	adminStatus, err := checkAdmin(ctx, "admin")
+	if err != nil {
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return
+	}
+	if !adminStatus {
+		ctx.JSON(http.StatusForbidden, errorResponse(fmt.Errorf("not authorized to edit the curriculum")))
+		return
+	}
+
+	subject, err := server.store.GetSubject(ctx, req.SubjectID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(http.StatusNotFound, errorResponse(fmt.Errorf("subject not found")))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	requirement, err := server.store.UpsertCurriculumRequirement(ctx, db.UpsertCurriculumRequirementParams{
+		Program:        strings.ToUpper(req.Program),
+		Semester:       req.Semester,
+		SubjectID:      req.SubjectID,
+		SessionType:    sessionType(req.SessionType),
+		PeriodsPerWeek: req.PeriodsPerWeek,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	ctx.JSON(http.StatusOK, curriculumRequirementResponse{
+		ID:             requirement.ID,
+		Program:        requirement.Program,
+		Semester:       requirement.Semester,
+		SubjectID:      requirement.SubjectID,
+		SubjectCode:    subject.SubjectCode.String,
+		SubjectName:    subject.Name.String,
+		SessionType:    string(requirement.SessionType),
+		PeriodsPerWeek: requirement.PeriodsPerWeek,
+	})
+}
+
+func (server *Server) deleteCurriculumRequirement(ctx *gin.Context) {
+	var uri getScheduleRequest
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	adminStatus, err := checkAdmin(ctx, "admin")
+	if err != nil {
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return
+	}
+	if !adminStatus {
+		ctx.JSON(http.StatusForbidden, errorResponse(fmt.Errorf("not authorized to edit the curriculum")))
+		return
+	}
+
+	n, err := server.store.DeleteCurriculumRequirement(ctx, uri.ID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	if n == 0 {
+		ctx.JSON(http.StatusNotFound, errorResponse(fmt.Errorf("curriculum requirement not found")))
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"message": "Curriculum requirement deleted successfully"})
+}
+
+type completenessReportRequest struct {
+	Year int32 `form:"year"`
+	// Part is the half of the year, 1 for odd semesters and 2 for even
+	// ones, used to work out which semester each section is in.
+	Part int `form:"part" binding:"omitempty,oneof=1 2"`
+	// Term is the term whose routine is checked, by default the one
+	// numbered like Part.
+	Term       int16  `form:"term" binding:"omitempty,min=1"`
+	Program    string `form:"program"`
+	Department string `form:"department"`
+	GroupID    int64  `form:"group_id"`
+	// Incomplete limits the report to sections that are off curriculum.
+	Incomplete bool `form:"incomplete"`
+}
+
+type completenessLineResponse struct {
+	SubjectID   int64  `json:"subject_id"`
+	SubjectCode string `json:"subject_code,omitempty"`
+	SubjectName string `json:"subject_name,omitempty"`
+	SessionType string `json:"session_type"`
+	Required    int    `json:"required"`
+	Scheduled   int    `json:"scheduled"`
+	Status      string `json:"status"`
+}
+
+type sectionCompletenessResponse struct {
+	GroupID   int64                      `json:"group_id"`
+	GroupName string                     `json:"group_name"`
+	Program   string                     `json:"program"`
+	Semester  int32                      `json:"semester"`
+	Complete  bool                       `json:"complete"`
+	Lines     []completenessLineResponse `json:"lines"`
+}
+
+// getCompletenessReport compares each section's scheduled periods in a term
+// with the curriculum of its program and current semester.
+func (server *Server) getCompletenessReport(ctx *gin.Context) {
+	var req completenessReportRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	if req.Year == 0 {
+		req.Year = int32(bs.CurrentYear())
+	}
+	if req.Part == 0 {
+		req.Part = 1
+	}
+	if req.Term == 0 {
+		req.Term = int16(req.Part)
+	}
+
+	sections, err := server.store.ListAllStudentSections(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	requirements, err := server.store.ListCurriculumRequirements(ctx, db.ListCurriculumRequirementsParams{})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	scheduledRows, err := server.store.ListScheduledPeriods(ctx, db.ListScheduledPeriodsParams{Year: req.Year, Term: req.Term})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	subjects, err := server.store.ListAllSubjects(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	subjectByID := make(map[int64]db.Subject, len(subjects))
+	for _, s := range subjects {
+		subjectByID[s.ID] = s
+	}
+	type programSemester struct {
+		program  string
+		semester int32
+	}
+	required := map[programSemester]map[curriculum.Key]int{}
+	for _, r := range requirements {
+		ps := programSemester{strings.ToUpper(r.Program), r.Semester}
+		if required[ps] == nil {
+			required[ps] = map[curriculum.Key]int{}
+		}
+		required[ps][curriculum.Key{SubjectID: r.SubjectID, SessionType: string(r.SessionType)}] = int(r.PeriodsPerWeek)
+	}
+	sessions := map[int64][]curriculum.Session{}
+	for _, row := range scheduledRows {
+		group := row.GroupID.Int64
+		sessions[group] = append(sessions[group], curriculum.Session{
+			Key:        curriculum.Key{SubjectID: row.SubjectID.Int64, SessionType: string(row.SessionType)},
+			SubGroupID: row.SubGroupID.Int64,
+			Periods:    int(row.Periods),
+		})
+	}
+	scheduled := make(map[int64]map[curriculum.Key]int, len(sessions))
+	for group, s := range sessions {
+		scheduled[group] = curriculum.Scheduled(s)
+	}
+
+	res := []sectionCompletenessResponse{}
+	for _, section := range sections {
+		id := int64(section.ID)
+		if req.GroupID != 0 && id != req.GroupID {
+			continue
+		}
+		if req.Program != "" && !strings.EqualFold(section.Program.String, req.Program) {
+			continue
+		}
+		if req.Department != "" && section.Department.String != req.Department {
+			continue
+		}
+		semester := curriculum.SemesterOf(section.YearEnrolled.Int32, req.Year, req.Part)
+		if semester == 0 {
+			continue
+		}
+
+		lines := curriculum.Compare(required[programSemester{strings.ToUpper(section.Program.String), semester}], scheduled[id])
+		complete := curriculum.Complete(lines)
+		if req.Incomplete && complete {
+			continue
+		}
+		r := sectionCompletenessResponse{
+			GroupID:   id,
+			GroupName: section.Name.String,
+			Program:   section.Program.String,
+			Semester:  semester,
+			Complete:  complete,
+			Lines:     make([]completenessLineResponse, 0, len(lines)),
+		}
+		for _, l := range lines {
+			subject := subjectByID[l.SubjectID]
+			r.Lines = append(r.Lines, completenessLineResponse{
+				SubjectID:   l.SubjectID,
+				SubjectCode: subject.SubjectCode.String,
+				SubjectName: subject.Name.String,
+				SessionType: l.SessionType,
+				Required:    l.Required,
+				Scheduled:   l.Scheduled,
+				Status:      string(l.Status),
+			})
+		}
+		res = append(res, r)
+	}
+	ctx.JSON(http.StatusOK, res)
+}
@@ -0,0 +1,321 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/nirajan1111/routiney/db/sqlc"
+	"github.com/nirajan1111/routiney/ical"
+	"github.com/nirajan1111/routiney/pdf"
+	"github.com/nirajan1111/routiney/timeslot"
+	"github.com/nirajan1111/routiney/validation"
+)
+
+// routine views decide which detail is redundant on a page: a group's
+// routine need not repeat the group name on every cell.
+const (
+	viewTeacher = "teacher"
+	viewRoom    = "room"
+	viewGroup   = "group"
+)
+
+// newRoutineGrid lays schedules out on the days and teaching periods of
+// week. Classes outside it still get a column of their own.
+func newRoutineGrid(title, subtitle, view string, week timeslot.Week, schedules []detailedScheduleResponse) pdf.Grid {
+	grid := pdf.Grid{
+		Title:    title,
+		Subtitle: subtitle,
+		Days:     week.Days,
+		Periods:  week.Teaching(),
+	}
+	for _, s := range schedules {
+		slot, err := timeslot.Parse(s.TimeSlot)
+		if err != nil {
+			continue
+		}
+		lines := []string{s.SubjectCode, s.SubjectName}
+		if view != viewTeacher && s.TeacherName != "" {
+			lines = append(lines, s.TeacherName)
+		}
+		if view != viewRoom && s.RoomCode != "" {
+			room := s.RoomCode
+			if s.BlockNo != "" {
+				room += " (Block " + s.BlockNo + ")"
+			}
+			lines = append(lines, room)
+		}
+		switch {
+		case view != viewGroup && s.GroupName != "" && s.SubGroupName != "":
+			lines = append(lines, s.GroupName+" ("+s.SubGroupName+")")
+		case view != viewGroup && s.GroupName != "":
+			lines = append(lines, sectionNames(s))
+		case s.SubGroupName != "":
+			// parallel classes of a section are told apart by sub-group
+			lines = append(lines, s.SubGroupName)
+		case len(s.CombinedGroupNames) > 0:
+			lines = append(lines, "Combined: "+sectionNames(s))
+		}
+		grid.Entries = append(grid.Entries, pdf.Entry{Slot: slot, Lines: lines})
+	}
+	return grid
+}
+
+// sectionNames lists the sections attending a class, more than one for a
+// combined class.
+func sectionNames(s detailedScheduleResponse) string {
+	return strings.Join(append([]string{s.GroupName}, s.CombinedGroupNames...), ", ")
+}
+
+func routineSubtitle(year int32, term int16, extra ...string) string {
+	parts := append([]string{fmt.Sprintf("Routine %d term %d", year, term)}, extra...)
+	return strings.Join(parts, " - ")
+}
+
+func writePDF(ctx *gin.Context, filename string, doc *pdf.Document) {
+	var buf bytes.Buffer
+	if _, err := doc.WriteTo(&buf); err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	ctx.Header("Content-Disposition", fmt.Sprintf(`inline; filename="%s"`, filename))
+	ctx.Data(http.StatusOK, "application/pdf", buf.Bytes())
+}
+
+func (server *Server) getTeacherRoutinePDF(ctx *gin.Context) {
+	year, err := yearFromQuery(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	term, err := server.termFromQuery(ctx, year, time.Time{})
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	teacher, err := server.store.GetTeacherByEmail(ctx, ctx.Param("email"))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(http.StatusNotFound, errorResponse(fmt.Errorf("teacher not found")))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	schedules, err := server.teacherSchedules(ctx, teacher.Email, year, term, false)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	title := teacher.Name.String
+	if title == "" {
+		title = teacher.Email
+	}
+	var extra []string
+	if teacher.Designation.Valid {
+		extra = append(extra, teacher.Designation.String)
+	}
+	if teacher.Department.Valid {
+		extra = append(extra, teacher.Department.String)
+	}
+	// a teacher or room can serve sections on several grids, so their
+	// routine is drawn on the default one
+	week, err := validation.GridFor(ctx, server.store, 0)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	grid := newRoutineGrid(title, routineSubtitle(year, term, extra...), viewTeacher, week, schedules)
+	if grid.Notes, err = server.holidayNotes(ctx, year); err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	doc := pdf.New()
+	doc.AddGrid(grid)
+	writePDF(ctx, fmt.Sprintf("routine-%s-%d.pdf", teacher.Email, year), doc)
+}
+
+func (server *Server) getRoomRoutinePDF(ctx *gin.Context) {
+	year, err := yearFromQuery(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	term, err := server.termFromQuery(ctx, year, time.Time{})
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	roomID, err := strconv.ParseInt(ctx.Param("room_id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(fmt.Errorf("invalid room ID")))
+		return
+	}
+	room, err := server.store.GetRoom(ctx, int32(roomID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(http.StatusNotFound, errorResponse(fmt.Errorf("room not found")))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	schedules, err := server.roomSchedules(ctx, roomID, year, term, false)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	title := "Room " + room.RoomCode.String
+	var extra []string
+	if room.BlockNo.Valid {
+		extra = append(extra, "Block "+room.BlockNo.String)
+	}
+	if room.Department.Valid {
+		extra = append(extra, room.Department.String)
+	}
+	// a teacher or room can serve sections on several grids, so their
+	// routine is drawn on the default one
+	week, err := validation.GridFor(ctx, server.store, 0)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	grid := newRoutineGrid(title, routineSubtitle(year, term, extra...), viewRoom, week, schedules)
+	if grid.Notes, err = server.holidayNotes(ctx, year); err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	// bookings already past are of no use on a printed routine
+	t, err := server.academicTerm(ctx, year, term)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	from, to := t.StartDate, t.EndDate
+	if today := time.Now().In(ical.Kathmandu); today.After(from) {
+		from = time.Date(today.Year(), today.Month(), today.Day(), 0, 0, 0, 0, time.UTC)
+	}
+	bookings, err := server.roomBookings(ctx, roomID, from, to)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	grid.Notes = append(grid.Notes, bookingNotes(bookings)...)
+	doc := pdf.New()
+	doc.AddGrid(grid)
+	writePDF(ctx, fmt.Sprintf("routine-room-%s-%d.pdf", room.RoomCode.String, year), doc)
+}
+
+func (server *Server) getGroupRoutinePDF(ctx *gin.Context) {
+	year, err := yearFromQuery(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	term, err := server.termFromQuery(ctx, year, time.Time{})
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	groupID, err := strconv.ParseInt(ctx.Param("group_id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(fmt.Errorf("invalid group ID")))
+		return
+	}
+	section, err := server.store.GetStudentSection(ctx, int32(groupID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(http.StatusNotFound, errorResponse(fmt.Errorf("student section not found")))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	schedules, err := server.groupSchedules(ctx, groupID, year, term, false)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	week, err := validation.GridFor(ctx, server.store, groupID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	grid := newSectionGrid(section, year, term, week, schedules)
+	if grid.Notes, err = server.holidayNotes(ctx, year); err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	doc := pdf.New()
+	doc.AddGrid(grid)
+	writePDF(ctx, fmt.Sprintf("routine-%s-%d.pdf", section.Name.String, year), doc)
+}
+
+func newSectionGrid(section db.StudentSection, year int32, term int16, week timeslot.Week, schedules []detailedScheduleResponse) pdf.Grid {
+	title := section.Name.String
+	if title == "" {
+		title = fmt.Sprintf("%s %d %s", section.Program.String, section.YearEnrolled.Int32, section.GroupName.String)
+	}
+	var extra []string
+	if section.Department.Valid {
+		extra = append(extra, section.Department.String)
+	}
+	return newRoutineGrid(title, routineSubtitle(year, term, extra...), viewGroup, week, schedules)
+}
+
+// getDepartmentBookletPDF renders one page per student section of a
+// department, ready to print and pin up.
+func (server *Server) getDepartmentBookletPDF(ctx *gin.Context) {
+	year, err := yearFromQuery(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	term, err := server.termFromQuery(ctx, year, time.Time{})
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	department := ctx.Param("department")
+	sections, err := server.store.GetStudentSectionsByDepartment(ctx, StringToSQLNullString(department))
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	if len(sections) == 0 {
+		ctx.JSON(http.StatusNotFound, errorResponse(fmt.Errorf("no student sections in department %s", department)))
+		return
+	}
+
+	notes, err := server.holidayNotes(ctx, year)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	doc := pdf.New()
+	for _, section := range sections {
+		schedules, err := server.groupSchedules(ctx, int64(section.ID), year, term, false)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+			return
+		}
+		week, err := validation.GridFor(ctx, server.store, int64(section.ID))
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+			return
+		}
+		grid := newSectionGrid(section, year, term, week, schedules)
+		grid.Notes = notes
+		doc.AddGrid(grid)
+	}
+	writePDF(ctx, fmt.Sprintf("routine-%s-%d.pdf", department, year), doc)
+}
@@ -0,0 +1,205 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nirajan1111/routiney/bs"
+	db "github.com/nirajan1111/routiney/db/sqlc"
+	"github.com/nirajan1111/routiney/timeslot"
+)
+
+type findFreeRoomsRequest struct {
+	Year            int32  `form:"year"`
+	Term            int16  `form:"term" binding:"omitempty,min=1"`
+	Day             string `form:"day"`
+	Date            string `form:"date"`
+	StartTime       string `form:"start_time" binding:"required"`
+	EndTime         string `form:"end_time" binding:"required"`
+	BlockNo         string `form:"block_no"`
+	FloorNo         *int32 `form:"floor_no"`
+	Department      string `form:"department"`
+	ScreenAvailable *bool  `form:"screen_available"`
+	MinCapacity     int32  `form:"min_capacity" binding:"min=0"`
+	NearBlock       string `form:"near_block"`
+	NearRoom        string `form:"near_room"`
+	// GroupID asks for rooms that seat the whole section.
+	GroupID int32 `form:"group_id" binding:"min=0"`
+}
+
+type freeRoomResponse struct {
+	newRoomresponse
+	// SameBlock and FloorsAway tell how far the room is from the block or
+	// room the search was ranked by.
+	SameBlock  *bool  `json:"same_block,omitempty"`
+	FloorsAway *int32 `json:"floors_away,omitempty"`
+}
+
+// reference is the place free rooms are ranked by proximity to.
+type reference struct {
+	block string
+	floor sql.NullInt32
+}
+
+// distance orders rooms by proximity: rooms in the reference block come
+// first, nearest floor first; rooms elsewhere follow, lowest floor first.
+func (ref reference) distance(room db.Room) (int, int32) {
+	if !strings.EqualFold(room.BlockNo.String, ref.block) {
+		return 1, room.FloorNo.Int32
+	}
+	if !ref.floor.Valid {
+		return 0, 0
+	}
+	return 0, absInt32(room.FloorNo.Int32 - ref.floor.Int32)
+}
+
+func absInt32(n int32) int32 {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+// findFreeRooms lists the rooms with neither a weekly class nor, for a
+// dated search, a booking overlapping the time range.
+func (server *Server) findFreeRooms(ctx *gin.Context) {
+	var req findFreeRoomsRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	arg := db.ListFreeRoomsParams{
+		BlockNo:    StringToSQLNullString(req.BlockNo),
+		Department: StringToSQLNullString(req.Department),
+		Year:       req.Year,
+	}
+	switch {
+	case req.Date != "":
+		date, err := parseDate("date", req.Date)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, errorResponse(err))
+			return
+		}
+		arg.Date = sql.NullTime{Time: date, Valid: true}
+		arg.DayOfWeek = int16(date.Weekday())
+		if arg.Year == 0 {
+			arg.Year = int32(bs.YearOf(date))
+		}
+	case req.Day != "":
+		day, err := timeslot.ParseDay(req.Day)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, errorResponse(err))
+			return
+		}
+		arg.DayOfWeek = int16(day)
+	default:
+		ctx.JSON(http.StatusBadRequest, errorResponse(fmt.Errorf("day or date is required")))
+		return
+	}
+	if arg.Year == 0 {
+		arg.Year = int32(bs.CurrentYear())
+	}
+	// without a term, the one running on the date searched (or today)
+	arg.Term = req.Term
+	if arg.Term == 0 {
+		term, err := server.termOn(ctx, arg.Year, arg.Date.Time)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+			return
+		}
+		arg.Term = term
+	}
+
+	start, err := timeslot.ParseClock(req.StartTime)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(fmt.Errorf("invalid start_time: %w", err)))
+		return
+	}
+	end, err := timeslot.ParseClock(req.EndTime)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(fmt.Errorf("invalid end_time: %w", err)))
+		return
+	}
+	slot := timeslot.TimeSlot{Day: timeslot.Day(arg.DayOfWeek), Start: start, End: end}
+	if err := slot.Validate(); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	arg.StartMinute, arg.EndMinute = slot.Start, slot.End
+
+	if req.FloorNo != nil {
+		arg.FloorNo = sql.NullInt32{Int32: *req.FloorNo, Valid: true}
+	}
+	if req.ScreenAvailable != nil {
+		arg.ScreenAvailable = sql.NullBool{Bool: *req.ScreenAvailable, Valid: true}
+	}
+	if req.GroupID != 0 {
+		size, err := server.store.GetStudentSectionSize(ctx, req.GroupID)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				ctx.JSON(http.StatusNotFound, errorResponse(fmt.Errorf("student section not found")))
+				return
+			}
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+			return
+		}
+		req.MinCapacity = max(req.MinCapacity, size)
+	}
+	if req.MinCapacity > 0 {
+		arg.MinCapacity = sql.NullInt32{Int32: req.MinCapacity, Valid: true}
+	}
+
+	var ref *reference
+	switch {
+	case req.NearRoom != "":
+		room, err := server.store.GetRoomByCode(ctx, req.NearRoom)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				ctx.JSON(http.StatusNotFound, errorResponse(fmt.Errorf("room %s not found", req.NearRoom)))
+				return
+			}
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+			return
+		}
+		ref = &reference{block: room.BlockNo.String, floor: room.FloorNo}
+	case req.NearBlock != "":
+		ref = &reference{block: req.NearBlock}
+	}
+
+	rooms, err := server.store.ListFreeRooms(ctx, arg)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	if ref != nil {
+		sort.SliceStable(rooms, func(i, j int) bool {
+			bi, fi := ref.distance(rooms[i])
+			bj, fj := ref.distance(rooms[j])
+			if bi != bj {
+				return bi < bj
+			}
+			return fi < fj
+		})
+	}
+
+	res := make([]freeRoomResponse, 0, len(rooms))
+	for _, room := range rooms {
+		r := freeRoomResponse{newRoomresponse: newRoomResponse(room)}
+		if ref != nil {
+			block, floors := ref.distance(room)
+			sameBlock := block == 0
+			r.SameBlock = &sameBlock
+			if sameBlock && ref.floor.Valid {
+				r.FloorsAway = &floors
+			}
+		}
+		res = append(res, r)
+	}
+	ctx.JSON(http.StatusOK, res)
+}
@@ -0,0 +1,435 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/nirajan1111/routiney/db/sqlc"
+	"github.com/nirajan1111/routiney/timeslot"
+	"github.com/nirajan1111/routiney/validation"
+)
+
+type gridPeriodRequest struct {
+	Name  string `json:"name" binding:"required,max=20"`
+	Start string `json:"start" binding:"required"`
+	End   string `json:"end" binding:"required"`
+	Break bool   `json:"break"`
+}
+
+type gridRequest struct {
+	Name  string `json:"name" binding:"required,max=50"`
+	Shift string `json:"shift" binding:"omitempty,oneof=morning day evening"`
+	// Programs are the programs whose sections follow the grid, e.g.
+	// "BCT". Sections of other programs follow the default grid.
+	Programs  []string            `json:"programs"`
+	Days      []string            `json:"days" binding:"required,min=1"`
+	Periods   []gridPeriodRequest `json:"periods" binding:"required,min=1,dive"`
+	IsDefault bool                `json:"is_default"`
+}
+
+type gridPeriodResponse struct {
+	Name  string `json:"name"`
+	Start string `json:"start"`
+	End   string `json:"end"`
+	Break bool   `json:"break,omitempty"`
+}
+
+type gridResponse struct {
+	ID        int64                `json:"id,omitempty"`
+	Name      string               `json:"name"`
+	Shift     string               `json:"shift"`
+	Programs  []string             `json:"programs"`
+	Days      []string             `json:"days"`
+	Periods   []gridPeriodResponse `json:"periods"`
+	IsDefault bool                 `json:"is_default"`
+	// Slots are the single-period time slots classes can be scheduled in.
+	Slots []string `json:"slots"`
+}
+
+func newGridResponse(grid db.TimetableGrid, periods []db.GridPeriod) gridResponse {
+	res := newWeekResponse(validation.Week(grid, periods))
+	res.ID = grid.ID
+	res.Name = grid.Name
+	res.Shift = string(grid.Shift)
+	res.Programs = grid.Programs
+	if res.Programs == nil {
+		res.Programs = []string{}
+	}
+	res.IsDefault = grid.IsDefault
+	return res
+}
+
+func newWeekResponse(week timeslot.Week) gridResponse {
+	res := gridResponse{
+		Programs: []string{},
+		Days:     make([]string, 0, len(week.Days)),
+		Periods:  make([]gridPeriodResponse, 0, len(week.Periods)),
+		Slots:    []string{},
+	}
+	for _, d := range week.Days {
+		res.Days = append(res.Days, d.String())
+	}
+	for _, p := range week.Periods {
+		res.Periods = append(res.Periods, gridPeriodResponse{
+			Name:  p.Name,
+			Start: timeslot.FormatClock(p.Start),
+			End:   timeslot.FormatClock(p.End),
+			Break: p.Break,
+		})
+	}
+	for _, slot := range week.Slots() {
+		res.Slots = append(res.Slots, slot.String())
+	}
+	return res
+}
+
+// params checks the grid's days and periods: periods must not overlap and at
+// least one of them must be for teaching.
+func (req gridRequest) params() (db.CreateGridParams, []db.CreateGridPeriodParams, error) {
+	arg := db.CreateGridParams{
+		Name:      req.Name,
+		Shift:     db.ShiftDay,
+		Programs:  []string{},
+		IsDefault: req.IsDefault,
+	}
+	if req.Shift != "" {
+		arg.Shift = db.Shift(req.Shift)
+	}
+	seen := make(map[string]bool)
+	for _, p := range req.Programs {
+		p = strings.ToUpper(strings.TrimSpace(p))
+		if p != "" && !seen[p] {
+			seen[p] = true
+			arg.Programs = append(arg.Programs, p)
+		}
+	}
+	if len(arg.Programs) == 0 && !req.IsDefault {
+		return arg, nil, fmt.Errorf("a grid must list its programs or be the default grid")
+	}
+
+	days := make(map[timeslot.Day]bool)
+	for _, s := range req.Days {
+		day, err := timeslot.ParseDay(s)
+		if err != nil {
+			return arg, nil, err
+		}
+		if !days[day] {
+			days[day] = true
+			arg.Days = append(arg.Days, int32(day))
+		}
+	}
+	sort.Slice(arg.Days, func(i, j int) bool { return arg.Days[i] < arg.Days[j] })
+
+	periods := make([]db.CreateGridPeriodParams, 0, len(req.Periods))
+	names := make(map[string]bool)
+	teaching := false
+	for _, p := range req.Periods {
+		start, err := timeslot.ParseClock(p.Start)
+		if err != nil {
+			return arg, nil, err
+		}
+		end, err := timeslot.ParseClock(p.End)
+		if err != nil {
+			return arg, nil, err
+		}
+		if end <= start {
+			return arg, nil, fmt.Errorf("period %s ends before it starts", p.Name)
+		}
+		if names[p.Name] {
+			return arg, nil, fmt.Errorf("period %s is listed twice", p.Name)
+		}
+		names[p.Name] = true
+		teaching = teaching || !p.Break
+		periods = append(periods, db.CreateGridPeriodParams{
+			Name:        p.Name,
+			StartMinute: start,
+			EndMinute:   end,
+			IsBreak:     p.Break,
+		})
+	}
+	if !teaching {
+		return arg, nil, fmt.Errorf("a grid needs at least one teaching period")
+	}
+	sort.Slice(periods, func(i, j int) bool { return periods[i].StartMinute < periods[j].StartMinute })
+	for i := 1; i < len(periods); i++ {
+		if periods[i].StartMinute < periods[i-1].EndMinute {
+			return arg, nil, fmt.Errorf("periods %s and %s overlap", periods[i-1].Name, periods[i].Name)
+		}
+	}
+	return arg, periods, nil
+}
+
+// checkGridPrograms makes sure no program is given a second grid, as a
+// section must have exactly one.
+func (server *Server) checkGridPrograms(ctx *gin.Context, id int64, programs []string) (int, error) {
+	grids, err := server.store.ListGrids(ctx)
+	if err != nil {
+		return http.StatusInternalServerError, err
+	}
+	for _, g := range grids {
+		if g.ID == id {
+			continue
+		}
+		for _, p := range g.Programs {
+			for _, program := range programs {
+				if p == program {
+					return http.StatusConflict, fmt.Errorf("program %s already follows grid %s", program, g.Name)
+				}
+			}
+		}
+	}
+	return http.StatusOK, nil
+}
+
+// saveGrid writes a grid and replaces its periods in one transaction. A
+// zero id creates a new grid.
+func (server *Server) saveGrid(ctx *gin.Context, id int64, arg db.CreateGridParams, periods []db.CreateGridPeriodParams) (db.TimetableGrid, []db.GridPeriod, error) {
+	var grid db.TimetableGrid
+	var saved []db.GridPeriod
+	err := server.store.ExecTx(ctx, func(q *db.Queries) error {
+		var err error
+		if arg.IsDefault {
+			// only one grid can be the default
+			if err := q.ClearDefaultGrid(ctx, id); err != nil {
+				return err
+			}
+		}
+		if id == 0 {
+			grid, err = q.CreateGrid(ctx, arg)
+		} else {
+			grid, err = q.UpdateGrid(ctx, db.UpdateGridParams{
+				ID:        id,
+				Name:      arg.Name,
+				Shift:     arg.Shift,
+				Programs:  arg.Programs,
+				Days:      arg.Days,
+				IsDefault: arg.IsDefault,
+			})
+		}
+		if err != nil {
+			return err
+		}
+		if err := q.DeleteGridPeriods(ctx, grid.ID); err != nil {
+			return err
+		}
+		for _, p := range periods {
+			p.GridID = grid.ID
+			period, err := q.CreateGridPeriod(ctx, p)
+			if err != nil {
+				return err
+			}
+			saved = append(saved, period)
+		}
+		return nil
+	})
+	return grid, saved, err
+}
+
+func (server *Server) listGrids(ctx *gin.Context) {
+	grids, err := server.store.ListGrids(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	periods, err := server.store.ListAllGridPeriods(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	byGrid := make(map[int64][]db.GridPeriod)
+	for _, p := range periods {
+		byGrid[p.GridID] = append(byGrid[p.GridID], p)
+	}
+	res := make([]gridResponse, 0, len(grids))
+	for _, g := range grids {
+		res = append(res, newGridResponse(g, byGrid[g.ID]))
+	}
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (server *Server) getGrid(ctx *gin.Context) {
+	var uri getScheduleRequest
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	grid, err := server.store.GetGrid(ctx, uri.ID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(http.StatusNotFound, errorResponse(fmt.Errorf("grid not found")))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	periods, err := server.store.ListGridPeriods(ctx, grid.ID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	ctx.JSON(http.StatusOK, newGridResponse(grid, periods))
+}
+
+// getSectionGrid returns the grid a section's classes are scheduled on.
+func (server *Server) getSectionGrid(ctx *gin.Context) {
+	var uri getStudentSectionRequest
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if _, err := server.store.GetStudentSection(ctx, uri.ID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(http.StatusNotFound, errorResponse(fmt.Errorf("student section not found")))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	grid, err := server.store.GetGridForSection(ctx, uri.ID)
+	if errors.Is(err, sql.ErrNoRows) {
+		// no grid has been set up, the built-in one applies
+		res := newWeekResponse(timeslot.DefaultWeek())
+		res.Shift = string(db.ShiftEvening)
+		res.IsDefault = true
+		ctx.JSON(http.StatusOK, res)
+		return
+	}
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	periods, err := server.store.ListGridPeriods(ctx, grid.ID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	ctx.JSON(http.StatusOK, newGridResponse(grid, periods))
+}
+
+func (server *Server) createGrid(ctx *gin.Context) {
+	var req gridRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	adminStatus, err := checkAdmin(ctx, "admin")
+	if err != nil {
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return
+	}
+	if !adminStatus {
+		ctx.JSON(http.StatusForbidden, errorResponse(fmt.Errorf("not authorized to edit timetable grids")))
+		return
+	}
+
+	arg, periods, err := req.params()
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	if status, err := server.checkGridPrograms(ctx, 0, arg.Programs); err != nil {
+		ctx.JSON(status, errorResponse(err))
+		return
+	}
+	grid, saved, err := server.saveGrid(ctx, 0, arg, periods)
+	if err != nil {
+		if isUniqueViolation(err) {
+			ctx.JSON(http.StatusConflict, errorResponse(fmt.Errorf("grid %s already exists", req.Name)))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	ctx.JSON(http.StatusOK, newGridResponse(grid, saved))
+}
+
+// updateGrid replaces a grid's days and periods. Classes already scheduled
+// keep their slots; only classes created or moved later are checked against
+// the new grid.
+func (server *Server) updateGrid(ctx *gin.Context) {
+	var uri getScheduleRequest
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	var req gridRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	adminStatus, err := checkAdmin(ctx, "admin")
+	if err != nil {
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return
+	}
+	if !adminStatus {
+		ctx.JSON(http.StatusForbidden, errorResponse(fmt.Errorf("not authorized to edit timetable grids")))
+		return
+	}
+
+	if _, err := server.store.GetGrid(ctx, uri.ID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(http.StatusNotFound, errorResponse(fmt.Errorf("grid not found")))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	arg, periods, err := req.params()
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	if status, err := server.checkGridPrograms(ctx, uri.ID, arg.Programs); err != nil {
+		ctx.JSON(status, errorResponse(err))
+		return
+	}
+	grid, saved, err := server.saveGrid(ctx, uri.ID, arg, periods)
+	if err != nil {
+		if isUniqueViolation(err) {
+			ctx.JSON(http.StatusConflict, errorResponse(fmt.Errorf("grid %s already exists", req.Name)))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	ctx.JSON(http.StatusOK, newGridResponse(grid, saved))
+}
+
+func (server *Server) deleteGrid(ctx *gin.Context) {
+	var uri getScheduleRequest
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	adminStatus, err := checkAdmin(ctx, "admin")
+	if err != nil {
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return
+	}
+	if !adminStatus {
+		ctx.JSON(http.StatusForbidden, errorResponse(fmt.Errorf("not authorized to edit timetable grids")))
+		return
+	}
+
+	n, err := server.store.DeleteGrid(ctx, uri.ID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	if n == 0 {
+		ctx.JSON(http.StatusNotFound, errorResponse(fmt.Errorf("grid not found")))
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"message": "Grid deleted successfully"})
+}
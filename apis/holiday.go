@@ -0,0 +1,364 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nirajan1111/routiney/bs"
+	db "github.com/nirajan1111/routiney/db/sqlc"
+	"github.com/nirajan1111/routiney/ical"
+)
+
+const dateLayout = "2006-01-02"
+
+// parseDate parses a "2006-01-02" date, naming the field in the error.
+func parseDate(field, value string) (time.Time, error) {
+	t, err := time.Parse(dateLayout, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%s must look like 2024-04-14", field)
+	}
+	return t, nil
+}
+
+// bsDate formats the Bikram Sambat date of an AD date, or returns "" when it
+// is outside the range the bs package covers.
+func bsDate(t time.Time) string {
+	d, err := bs.FromAD(t)
+	if err != nil {
+		return ""
+	}
+	return d.String()
+}
+
+type calendarEventRequest struct {
+	Kind        string `json:"kind" binding:"required,oneof=term holiday exam_week closure"`
+	Title       string `json:"title" binding:"required,max=200"`
+	StartDate   string `json:"start_date" binding:"required"`
+	EndDate     string `json:"end_date"`
+	Year        int32  `json:"year"`
+	Description string `json:"description"`
+}
+
+type listCalendarEventsRequest struct {
+	Year int32  `form:"year"`
+	Kind string `form:"kind" binding:"omitempty,oneof=term holiday exam_week closure"`
+}
+
+type calendarEventResponse struct {
+	ID          int64  `json:"id"`
+	Kind        string `json:"kind"`
+	Title       string `json:"title"`
+	StartDate   string `json:"start_date"`
+	EndDate     string `json:"end_date"`
+	StartDateBs string `json:"start_date_bs,omitempty"`
+	EndDateBs   string `json:"end_date_bs,omitempty"`
+	Year        int32  `json:"year"`
+	Description string `json:"description,omitempty"`
+}
+
+func newCalendarEventResponse(e db.CalendarEvent) calendarEventResponse {
+	return calendarEventResponse{
+		ID:          e.ID,
+		Kind:        string(e.Kind),
+		Title:       e.Title,
+		StartDate:   e.StartDate.Format(dateLayout),
+		EndDate:     e.EndDate.Format(dateLayout),
+		StartDateBs: bsDate(e.StartDate),
+		EndDateBs:   bsDate(e.EndDate),
+		Year:        e.Year,
+		Description: e.Description.String,
+	}
+}
+
+// params validates the request's dates, defaulting the end to the start and
+// the year to the Nepali year the event starts in.
+func (req calendarEventRequest) params() (db.CreateCalendarEventParams, error) {
+	start, err := parseDate("start_date", req.StartDate)
+	if err != nil {
+		return db.CreateCalendarEventParams{}, err
+	}
+	end := start
+	if req.EndDate != "" {
+		if end, err = parseDate("end_date", req.EndDate); err != nil {
+			return db.CreateCalendarEventParams{}, err
+		}
+	}
+	if end.Before(start) {
+		return db.CreateCalendarEventParams{}, fmt.Errorf("end_date is before start_date")
+	}
+	if req.Year == 0 {
+		req.Year = int32(bs.YearOf(start))
+	}
+	return db.CreateCalendarEventParams{
+		Kind:        db.CalendarEventKind(req.Kind),
+		Title:       req.Title,
+		StartDate:   start,
+		EndDate:     end,
+		Year:        req.Year,
+		Description: StringToSQLNullString(req.Description),
+	}, nil
+}
+
+func (server *Server) listCalendarEvents(ctx *gin.Context) {
+	var req listCalendarEventsRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	events, err := server.store.ListCalendarEvents(ctx, db.ListCalendarEventsParams{
+		Year: sql.NullInt32{Int32: req.Year, Valid: req.Year != 0},
+		Kind: db.NullCalendarEventKind{CalendarEventKind: db.CalendarEventKind(req.Kind), Valid: req.Kind != ""},
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	res := make([]calendarEventResponse, 0, len(events))
+	for _, e := range events {
+		res = append(res, newCalendarEventResponse(e))
+	}
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (server *Server) getCalendarEvent(ctx *gin.Context) {
+	var uri getScheduleRequest
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	event, err := server.store.GetCalendarEvent(ctx, uri.ID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(http.StatusNotFound, errorResponse(fmt.Errorf("calendar event not found")))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	ctx.JSON(http.StatusOK, newCalendarEventResponse(event))
+}
+
+func (server *Server) createCalendarEvent(ctx *gin.Context) {
+	var req calendarEventRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	adminStatus, err := checkAdmin(ctx, "admin")
+	if err != nil {
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return
+	}
+	if !adminStatus {
+		ctx.JSON(http.StatusForbidden, errorResponse(fmt.Errorf("not authorized to edit the academic calendar")))
+		return
+	}
+
+	arg, err := req.params()
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	event, err := server.store.CreateCalendarEvent(ctx, arg)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	ctx.JSON(http.StatusOK, newCalendarEventResponse(event))
+}
+
+func (server *Server) updateCalendarEvent(ctx *gin.Context) {
+	var uri getScheduleRequest
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	var req calendarEventRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	adminStatus, err := checkAdmin(ctx, "admin")
+	if err != nil {
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return
+	}
+	if !adminStatus {
+		ctx.JSON(http.StatusForbidden, errorResponse(fmt.Errorf("not authorized to edit the academic calendar")))
+		return
+	}
+
+	arg, err := req.params()
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	event, err := server.store.UpdateCalendarEvent(ctx, db.UpdateCalendarEventParams{
+		ID:          uri.ID,
+		Kind:        arg.Kind,
+		Title:       arg.Title,
+		StartDate:   arg.StartDate,
+		EndDate:     arg.EndDate,
+		Year:        arg.Year,
+		Description: arg.Description,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(http.StatusNotFound, errorResponse(fmt.Errorf("calendar event not found")))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	ctx.JSON(http.StatusOK, newCalendarEventResponse(event))
+}
+
+func (server *Server) deleteCalendarEvent(ctx *gin.Context) {
+	var uri getScheduleRequest
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	adminStatus, err := checkAdmin(ctx, "admin")
+	if err != nil {
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return
+	}
+	if !adminStatus {
+		ctx.JSON(http.StatusForbidden, errorResponse(fmt.Errorf("not authorized to edit the academic calendar")))
+		return
+	}
+
+	if err := server.store.DeleteCalendarEvent(ctx, uri.ID); err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"message": "Calendar event deleted successfully"})
+}
+
+// importCalendarEvents loads an .ics holiday list, uploaded as the "file"
+// form field or sent as the raw body. Events are stored as ?kind= (holiday
+// by default); re-importing the same list updates the events by UID.
+func (server *Server) importCalendarEvents(ctx *gin.Context) {
+	kind := ctx.DefaultQuery("kind", string(db.CalendarEventKindHoliday))
+	switch db.CalendarEventKind(kind) {
+	case db.CalendarEventKindTerm, db.CalendarEventKindHoliday, db.CalendarEventKindExamWeek, db.CalendarEventKindClosure:
+	default:
+		ctx.JSON(http.StatusBadRequest, errorResponse(fmt.Errorf("invalid kind %q", kind)))
+		return
+	}
+
+	adminStatus, err := checkAdmin(ctx, "admin")
+	if err != nil {
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return
+	}
+	if !adminStatus {
+		ctx.JSON(http.StatusForbidden, errorResponse(fmt.Errorf("not authorized to edit the academic calendar")))
+		return
+	}
+
+	var body io.Reader = ctx.Request.Body
+	if strings.HasPrefix(ctx.ContentType(), "multipart/") {
+		fileHeader, err := ctx.FormFile("file")
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, errorResponse(fmt.Errorf("file is required")))
+			return
+		}
+		file, err := fileHeader.Open()
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, errorResponse(err))
+			return
+		}
+		defer file.Close()
+		body = file
+	}
+
+	imported, err := ical.Parse(body)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	events := make([]calendarEventResponse, 0, len(imported))
+	err = server.store.ExecTx(ctx, func(q *db.Queries) error {
+		for _, e := range imported {
+			title := e.Summary
+			if title == "" {
+				title = "Holiday"
+			}
+			if len(title) > 200 {
+				title = title[:200]
+			}
+			uid := sql.NullString{String: e.UID, Valid: e.UID != ""}
+			if !uid.Valid {
+				// without a UID fall back to the date and title so that
+				// re-imports still match
+				uid = sql.NullString{String: e.Start.Format(dateLayout) + "/" + title, Valid: true}
+			}
+			event, err := q.UpsertImportedCalendarEvent(ctx, db.UpsertImportedCalendarEventParams{
+				Kind:        db.CalendarEventKind(kind),
+				Title:       title,
+				StartDate:   e.Start,
+				EndDate:     e.End,
+				Year:        int32(bs.YearOf(e.Start)),
+				Description: StringToSQLNullString(e.Description),
+				SourceUid:   uid,
+			})
+			if err != nil {
+				return err
+			}
+			events = append(events, newCalendarEventResponse(event))
+		}
+		return nil
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	ctx.JSON(http.StatusOK, events)
+}
+
+// noClassDays returns the holidays, exam weeks and closures of an academic
+// year, for exports to leave out.
+func (server *Server) noClassDays(ctx *gin.Context, year int32) ([]db.CalendarEvent, error) {
+	start, end := bs.YearBounds(int(year))
+	return server.store.ListNoClassDays(ctx, db.ListNoClassDaysParams{
+		FromDate: start,
+		ToDate:   end,
+	})
+}
+
+// holidayNotes describes the days without classes for printed routines.
+func (server *Server) holidayNotes(ctx *gin.Context, year int32) ([]string, error) {
+	events, err := server.noClassDays(ctx, year)
+	if err != nil {
+		return nil, err
+	}
+	notes := make([]string, 0, len(events))
+	for _, e := range events {
+		dates := e.StartDate.Format(dateLayout)
+		if d := bsDate(e.StartDate); d != "" {
+			dates += " (" + d + " BS)"
+		}
+		if !e.EndDate.Equal(e.StartDate) {
+			dates += " to " + e.EndDate.Format(dateLayout)
+			if d := bsDate(e.EndDate); d != "" {
+				dates += " (" + d + " BS)"
+			}
+		}
+		notes = append(notes, fmt.Sprintf("No classes %s: %s", dates, e.Title))
+	}
+	return notes, nil
+}
@@ -0,0 +1,89 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nirajan1111/routiney/bs"
+	"github.com/nirajan1111/routiney/importer"
+)
+
+type importRequest struct {
+	DryRun bool  `form:"dry_run"`
+	Year   int32 `form:"year"`
+	Term   int16 `form:"term" binding:"omitempty,min=1"`
+}
+
+// importSheet loads a CSV or XLSX upload (form field "file") into the table
+// named by :entity. Nothing is written unless every row is valid; with
+// ?dry_run=true nothing is written at all and the per-row report is returned.
+func (server *Server) importSheet(ctx *gin.Context) {
+	var req importRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	adminStatus, err := checkAdmin(ctx, "admin")
+	if err != nil {
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return
+	}
+	if !adminStatus {
+		ctx.JSON(http.StatusForbidden, errorResponse(fmt.Errorf("not authorized to import data")))
+		return
+	}
+
+	entity, err := importer.ParseEntity(ctx.Param("entity"))
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, errorResponse(err))
+		return
+	}
+
+	fileHeader, err := ctx.FormFile("file")
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(fmt.Errorf("file is required")))
+		return
+	}
+	format, err := importer.FormatFromName(fileHeader.Filename)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	file, err := fileHeader.Open()
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	defer file.Close()
+
+	table, err := importer.Read(file, format)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if req.Year == 0 {
+		req.Year = int32(bs.CurrentYear())
+	}
+	report, err := importer.Run(ctx, server.store, entity, table, importer.Options{
+		DryRun: req.DryRun,
+		Year:   req.Year,
+		Term:   req.Term,
+	})
+	if err != nil {
+		if errors.Is(err, importer.ErrInvalidSheet) {
+			ctx.JSON(http.StatusBadRequest, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	if len(report.Errors) > 0 && !req.DryRun {
+		ctx.JSON(http.StatusUnprocessableEntity, report)
+		return
+	}
+	ctx.JSON(http.StatusOK, report)
+}
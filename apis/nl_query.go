@@ -0,0 +1,249 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nirajan1111/routiney/bs"
+	db "github.com/nirajan1111/routiney/db/sqlc"
+	"github.com/nirajan1111/routiney/nlquery"
+	"github.com/nirajan1111/routiney/timeslot"
+	"github.com/nirajan1111/routiney/validation"
+)
+
+type naturalLanguageQueryRequest struct {
+	Query string `json:"query" binding:"required"`
+}
+
+type nlSubject struct {
+	ID   int64  `json:"id,omitempty"`
+	Code string `json:"code"`
+	Name string `json:"name"`
+}
+
+type nlTeacher struct {
+	Email       string `json:"email"`
+	Name        string `json:"name"`
+	Designation string `json:"designation,omitempty"`
+}
+
+type nlRoom struct {
+	ID      int64  `json:"id,omitempty"`
+	Name    string `json:"name"`
+	BlockNo string `json:"block_no"`
+}
+
+type nlGroup struct {
+	ID   int64  `json:"id,omitempty"`
+	Name string `json:"name"`
+}
+
+type nlTimeSlot struct {
+	Day       string `json:"day"`
+	StartTime string `json:"start_time"`
+	EndTime   string `json:"end_time"`
+}
+
+type nlQueryResult struct {
+	ScheduleID int64      `json:"schedule_id,omitempty"`
+	Year       int32      `json:"year"`
+	Subject    nlSubject  `json:"subject"`
+	Teacher    nlTeacher  `json:"teacher"`
+	Room       nlRoom     `json:"room"`
+	Group      nlGroup    `json:"group"`
+	TimeSlot   nlTimeSlot `json:"time_slot"`
+}
+
+func newNLTimeSlot(slot timeslot.TimeSlot) nlTimeSlot {
+	return nlTimeSlot{
+		Day:       slot.Day.String(),
+		StartTime: timeslot.FormatClock(slot.Start),
+		EndTime:   timeslot.FormatClock(slot.End),
+	}
+}
+
+func newNLQueryResult(s detailedScheduleResponse, slot timeslot.TimeSlot) nlQueryResult {
+	return nlQueryResult{
+		ScheduleID: s.ID,
+		Year:       s.Year,
+		Subject:    nlSubject{ID: s.SubjectID, Code: s.SubjectCode, Name: s.SubjectName},
+		Teacher:    nlTeacher{Email: s.TeacherEmail, Name: s.TeacherName, Designation: s.TeacherDesignation},
+		Room:       nlRoom{ID: s.RoomID, Name: s.RoomCode, BlockNo: s.BlockNo},
+		Group:      nlGroup{ID: s.GroupID, Name: s.GroupName},
+		TimeSlot:   newNLTimeSlot(slot),
+	}
+}
+
+// matchesQuery applies the day and time filters of a parsed query.
+func matchesQuery(q nlquery.Query, slot timeslot.TimeSlot) bool {
+	if q.HasDay && slot.Day != q.Day {
+		return false
+	}
+	if q.HasTime && !slot.Contains(q.Minute) {
+		return false
+	}
+	return true
+}
+
+func (server *Server) naturalLanguageQuery(ctx *gin.Context) {
+	var req naturalLanguageQueryRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	q, err := nlquery.Parse(req.Query)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	year := q.Year
+	if year == 0 {
+		year = int32(bs.CurrentYear())
+	}
+	// queries are about the term running now
+	term, err := server.termOn(ctx, year, time.Time{})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	var schedules []detailedScheduleResponse
+	switch q.Kind {
+	case nlquery.KindGroup:
+		sections, err := server.store.FindStudentSections(ctx, db.FindStudentSectionsParams{
+			Program:      q.Program,
+			YearEnrolled: sql.NullInt32{Int32: q.Batch, Valid: q.Batch != 0},
+			GroupName:    sql.NullString{String: q.GroupName, Valid: q.GroupName != ""},
+		})
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+			return
+		}
+		if len(sections) == 0 {
+			ctx.JSON(http.StatusNotFound, errorResponse(fmt.Errorf("no student section matches %s %d %s", q.Program, q.Batch, q.GroupName)))
+			return
+		}
+		for _, section := range sections {
+			rows, err := server.groupSchedules(ctx, int64(section.ID), year, term, false)
+			if err != nil {
+				ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+				return
+			}
+			schedules = append(schedules, rows...)
+		}
+
+	case nlquery.KindTeacher:
+		teachers, err := server.store.SearchTeachersByName(ctx, q.Teacher)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+			return
+		}
+		if len(teachers) == 0 {
+			ctx.JSON(http.StatusNotFound, errorResponse(fmt.Errorf("no teacher named %q", q.Teacher)))
+			return
+		}
+		for _, teacher := range teachers {
+			rows, err := server.teacherSchedules(ctx, teacher.Email, year, term, false)
+			if err != nil {
+				ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+				return
+			}
+			schedules = append(schedules, rows...)
+		}
+
+	case nlquery.KindRoom:
+		room, err := server.store.GetRoomByCode(ctx, q.RoomCode)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				ctx.JSON(http.StatusNotFound, errorResponse(fmt.Errorf("room %s not found", q.RoomCode)))
+				return
+			}
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+			return
+		}
+		schedules, err = server.roomSchedules(ctx, int64(room.ID), year, term, false)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+			return
+		}
+
+	case nlquery.KindFreeRooms:
+		results, err := server.freeRoomResults(ctx, q, year, term)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusOK, results)
+		return
+	}
+
+	results := make([]nlQueryResult, 0)
+	for _, s := range schedules {
+		slot, err := timeslot.Parse(s.TimeSlot)
+		if err != nil || !matchesQuery(q, slot) {
+			continue
+		}
+		results = append(results, newNLQueryResult(s, slot))
+	}
+	ctx.JSON(http.StatusOK, results)
+}
+
+// freeRoomResults lists rooms with nothing scheduled at the asked time, or
+// for the whole teaching day of the default grid when no time is given.
+// Without a day every working day is checked.
+func (server *Server) freeRoomResults(ctx *gin.Context, q nlquery.Query, year int32, term int16) ([]nlQueryResult, error) {
+	week, err := validation.GridFor(ctx, server.store, 0)
+	if err != nil {
+		return nil, err
+	}
+	days := week.Days
+	if q.HasDay {
+		days = []timeslot.Day{q.Day}
+	}
+	periods := week.Teaching()
+	// a grid of nothing but breaks has no teaching day to look at
+	if len(periods) == 0 {
+		periods = timeslot.DefaultWeek().Teaching()
+	}
+	start, end := periods[0].Start, periods[len(periods)-1].End
+	if q.HasTime {
+		start, end = q.Minute, q.Minute+1
+	}
+
+	results := make([]nlQueryResult, 0)
+	for _, day := range days {
+		rooms, err := server.store.ListFreeRooms(ctx, db.ListFreeRoomsParams{
+			BlockNo:     sql.NullString{String: q.Block, Valid: q.Block != ""},
+			FloorNo:     sql.NullInt32{Int32: q.Floor, Valid: q.HasFloor},
+			Year:        year,
+			Term:        term,
+			DayOfWeek:   int16(day),
+			StartMinute: start,
+			EndMinute:   end,
+		})
+		if err != nil {
+			return nil, err
+		}
+		slot := timeslot.TimeSlot{Day: day, Start: start, End: end}
+		if q.HasTime {
+			slot.End = start
+		}
+		for _, room := range rooms {
+			results = append(results, nlQueryResult{
+				Year: year,
+				Room: nlRoom{
+					ID:      int64(room.ID),
+					Name:    room.RoomCode.String,
+					BlockNo: room.BlockNo.String,
+				},
+				TimeSlot: newNLTimeSlot(slot),
+			})
+		}
+	}
+	return results, nil
+}
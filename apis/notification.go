@@ -0,0 +1,212 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/nirajan1111/routiney/db/sqlc"
+	"github.com/nirajan1111/routiney/notify"
+	"github.com/nirajan1111/routiney/token"
+)
+
+type notificationPreferencesRequest struct {
+	RoutineChanges *bool `json:"routine_changes" binding:"required"`
+}
+
+type notificationPreferencesResponse struct {
+	Email          string `json:"email"`
+	RoutineChanges bool   `json:"routine_changes"`
+}
+
+type listEmailOutboxRequest struct {
+	Status string `form:"status" binding:"omitempty,oneof=pending sent failed"`
+}
+
+type emailOutboxResponse struct {
+	ID            int64      `json:"id"`
+	Recipient     string     `json:"recipient"`
+	Subject       string     `json:"subject"`
+	Status        string     `json:"status"`
+	Attempts      int32      `json:"attempts"`
+	LastError     string     `json:"last_error,omitempty"`
+	NextAttemptAt time.Time  `json:"next_attempt_at"`
+	CreatedAt     time.Time  `json:"created_at"`
+	SentAt        *time.Time `json:"sent_at,omitempty"`
+}
+
+func newEmailOutboxResponse(e db.EmailOutbox) emailOutboxResponse {
+	res := emailOutboxResponse{
+		ID:            e.ID,
+		Recipient:     e.Recipient,
+		Subject:       e.Subject,
+		Status:        string(e.Status),
+		Attempts:      e.Attempts,
+		LastError:     e.LastError.String,
+		NextAttemptAt: e.NextAttemptAt,
+		CreatedAt:     e.CreatedAt,
+	}
+	if e.SentAt.Valid {
+		res.SentAt = &e.SentAt.Time
+	}
+	return res
+}
+
+// activeVersion returns the version of a term's routine that is live, or
+// nil before the term is first published.
+func activeVersion(ctx context.Context, q *db.Queries, year int32, term int16) (*db.RoutineVersion, error) {
+	version, err := q.GetActiveRoutineVersion(ctx, db.GetActiveRoutineVersionParams{Year: year, Term: term})
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &version, nil
+}
+
+// notifyPublished queues emails about the classes moved or cancelled
+// between the version that was live and the one that is now. It runs in
+// the transaction making current live, so they are queued only if that
+// commits. A term published for the first time has no changes to tell.
+func (server *Server) notifyPublished(ctx context.Context, q *db.Queries, previous *db.RoutineVersion, current db.RoutineVersion) error {
+	if !server.notifications || previous == nil {
+		return nil
+	}
+	_, err := notify.RoutinePublished(ctx, q, *previous, current)
+	return err
+}
+
+func currentUserEmail(ctx *gin.Context) (string, error) {
+	payload, ok := ctx.Value("user").(*token.Payload)
+	if !ok {
+		return "", fmt.Errorf("not authenticated")
+	}
+	return strings.ToLower(payload.Email), nil
+}
+
+// getNotificationPreferences tells the signed in user whether they get
+// emails about routine changes.
+func (server *Server) getNotificationPreferences(ctx *gin.Context) {
+	email, err := currentUserEmail(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return
+	}
+
+	_, err = server.store.GetNotificationOptOut(ctx, email)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	ctx.JSON(http.StatusOK, notificationPreferencesResponse{Email: email, RoutineChanges: err != nil})
+}
+
+// updateNotificationPreferences turns routine change emails on or off for
+// the signed in user, at every address they are known by: their login and
+// those of the teacher or student they are linked to.
+func (server *Server) updateNotificationPreferences(ctx *gin.Context) {
+	var req notificationPreferencesRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	email, err := currentUserEmail(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return
+	}
+
+	addresses, err := server.store.ListUserEmailAddresses(ctx, email)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	addresses = append(addresses, email)
+	err = server.store.ExecTx(ctx, func(q *db.Queries) error {
+		for _, a := range addresses {
+			a = strings.ToLower(a)
+			var err error
+			if *req.RoutineChanges {
+				err = q.DeleteNotificationOptOut(ctx, a)
+			} else {
+				err = q.CreateNotificationOptOut(ctx, a)
+			}
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	ctx.JSON(http.StatusOK, notificationPreferencesResponse{Email: email, RoutineChanges: *req.RoutineChanges})
+}
+
+// listEmailOutbox shows the latest queued emails, optionally only those
+// with a ?status=.
+func (server *Server) listEmailOutbox(ctx *gin.Context) {
+	var req listEmailOutboxRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	adminStatus, err := checkAdmin(ctx, "admin")
+	if err != nil {
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return
+	}
+	if !adminStatus {
+		ctx.JSON(http.StatusForbidden, errorResponse(fmt.Errorf("not authorized to view the email outbox")))
+		return
+	}
+
+	emails, err := server.store.ListEmailOutbox(ctx, db.NullEmailStatus{EmailStatus: db.EmailStatus(req.Status), Valid: req.Status != ""})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	res := make([]emailOutboxResponse, 0, len(emails))
+	for _, e := range emails {
+		res = append(res, newEmailOutboxResponse(e))
+	}
+	ctx.JSON(http.StatusOK, res)
+}
+
+// retryEmail queues an email that gave up for another round of attempts.
+func (server *Server) retryEmail(ctx *gin.Context) {
+	var uri getScheduleRequest
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	adminStatus, err := checkAdmin(ctx, "admin")
+	if err != nil {
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return
+	}
+	if !adminStatus {
+		ctx.JSON(http.StatusForbidden, errorResponse(fmt.Errorf("not authorized to retry emails")))
+		return
+	}
+
+	n, err := server.store.RetryEmail(ctx, uri.ID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	if n == 0 {
+		ctx.JSON(http.StatusNotFound, errorResponse(fmt.Errorf("no failed email %d", uri.ID)))
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"message": "Email queued for retry"})
+}
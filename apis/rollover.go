@@ -0,0 +1,74 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nirajan1111/routiney/rollover"
+)
+
+type rolloverRequest struct {
+	FromYear   int32             `json:"from_year" binding:"required"`
+	FromTerm   int16             `json:"from_term" binding:"omitempty,min=1"`
+	ToYear     int32             `json:"to_year"`
+	ToTerm     int16             `json:"to_term" binding:"omitempty,min=1"`
+	Department string            `json:"department"`
+	Program    string            `json:"program"`
+	GroupIDs   []int64           `json:"group_ids"`
+	TeacherMap map[string]string `json:"teacher_map"`
+	RoomMap    map[int64]int64   `json:"room_map"`
+	GroupMap   map[int64]int64   `json:"group_map"`
+	DryRun     bool              `json:"dry_run"`
+}
+
+// rolloverSchedules copies a term's routine into another term, reporting
+// rows that clash in the target term. Without a target year it copies into
+// the next year, or into another term of the same year when to_term is set.
+func (server *Server) rolloverSchedules(ctx *gin.Context) {
+	var req rolloverRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	adminStatus, err := checkAdmin(ctx, "admin")
+	if err != nil {
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return
+	}
+	if !adminStatus {
+		ctx.JSON(http.StatusForbidden, errorResponse(fmt.Errorf("not authorized to roll over schedules")))
+		return
+	}
+	if req.ToYear == 0 {
+		req.ToYear = req.FromYear
+		if req.ToTerm == 0 {
+			req.ToYear++
+		}
+	}
+
+	report, err := rollover.Run(ctx, server.store, rollover.Options{
+		FromYear:   req.FromYear,
+		FromTerm:   req.FromTerm,
+		ToYear:     req.ToYear,
+		ToTerm:     req.ToTerm,
+		Department: req.Department,
+		Program:    req.Program,
+		GroupIDs:   req.GroupIDs,
+		TeacherMap: req.TeacherMap,
+		RoomMap:    req.RoomMap,
+		GroupMap:   req.GroupMap,
+		DryRun:     req.DryRun,
+	})
+	if err != nil {
+		if errors.Is(err, rollover.ErrInvalid) {
+			ctx.JSON(http.StatusBadRequest, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	ctx.JSON(http.StatusOK, report)
+}
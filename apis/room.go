@@ -1,28 +1,36 @@
 package api
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 	db "github.com/nirajan1111/routiney/db/sqlc"
 )
 
 type addRoomRequest struct {
-	Room_code        string `json:"room_code" binding:"required"`
-	Block_no         string `json:"block_no" binding:"required"`
-	Department       string `json:"department" binding:"required"`
-	Floor_no         int32  `json:"floor_no" binding:"required"`
-	Screen_available bool   `json:"screen_available" binding:"required"`
+	Room_code        string   `json:"room_code" binding:"required"`
+	Block_no         string   `json:"block_no" binding:"required"`
+	Department       string   `json:"department" binding:"required"`
+	Floor_no         int32    `json:"floor_no" binding:"required"`
+	Screen_available bool     `json:"screen_available" binding:"required"`
+	Capacity         int32    `json:"capacity" binding:"min=0"`
+	Room_type        string   `json:"room_type" binding:"omitempty,oneof=lecture_hall computer_lab laboratory workshop"`
+	Features         []string `json:"features" binding:"omitempty,dive,required,max=30"`
 }
 type newRoomresponse struct {
-	ID               int32  `json:"id"`
-	Room_code        string `json:"room_code"`
-	Block_no         string `json:"block_no"`
-	Department       string `json:"department"`
-	Floor_no         int32  `json:"floor_no"`
-	Screen_available bool   `json:"screen_available"`
+	ID               int32    `json:"id"`
+	Room_code        string   `json:"room_code"`
+	Block_no         string   `json:"block_no"`
+	Department       string   `json:"department"`
+	Floor_no         int32    `json:"floor_no"`
+	Screen_available bool     `json:"screen_available"`
+	Capacity         int32    `json:"capacity,omitempty"`
+	Room_type        string   `json:"room_type"`
+	Features         []string `json:"features,omitempty"`
 }
 type updateRoomRequest struct {
 	Room_code        string `json:"room_code" binding:"required"`
@@ -30,6 +38,57 @@ type updateRoomRequest struct {
 	Department       string `json:"department" binding:"required"`
 	Floor_no         int32  `json:"floor_no" binding:"required"`
 	Screen_available bool   `json:"screen_available" binding:"required"`
+	// Capacity, Room_type and Features keep their current values when left
+	// out.
+	Capacity  *int32   `json:"capacity" binding:"omitempty,min=0"`
+	Room_type string   `json:"room_type" binding:"omitempty,oneof=lecture_hall computer_lab laboratory workshop"`
+	Features  []string `json:"features" binding:"omitempty,dive,required,max=30"`
+}
+
+func newRoomResponse(room db.Room) newRoomresponse {
+	return newRoomresponse{
+		ID:               room.ID,
+		Room_code:        room.RoomCode.String,
+		Block_no:         room.BlockNo.String,
+		Department:       room.Department.String,
+		Floor_no:         room.FloorNo.Int32,
+		Screen_available: room.ScreenAvailable.Bool,
+		Capacity:         room.Capacity.Int32,
+		Room_type:        string(room.RoomType),
+	}
+}
+
+func roomType(s string) db.RoomType {
+	if s == "" {
+		return db.RoomTypeLectureHall
+	}
+	return db.RoomType(s)
+}
+
+// normalizeFeatures lowercases and trims feature tags and drops duplicates.
+func normalizeFeatures(features []string) []string {
+	seen := make(map[string]bool, len(features))
+	out := make([]string, 0, len(features))
+	for _, f := range features {
+		f = strings.ToLower(strings.TrimSpace(f))
+		if f == "" || seen[f] {
+			continue
+		}
+		seen[f] = true
+		out = append(out, f)
+	}
+	return out
+}
+
+// setRoomFeatures replaces the feature tags of a room.
+func setRoomFeatures(ctx context.Context, q *db.Queries, roomID int32, features []string) error {
+	if err := q.DeleteRoomFeatures(ctx, roomID); err != nil {
+		return err
+	}
+	if len(features) == 0 {
+		return nil
+	}
+	return q.AddRoomFeatures(ctx, db.AddRoomFeaturesParams{RoomID: roomID, Features: features})
 }
 
 func (server *Server) addRoom(ctx *gin.Context) {
@@ -61,10 +120,20 @@ func (server *Server) addRoom(ctx *gin.Context) {
 		RoomCode:   StringToSQLNullString(req.Room_code),
 		Department: StringToSQLNullString(req.Department),
 		BlockNo:    StringToSQLNullString(req.Block_no),
+		Capacity:   sql.NullInt32{Int32: req.Capacity, Valid: req.Capacity > 0},
+		RoomType:   roomType(req.Room_type),
 	}
 	fmt.Printf("Creating room with params: %+v\n", arg)
 
-	room, err := server.store.CreateRoom(ctx, arg)
+	var room db.Room
+	err = server.store.ExecTx(ctx, func(q *db.Queries) error {
+		var err error
+		room, err = q.CreateRoom(ctx, arg)
+		if err != nil {
+			return err
+		}
+		return setRoomFeatures(ctx, q, room.ID, normalizeFeatures(req.Features))
+	})
 	if err != nil {
 		ctx.JSON(500, errorResponse(err))
 		return
@@ -92,17 +161,21 @@ func (server *Server) listRooms(ctx *gin.Context) {
 		return
 	}
 
+	features, err := server.store.ListAllRoomFeatures(ctx)
+	if err != nil {
+		ctx.JSON(500, errorResponse(err))
+		return
+	}
+	featuresByRoom := map[int32][]string{}
+	for _, f := range features {
+		featuresByRoom[f.RoomID] = append(featuresByRoom[f.RoomID], f.Feature)
+	}
+
 	var roomResponses []newRoomresponse
 	for _, room := range rooms {
-		roomResponse := newRoomresponse{
-			ID:               room.ID,
-			Room_code:        room.RoomCode.String,
-			Block_no:         room.BlockNo.String,
-			Department:       room.Department.String,
-			Floor_no:         room.FloorNo.Int32,
-			Screen_available: room.ScreenAvailable.Bool,
-		}
-		roomResponses = append(roomResponses, roomResponse)
+		res := newRoomResponse(room)
+		res.Features = featuresByRoom[room.ID]
+		roomResponses = append(roomResponses, res)
 	}
 	fmt.Println(roomResponses)
 	if len(roomResponses) == 0 {
@@ -160,6 +233,18 @@ func (server *Server) updateRoom(ctx *gin.Context) {
 		ctx.JSON(400, errorResponse(err))
 		return
 	}
+	current, err := server.store.GetRoom(ctx, int32(room_id_int))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(404, errorResponse(err))
+			return
+		}
+		ctx.JSON(500, errorResponse(err))
+		return
+	}
+	if reqData.Room_type == "" {
+		reqData.Room_type = string(current.RoomType)
+	}
 	arg := db.UpdateRoomParams{
 		ID: int32(room_id_int),
 		Department: sql.NullString{
@@ -182,8 +267,22 @@ func (server *Server) updateRoom(ctx *gin.Context) {
 			Bool:  reqData.Screen_available,
 			Valid: true,
 		},
+		Capacity: current.Capacity,
+		RoomType: roomType(reqData.Room_type),
 	}
-	room, err := server.store.UpdateRoom(ctx, arg)
+	// a capacity of 0 clears it
+	if reqData.Capacity != nil {
+		arg.Capacity = sql.NullInt32{Int32: *reqData.Capacity, Valid: *reqData.Capacity > 0}
+	}
+	var room db.Room
+	err = server.store.ExecTx(ctx, func(q *db.Queries) error {
+		var err error
+		room, err = q.UpdateRoom(ctx, arg)
+		if err != nil || reqData.Features == nil {
+			return err
+		}
+		return setRoomFeatures(ctx, q, room.ID, normalizeFeatures(reqData.Features))
+	})
 	if err != nil {
 		ctx.JSON(500, errorResponse(err))
 		return
@@ -0,0 +1,350 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nirajan1111/routiney/bs"
+	db "github.com/nirajan1111/routiney/db/sqlc"
+	"github.com/nirajan1111/routiney/ical"
+	"github.com/nirajan1111/routiney/timeslot"
+	"github.com/nirajan1111/routiney/validation"
+)
+
+type createRoomBookingRequest struct {
+	RoomID    int32  `json:"room_id" binding:"required,min=1"`
+	Date      string `json:"date" binding:"required"`
+	StartTime string `json:"start_time" binding:"required"`
+	EndTime   string `json:"end_time" binding:"required"`
+	Purpose   string `json:"purpose" binding:"required,max=200"`
+}
+
+type listRoomBookingsRequest struct {
+	RoomID int32  `form:"room_id" binding:"min=0"`
+	From   string `form:"from"`
+	To     string `form:"to"`
+}
+
+type roomBookingResponse struct {
+	ID        int64     `json:"id"`
+	RoomID    int32     `json:"room_id"`
+	RoomCode  string    `json:"room_code,omitempty"`
+	Date      string    `json:"date"`
+	DateBs    string    `json:"date_bs,omitempty"`
+	StartTime string    `json:"start_time"`
+	EndTime   string    `json:"end_time"`
+	Purpose   string    `json:"purpose"`
+	BookedBy  string    `json:"booked_by"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func newRoomBookingResponse(b db.RoomBooking, roomCode sql.NullString) roomBookingResponse {
+	return roomBookingResponse{
+		ID:        b.ID,
+		RoomID:    b.RoomID,
+		RoomCode:  roomCode.String,
+		Date:      b.Date.Format(dateLayout),
+		DateBs:    bsDate(b.Date),
+		StartTime: timeslot.FormatClock(b.StartMinute),
+		EndTime:   timeslot.FormatClock(b.EndMinute),
+		Purpose:   b.Purpose,
+		BookedBy:  b.BookedBy,
+		CreatedAt: b.CreatedAt,
+	}
+}
+
+func newRoomBookingResponseFromRow(row db.ListRoomBookingsRow) roomBookingResponse {
+	return newRoomBookingResponse(db.RoomBooking{
+		ID:          row.ID,
+		RoomID:      row.RoomID,
+		Date:        row.Date,
+		StartMinute: row.StartMinute,
+		EndMinute:   row.EndMinute,
+		Purpose:     row.Purpose,
+		BookedBy:    row.BookedBy,
+		CreatedAt:   row.CreatedAt,
+	}, row.RoomCode)
+}
+
+// bookingSlot is the time slot a booking takes on the weekday of its date.
+func bookingSlot(date time.Time, start, end int32) timeslot.TimeSlot {
+	return timeslot.TimeSlot{Day: timeslot.Day(date.Weekday()), Start: start, End: end}
+}
+
+// roomBookings lists the bookings of a room between two dates, inclusive.
+func (server *Server) roomBookings(ctx *gin.Context, roomID int64, from, to time.Time) ([]roomBookingResponse, error) {
+	rows, err := server.store.ListRoomBookings(ctx, db.ListRoomBookingsParams{
+		RoomID:   sql.NullInt32{Int32: int32(roomID), Valid: true},
+		FromDate: sql.NullTime{Time: from, Valid: true},
+		ToDate:   sql.NullTime{Time: to, Valid: true},
+	})
+	if err != nil {
+		return nil, err
+	}
+	bookings := make([]roomBookingResponse, 0, len(rows))
+	for _, row := range rows {
+		bookings = append(bookings, newRoomBookingResponseFromRow(row))
+	}
+	return bookings, nil
+}
+
+// bookingEntries turns bookings into routine entries so that dated room
+// views list them next to the weekly classes.
+func bookingEntries(bookings []roomBookingResponse, year int32) []detailedScheduleResponse {
+	entries := make([]detailedScheduleResponse, 0, len(bookings))
+	for i := range bookings {
+		b := &bookings[i]
+		date, err := time.Parse(dateLayout, b.Date)
+		if err != nil {
+			continue
+		}
+		start, _ := timeslot.ParseClock(b.StartTime)
+		end, _ := timeslot.ParseClock(b.EndTime)
+		entries = append(entries, detailedScheduleResponse{
+			RoomID:   int64(b.RoomID),
+			RoomCode: b.RoomCode,
+			TimeSlot: bookingSlot(date, start, end).String(),
+			Year:     year,
+			Booking:  b,
+		})
+	}
+	return entries
+}
+
+// bookingNotes describes a room's bookings for printed routines.
+func bookingNotes(bookings []roomBookingResponse) []string {
+	notes := make([]string, 0, len(bookings))
+	for _, b := range bookings {
+		notes = append(notes, fmt.Sprintf("Booked %s %s-%s: %s", b.Date, b.StartTime, b.EndTime, b.Purpose))
+	}
+	return notes
+}
+
+// bookingEvents turns bookings into single calendar events.
+func bookingEvents(bookings []roomBookingResponse) []ical.Event {
+	events := make([]ical.Event, 0, len(bookings))
+	for _, b := range bookings {
+		date, err := time.Parse(dateLayout, b.Date)
+		if err != nil {
+			continue
+		}
+		start, _ := timeslot.ParseClock(b.StartTime)
+		end, _ := timeslot.ParseClock(b.EndTime)
+		events = append(events, ical.Event{
+			UID:         fmt.Sprintf("booking-%d@routiney", b.ID),
+			Summary:     b.Purpose,
+			Location:    b.RoomCode,
+			Description: "Booked by " + b.BookedBy,
+			Slot:        bookingSlot(date, start, end),
+			Date:        date,
+		})
+	}
+	return events
+}
+
+// createRoomBooking books a room for a one-off event. The booking must not
+// overlap the weekly classes held in the room on that weekday, unless the
+// date has no classes, nor another booking.
+func (server *Server) createRoomBooking(ctx *gin.Context) {
+	var req createRoomBookingRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	date, err := parseDate("date", req.Date)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	start, err := timeslot.ParseClock(req.StartTime)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(fmt.Errorf("invalid start_time: %w", err)))
+		return
+	}
+	end, err := timeslot.ParseClock(req.EndTime)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(fmt.Errorf("invalid end_time: %w", err)))
+		return
+	}
+	slot := bookingSlot(date, start, end)
+	if err := slot.Validate(); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	payload, err := payloadFromContext(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return
+	}
+	if _, err := server.store.GetRoom(ctx, req.RoomID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(http.StatusNotFound, errorResponse(fmt.Errorf("room not found")))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	year := int32(bs.YearOf(date))
+	holidays, err := server.noClassDays(ctx, year)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	term, err := server.termOn(ctx, year, date)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	var classes []db.ListScheduleConflictsRow
+	if !onHoliday(holidays, date) {
+		classes, err = server.store.ListScheduleConflicts(ctx, db.ListScheduleConflictsParams{
+			Year:        year,
+			Term:        term,
+			DayOfWeek:   int16(slot.Day),
+			EndMinute:   slot.End,
+			StartMinute: slot.Start,
+			RoomID:      sql.NullInt64{Int64: int64(req.RoomID), Valid: true},
+		})
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+			return
+		}
+	}
+	bookings, err := server.store.ListRoomBookingConflicts(ctx, db.ListRoomBookingConflictsParams{
+		RoomID:      req.RoomID,
+		Date:        date,
+		EndMinute:   slot.End,
+		StartMinute: slot.Start,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	if len(classes) > 0 || len(bookings) > 0 {
+		ctx.JSON(http.StatusConflict, bookingConflictResponse(classes, bookings))
+		return
+	}
+
+	booking, err := server.store.CreateRoomBooking(ctx, db.CreateRoomBookingParams{
+		RoomID:      req.RoomID,
+		Date:        date,
+		StartMinute: slot.Start,
+		EndMinute:   slot.End,
+		Purpose:     req.Purpose,
+		BookedBy:    payload.Email,
+	})
+	if err != nil {
+		// lost a race against a booking made in the meantime
+		if validation.IsExclusionViolation(err) {
+			ctx.JSON(http.StatusConflict, errorResponse(fmt.Errorf("the room was booked for this time in the meantime")))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	ctx.JSON(http.StatusOK, newRoomBookingResponse(booking, sql.NullString{}))
+}
+
+func bookingConflictResponse(classes []db.ListScheduleConflictsRow, bookings []db.RoomBooking) gin.H {
+	schedules := make([]gin.H, 0, len(classes))
+	for _, c := range classes {
+		schedules = append(schedules, gin.H{"schedule_id": c.ID, "time_slot": c.TimeSlot.String})
+	}
+	others := make([]roomBookingResponse, 0, len(bookings))
+	for _, b := range bookings {
+		others = append(others, newRoomBookingResponse(b, sql.NullString{}))
+	}
+	return gin.H{
+		"error":     "the room is not free at this time",
+		"schedules": schedules,
+		"bookings":  others,
+	}
+}
+
+func (server *Server) listRoomBookings(ctx *gin.Context) {
+	var req listRoomBookingsRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	arg := db.ListRoomBookingsParams{
+		RoomID: sql.NullInt32{Int32: req.RoomID, Valid: req.RoomID != 0},
+	}
+	if req.From != "" {
+		from, err := parseDate("from", req.From)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, errorResponse(err))
+			return
+		}
+		arg.FromDate = sql.NullTime{Time: from, Valid: true}
+	}
+	if req.To != "" {
+		to, err := parseDate("to", req.To)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, errorResponse(err))
+			return
+		}
+		arg.ToDate = sql.NullTime{Time: to, Valid: true}
+	}
+
+	rows, err := server.store.ListRoomBookings(ctx, arg)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	res := make([]roomBookingResponse, 0, len(rows))
+	for _, row := range rows {
+		res = append(res, newRoomBookingResponseFromRow(row))
+	}
+	ctx.JSON(http.StatusOK, res)
+}
+
+// deleteRoomBooking cancels a booking. Only its booker or an admin may.
+func (server *Server) deleteRoomBooking(ctx *gin.Context) {
+	var uri getScheduleRequest
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	booking, err := server.store.GetRoomBooking(ctx, uri.ID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(http.StatusNotFound, errorResponse(fmt.Errorf("booking not found")))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	payload, err := payloadFromContext(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return
+	}
+	if payload.Email != booking.BookedBy {
+		adminStatus, err := checkAdmin(ctx, "admin")
+		if err != nil {
+			ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+			return
+		}
+		if !adminStatus {
+			ctx.JSON(http.StatusForbidden, errorResponse(fmt.Errorf("not authorized to cancel this booking")))
+			return
+		}
+	}
+
+	if err := server.store.DeleteRoomBooking(ctx, booking.ID); err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"message": "Booking cancelled successfully"})
+}
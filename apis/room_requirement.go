@@ -0,0 +1,148 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/nirajan1111/routiney/db/sqlc"
+)
+
+type roomRequirementRequest struct {
+	SessionType string `json:"session_type" binding:"omitempty,oneof=lecture lab tutorial"`
+	// RoomType left empty accepts any type of room.
+	RoomType string   `json:"room_type" binding:"omitempty,oneof=lecture_hall computer_lab laboratory workshop"`
+	Features []string `json:"features" binding:"omitempty,dive,required,max=30"`
+}
+
+type deleteRoomRequirementRequest struct {
+	ID            int64 `uri:"id" binding:"required,min=1"`
+	RequirementID int64 `uri:"requirement_id" binding:"required,min=1"`
+}
+
+type roomRequirementResponse struct {
+	ID          int64    `json:"id"`
+	SubjectID   int64    `json:"subject_id"`
+	SessionType string   `json:"session_type"`
+	RoomType    string   `json:"room_type,omitempty"`
+	Features    []string `json:"features"`
+}
+
+func newRoomRequirementResponse(r db.SubjectRoomRequirement) roomRequirementResponse {
+	res := roomRequirementResponse{
+		ID:          r.ID,
+		SubjectID:   r.SubjectID,
+		SessionType: string(r.SessionType),
+		Features:    r.Features,
+	}
+	if r.RoomType.Valid {
+		res.RoomType = string(r.RoomType.RoomType)
+	}
+	if res.Features == nil {
+		res.Features = []string{}
+	}
+	return res
+}
+
+func (server *Server) listRoomRequirements(ctx *gin.Context) {
+	var uri getSubjectRequest
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	requirements, err := server.store.ListSubjectRoomRequirements(ctx, uri.ID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	res := make([]roomRequirementResponse, 0, len(requirements))
+	for _, r := range requirements {
+		res = append(res, newRoomRequirementResponse(r))
+	}
+	ctx.JSON(http.StatusOK, res)
+}
+
+// setRoomRequirement creates the subject's room requirement for a session
+// type, or replaces the one it already has.
+func (server *Server) setRoomRequirement(ctx *gin.Context) {
+	var uri getSubjectRequest
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	var req roomRequirementRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	adminStatus, err := checkAdmin(ctx, "admin")
+	if err != nil {
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return
+	}
+	if !adminStatus {
+		ctx.JSON(http.StatusForbidden, errorResponse(fmt.Errorf("not authorized to edit room requirements")))
+		return
+	}
+
+	if _, err := server.store.GetSubject(ctx, uri.ID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(http.StatusNotFound, errorResponse(fmt.Errorf("subject not found")))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	if req.RoomType == "" && len(req.Features) == 0 {
+		ctx.JSON(http.StatusBadRequest, errorResponse(fmt.Errorf("room_type or features is required")))
+		return
+	}
+
+	requirement, err := server.store.UpsertSubjectRoomRequirement(ctx, db.UpsertSubjectRoomRequirementParams{
+		SubjectID:   uri.ID,
+		SessionType: sessionType(req.SessionType),
+		RoomType:    db.NullRoomType{RoomType: db.RoomType(req.RoomType), Valid: req.RoomType != ""},
+		Features:    normalizeFeatures(req.Features),
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	ctx.JSON(http.StatusOK, newRoomRequirementResponse(requirement))
+}
+
+func (server *Server) deleteRoomRequirement(ctx *gin.Context) {
+	var uri deleteRoomRequirementRequest
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	adminStatus, err := checkAdmin(ctx, "admin")
+	if err != nil {
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return
+	}
+	if !adminStatus {
+		ctx.JSON(http.StatusForbidden, errorResponse(fmt.Errorf("not authorized to edit room requirements")))
+		return
+	}
+
+	n, err := server.store.DeleteSubjectRoomRequirement(ctx, db.DeleteSubjectRoomRequirementParams{
+		ID:        uri.RequirementID,
+		SubjectID: uri.ID,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	if n == 0 {
+		ctx.JSON(http.StatusNotFound, errorResponse(fmt.Errorf("room requirement not found")))
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"message": "Room requirement deleted successfully"})
+}
@@ -0,0 +1,77 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	_ "github.com/lib/pq"
+	db "github.com/nirajan1111/routiney/db/sqlc"
+)
+
+// testServer returns a server on the migrated database in TEST_DB_SOURCE
+// and an admin token for it. Tests are skipped without one.
+func testServer(t *testing.T) (*Server, *db.Store, string) {
+	t.Helper()
+	source := os.Getenv("TEST_DB_SOURCE")
+	if source == "" {
+		t.Skip("TEST_DB_SOURCE is not set")
+	}
+	conn, err := sql.Open("postgres", source)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	gin.SetMode(gin.TestMode)
+	store := db.NewStore(conn)
+	server, err := NewServer(store, "12345678901234567890123456789012", time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	token, err := server.tokenMaker.CreateToken("admin@example.com", "admin", time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return server, store, token
+}
+
+func TestUpdateRoomKeepsCapacity(t *testing.T) {
+	server, store, token := testServer(t)
+	ctx := context.Background()
+
+	room, err := store.CreateRoom(ctx, db.CreateRoomParams{
+		RoomCode: sql.NullString{String: "TEST-101", Valid: true},
+		Capacity: sql.NullInt32{Int32: 48, Valid: true},
+		RoomType: db.RoomTypeLectureHall,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { store.DeleteRoom(ctx, room.ID) })
+
+	// the room form sends no capacity
+	body := `{"room_code":"TEST-102","block_no":"A","department":"DOECE","floor_no":1,"screen_available":true}`
+	req := httptest.NewRequest(http.MethodPut, "/rooms/"+strconv.Itoa(int(room.ID)), strings.NewReader(body))
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	server.router.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status %d: %s", rec.Code, rec.Body)
+	}
+
+	updated, err := store.GetRoom(ctx, room.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updated.RoomCode.String != "TEST-102" || updated.Capacity != room.Capacity {
+		t.Errorf("after the update the room is %s with capacity %v, want TEST-102 with 48", updated.RoomCode.String, updated.Capacity)
+	}
+}
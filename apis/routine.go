@@ -0,0 +1,553 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nirajan1111/routiney/bs"
+	db "github.com/nirajan1111/routiney/db/sqlc"
+	"github.com/nirajan1111/routiney/scheduler"
+	"github.com/nirajan1111/routiney/timeslot"
+	"github.com/nirajan1111/routiney/validation"
+)
+
+type routineRequirementRequest struct {
+	GroupID        int64  `json:"group_id" binding:"required"`
+	SubjectID      int64  `json:"subject_id" binding:"required"`
+	PeriodsPerWeek int    `json:"periods_per_week" binding:"required,min=1"`
+	TeacherEmail   string `json:"teacher_email"`
+}
+
+type optimizeRoutineRequest struct {
+	// Year is the year of study (1 for first year sections, ...), matched
+	// against each section's year_enrolled.
+	Year         int32  `json:"year"`
+	Semester     int32  `json:"semester"`
+	AcademicYear string `json:"academic_year"`
+	// ScheduleYear is the Nepali year the routine is generated for. It
+	// takes precedence over AcademicYear.
+	ScheduleYear int32 `json:"schedule_year"`
+	// Term is the term of ScheduleYear the routine is for. Without it odd
+	// semesters go in the first term and even ones in the second.
+	Term           int16                       `json:"term" binding:"omitempty,min=1"`
+	Department     string                      `json:"department"`
+	Program        string                      `json:"program"`
+	GroupIDs       []int64                     `json:"group_ids"`
+	Requirements   []routineRequirementRequest `json:"requirements"`
+	PeriodsPerWeek int                         `json:"periods_per_week"`
+	Slots          []string                    `json:"slots"`
+}
+
+type routineEntry struct {
+	GroupID      int64  `json:"group_id" binding:"required"`
+	GroupName    string `json:"group_name,omitempty"`
+	SubjectID    int64  `json:"subject_id" binding:"required"`
+	SubjectCode  string `json:"subject_code,omitempty"`
+	RoomID       int64  `json:"room_id" binding:"required"`
+	RoomCode     string `json:"room_code,omitempty"`
+	TeacherEmail string `json:"teacher_email" binding:"required,email"`
+	TimeSlot     string `json:"time_slot" binding:"required"`
+	SessionType  string `json:"session_type,omitempty" binding:"omitempty,oneof=lecture lab tutorial"`
+}
+
+type unplacedResponse struct {
+	GroupID   int64 `json:"group_id"`
+	SubjectID int64 `json:"subject_id"`
+	Missing   int   `json:"missing"`
+}
+
+type scoreResponse struct {
+	Gaps      int `json:"gaps"`
+	Imbalance int `json:"imbalance"`
+	Repeats   int `json:"repeats"`
+	Total     int `json:"total"`
+}
+
+type optimizeRoutineResponse struct {
+	ScheduleYear int32              `json:"schedule_year"`
+	Term         int16              `json:"term"`
+	Complete     bool               `json:"complete"`
+	Score        scoreResponse      `json:"score"`
+	Entries      []routineEntry     `json:"entries"`
+	Unplaced     []unplacedResponse `json:"unplaced"`
+}
+
+type applyRoutineRequest struct {
+	ScheduleYear int32          `json:"schedule_year" binding:"required"`
+	Term         int16          `json:"term" binding:"omitempty,min=1"`
+	Entries      []routineEntry `json:"entries" binding:"required,min=1,dive"`
+}
+
+// parseAcademicYear reads the leading year of values like "2081" or
+// "2023-2024". Years before 2060 are taken as AD and converted to the
+// Nepali year in which that academic year starts.
+func parseAcademicYear(s string) (int32, bool) {
+	digits := s
+	if i := strings.IndexFunc(s, func(r rune) bool { return r < '0' || r > '9' }); i >= 0 {
+		digits = s[:i]
+	}
+	year, err := strconv.Atoi(digits)
+	if err != nil || year < 1900 {
+		return 0, false
+	}
+	if year < 2060 {
+		year += 57
+	}
+	return int32(year), true
+}
+
+func (server *Server) optimizeRoutine(ctx *gin.Context) {
+	var req optimizeRoutineRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	adminStatus, err := checkAdmin(ctx, "admin")
+	if err != nil {
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return
+	}
+	if !adminStatus {
+		ctx.JSON(http.StatusForbidden, errorResponse(fmt.Errorf("not authorized to generate routines")))
+		return
+	}
+
+	scheduleYear := req.ScheduleYear
+	if scheduleYear == 0 && req.AcademicYear != "" {
+		year, ok := parseAcademicYear(req.AcademicYear)
+		if !ok {
+			ctx.JSON(http.StatusBadRequest, errorResponse(fmt.Errorf("invalid academic year %q", req.AcademicYear)))
+			return
+		}
+		scheduleYear = year
+	}
+	if scheduleYear == 0 {
+		scheduleYear = int32(bs.CurrentYear())
+	}
+	term := req.Term
+	if term == 0 {
+		term = 1
+		if req.Semester > 0 && req.Semester%2 == 0 {
+			term = 2
+		}
+	}
+
+	sections, err := server.store.ListAllStudentSections(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	selected := selectSections(sections, req, scheduleYear)
+	if len(selected) == 0 {
+		ctx.JSON(http.StatusNotFound, errorResponse(fmt.Errorf("no student sections match the request")))
+		return
+	}
+
+	week, status, err := server.sectionsWeek(ctx, selected)
+	if err != nil {
+		ctx.JSON(status, errorResponse(err))
+		return
+	}
+	slots := week.Slots()
+	if len(req.Slots) > 0 {
+		slots = make([]timeslot.TimeSlot, 0, len(req.Slots))
+		for _, s := range req.Slots {
+			slot, err := timeslot.Parse(s)
+			if err == nil {
+				err = week.Check(slot)
+			}
+			if err != nil {
+				ctx.JSON(http.StatusBadRequest, errorResponse(err))
+				return
+			}
+			slots = append(slots, slot)
+		}
+	}
+
+	assignments, err := server.store.ListSubjectTeacherAssignments(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	subjectTeachers := map[int64][]string{}
+	subjectDepartment := map[int64]string{}
+	for _, a := range assignments {
+		subjectTeachers[a.SubjectID] = append(subjectTeachers[a.SubjectID], a.TeacherEmail)
+		subjectDepartment[a.SubjectID] = a.Department.String
+	}
+
+	requirements, err := buildRequirements(selected, req, subjectTeachers, subjectDepartment)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	if len(requirements) == 0 {
+		ctx.JSON(http.StatusNotFound, errorResponse(fmt.Errorf("no subjects with assigned teachers found for the selected sections")))
+		return
+	}
+
+	sizes, err := server.store.ListStudentSectionSizes(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	groupSizes := make(map[int64]int, len(sizes))
+	for _, s := range sizes {
+		groupSizes[int64(s.ID)] = int(s.Size)
+	}
+	// Generated lessons are lectures, so only the lecture room
+	// requirements apply.
+	roomRequirements, err := server.store.ListAllSubjectRoomRequirements(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	lectureRequirement := map[int64]db.SubjectRoomRequirement{}
+	for _, r := range roomRequirements {
+		if r.SessionType == db.SessionTypeLecture {
+			lectureRequirement[r.SubjectID] = r
+		}
+	}
+	for i := range requirements {
+		requirements[i].Size = groupSizes[requirements[i].GroupID]
+		if r, ok := lectureRequirement[requirements[i].SubjectID]; ok {
+			if r.RoomType.Valid {
+				requirements[i].RoomType = string(r.RoomType.RoomType)
+			}
+			requirements[i].Features = r.Features
+		}
+	}
+
+	rooms, err := server.store.ListAllRooms(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	roomFeatures, err := server.store.ListAllRoomFeatures(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	featuresByRoom := map[int32][]string{}
+	for _, f := range roomFeatures {
+		featuresByRoom[f.RoomID] = append(featuresByRoom[f.RoomID], f.Feature)
+	}
+	existing, err := server.store.ListSchedulesByTerm(ctx, db.ListSchedulesByTermParams{Year: scheduleYear, Term: term})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	availability, err := server.store.ListAllTeacherAvailability(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	workloads, err := server.store.ListTeacherWorkloads(ctx, db.ListTeacherWorkloadsParams{Year: scheduleYear, Term: term})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	problem := scheduler.Problem{
+		Slots:        slots,
+		Requirements: requirements,
+		Availability: map[string]scheduler.Availability{},
+		MaxPeriods:   map[string]int{},
+	}
+	for _, w := range workloads {
+		if w.MaxPeriods.Valid {
+			problem.MaxPeriods[w.Email] = int(w.MaxPeriods.Int32)
+		}
+	}
+	for _, a := range availability {
+		slot := timeslot.TimeSlot{Day: timeslot.Day(a.DayOfWeek), Start: a.StartMinute, End: a.EndMinute}
+		avail := problem.Availability[a.TeacherEmail]
+		switch a.Kind {
+		case db.AvailabilityKindUnavailable:
+			avail.Unavailable = append(avail.Unavailable, slot)
+		case db.AvailabilityKindPreferred:
+			avail.Preferred = append(avail.Preferred, slot)
+		case db.AvailabilityKindDisliked:
+			avail.Disliked = append(avail.Disliked, slot)
+		}
+		problem.Availability[a.TeacherEmail] = avail
+	}
+	roomCodes := map[int64]string{}
+	for _, room := range rooms {
+		roomCodes[int64(room.ID)] = room.RoomCode.String
+		problem.Rooms = append(problem.Rooms, scheduler.Room{
+			ID:         int64(room.ID),
+			Code:       room.RoomCode.String,
+			Department: room.Department.String,
+			Capacity:   int(room.Capacity.Int32),
+			Type:       string(room.RoomType),
+			Features:   featuresByRoom[room.ID],
+		})
+	}
+	for _, schedule := range existing {
+		problem.Existing = append(problem.Existing, scheduler.Booking{
+			GroupID:          schedule.GroupID.Int64,
+			CombinedGroupIDs: schedule.CombinedGroupIds,
+			RoomID:           schedule.RoomID.Int64,
+			TeacherEmail:     schedule.TeacherEmail.String,
+			Slot: timeslot.TimeSlot{
+				Day:   timeslot.Day(schedule.DayOfWeek),
+				Start: schedule.StartMinute,
+				End:   schedule.EndMinute,
+			},
+		})
+	}
+
+	solution := scheduler.Solve(problem, scheduler.Options{})
+
+	groupNames := map[int64]string{}
+	for _, section := range selected {
+		groupNames[int64(section.ID)] = section.Name.String
+	}
+	subjects, err := server.store.ListAllSubjects(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	subjectCodes := map[int64]string{}
+	for _, subject := range subjects {
+		subjectCodes[subject.ID] = subject.SubjectCode.String
+	}
+
+	res := optimizeRoutineResponse{
+		ScheduleYear: scheduleYear,
+		Term:         term,
+		Complete:     solution.Complete,
+		Score: scoreResponse{
+			Gaps:      solution.Score.Gaps,
+			Imbalance: solution.Score.Imbalance,
+			Repeats:   solution.Score.Repeats,
+			Total:     solution.Score.Total,
+		},
+		Entries:  make([]routineEntry, 0, len(solution.Assignments)),
+		Unplaced: make([]unplacedResponse, 0, len(solution.Unplaced)),
+	}
+	for _, a := range solution.Assignments {
+		res.Entries = append(res.Entries, routineEntry{
+			GroupID:      a.GroupID,
+			GroupName:    groupNames[a.GroupID],
+			SubjectID:    a.SubjectID,
+			SubjectCode:  subjectCodes[a.SubjectID],
+			RoomID:       a.RoomID,
+			RoomCode:     roomCodes[a.RoomID],
+			TeacherEmail: a.TeacherEmail,
+			TimeSlot:     a.Slot.String(),
+		})
+	}
+	for _, u := range solution.Unplaced {
+		res.Unplaced = append(res.Unplaced, unplacedResponse{
+			GroupID:   u.Requirement.GroupID,
+			SubjectID: u.Requirement.SubjectID,
+			Missing:   u.Missing,
+		})
+	}
+
+	ctx.JSON(http.StatusOK, res)
+}
+
+// sectionsWeek returns the timetable grid shared by sections. The generator
+// places every lesson in one list of slots, so sections on grids with
+// different days or periods have to be generated separately.
+func (server *Server) sectionsWeek(ctx *gin.Context, sections []db.StudentSection) (timeslot.Week, int, error) {
+	var week timeslot.Week
+	for i, section := range sections {
+		w, err := validation.GridFor(ctx, server.store, int64(section.ID))
+		if err != nil {
+			return timeslot.Week{}, http.StatusInternalServerError, err
+		}
+		if i > 0 && !reflect.DeepEqual(w, week) {
+			return timeslot.Week{}, http.StatusBadRequest, fmt.Errorf("the selected sections use different timetable grids, generate them separately")
+		}
+		week = w
+	}
+	return week, http.StatusOK, nil
+}
+
+func selectSections(sections []db.StudentSection, req optimizeRoutineRequest, scheduleYear int32) []db.StudentSection {
+	wanted := map[int64]bool{}
+	for _, id := range req.GroupIDs {
+		wanted[id] = true
+	}
+
+	var selected []db.StudentSection
+	for _, section := range sections {
+		if len(wanted) > 0 {
+			if wanted[int64(section.ID)] {
+				selected = append(selected, section)
+			}
+			continue
+		}
+		if req.Department != "" && section.Department.String != req.Department {
+			continue
+		}
+		if req.Program != "" && section.Program.String != req.Program {
+			continue
+		}
+		if req.Year != 0 && (!section.YearEnrolled.Valid || scheduleYear-section.YearEnrolled.Int32+1 != req.Year) {
+			continue
+		}
+		selected = append(selected, section)
+	}
+	return selected
+}
+
+// buildRequirements uses the explicit requirements of the request when
+// given. Otherwise every subject of a section's department that has an
+// assigned teacher is requested PeriodsPerWeek times.
+func buildRequirements(sections []db.StudentSection, req optimizeRoutineRequest, subjectTeachers map[int64][]string, subjectDepartment map[int64]string) ([]scheduler.Requirement, error) {
+	departments := map[int64]string{}
+	for _, section := range sections {
+		departments[int64(section.ID)] = section.Department.String
+	}
+
+	var requirements []scheduler.Requirement
+	if len(req.Requirements) > 0 {
+		for _, r := range req.Requirements {
+			if _, ok := departments[r.GroupID]; !ok {
+				return nil, fmt.Errorf("student section %d is not part of this routine", r.GroupID)
+			}
+			teachers := subjectTeachers[r.SubjectID]
+			if r.TeacherEmail != "" {
+				teachers = []string{r.TeacherEmail}
+			}
+			if len(teachers) == 0 {
+				return nil, fmt.Errorf("subject %d has no assigned teachers", r.SubjectID)
+			}
+			requirements = append(requirements, scheduler.Requirement{
+				GroupID:    r.GroupID,
+				Department: departments[r.GroupID],
+				SubjectID:  r.SubjectID,
+				Periods:    r.PeriodsPerWeek,
+				Teachers:   teachers,
+			})
+		}
+		return requirements, nil
+	}
+
+	periods := req.PeriodsPerWeek
+	if periods <= 0 {
+		periods = 1
+	}
+	for _, section := range sections {
+		for subjectID, teachers := range subjectTeachers {
+			if subjectDepartment[subjectID] != section.Department.String {
+				continue
+			}
+			requirements = append(requirements, scheduler.Requirement{
+				GroupID:    int64(section.ID),
+				Department: section.Department.String,
+				SubjectID:  subjectID,
+				Periods:    periods,
+				Teachers:   teachers,
+			})
+		}
+	}
+	// map iteration order is random, keep the proposal deterministic
+	sort.Slice(requirements, func(i, j int) bool {
+		if requirements[i].GroupID != requirements[j].GroupID {
+			return requirements[i].GroupID < requirements[j].GroupID
+		}
+		return requirements[i].SubjectID < requirements[j].SubjectID
+	})
+	return requirements, nil
+}
+
+func (server *Server) applyRoutine(ctx *gin.Context) {
+	var req applyRoutineRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	adminStatus, err := checkAdmin(ctx, "admin")
+	if err != nil {
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return
+	}
+	if !adminStatus {
+		ctx.JSON(http.StatusForbidden, errorResponse(fmt.Errorf("not authorized to create schedules")))
+		return
+	}
+	if req.Term == 0 {
+		req.Term = 1
+	}
+	if status, err := server.checkTerm(ctx, req.ScheduleYear, req.Term); err != nil {
+		ctx.JSON(status, errorResponse(err))
+		return
+	}
+
+	weeks := make(map[int64]timeslot.Week)
+	args := make([]db.CreateScheduleParams, 0, len(req.Entries))
+	for _, entry := range req.Entries {
+		week, ok := weeks[entry.GroupID]
+		if !ok {
+			if week, err = validation.GridFor(ctx, server.store, entry.GroupID); err != nil {
+				ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+				return
+			}
+			weeks[entry.GroupID] = week
+		}
+		slot, err := timeslot.Parse(entry.TimeSlot)
+		if err == nil {
+			err = week.Check(slot)
+		}
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, errorResponse(err))
+			return
+		}
+		args = append(args, db.CreateScheduleParams{
+			GroupID:      sql.NullInt64{Int64: entry.GroupID, Valid: true},
+			RoomID:       sql.NullInt64{Int64: entry.RoomID, Valid: true},
+			SubjectID:    sql.NullInt64{Int64: entry.SubjectID, Valid: true},
+			TeacherEmail: StringToSQLNullString(entry.TeacherEmail),
+			TimeSlot:     StringToSQLNullString(slot.String()),
+			Year:         req.ScheduleYear,
+			Term:         req.Term,
+			DayOfWeek:    int16(slot.Day),
+			StartMinute:  slot.Start,
+			EndMinute:    slot.End,
+			SessionType:  sessionType(entry.SessionType),
+		})
+	}
+
+	var schedules []db.Schedule
+	err = server.store.ExecTx(ctx, func(q *db.Queries) error {
+		var err error
+		schedules, err = validation.CreateSchedules(ctx, q, args)
+		return err
+	})
+	if err != nil {
+		var conflictErr *validation.ConflictError
+		if errors.As(err, &conflictErr) {
+			ctx.JSON(http.StatusConflict, conflictResponse(conflictErr))
+			return
+		}
+		if validation.IsExclusionViolation(err) {
+			ctx.JSON(http.StatusConflict, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	res := make([]scheduleResponse, 0, len(schedules))
+	for _, schedule := range schedules {
+		res = append(res, newScheduleResponse(schedule, weeks[schedule.GroupID.Int64]))
+	}
+	ctx.JSON(http.StatusOK, res)
+}
@@ -0,0 +1,303 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/nirajan1111/routiney/db/sqlc"
+	"github.com/nirajan1111/routiney/validation"
+)
+
+type routineYearRequest struct {
+	Year int32 `uri:"year" binding:"required,min=2000"`
+}
+
+type publishRoutineRequest struct {
+	Note string `json:"note"`
+}
+
+type rollbackRoutineRequest struct {
+	Version int32 `json:"version" binding:"required,min=1"`
+	// RestoreDraft also resets the term's draft to the restored version,
+	// discarding unpublished edits.
+	RestoreDraft bool `json:"restore_draft"`
+}
+
+type routineVersionResponse struct {
+	ID            int64     `json:"id"`
+	Year          int32     `json:"year"`
+	Term          int16     `json:"term"`
+	Version       int32     `json:"version"`
+	Note          string    `json:"note,omitempty"`
+	PublishedBy   string    `json:"published_by,omitempty"`
+	PublishedAt   time.Time `json:"published_at"`
+	Active        bool      `json:"active"`
+	ScheduleCount int64     `json:"schedule_count"`
+}
+
+func newRoutineVersionResponse(v db.RoutineVersion) routineVersionResponse {
+	return routineVersionResponse{
+		ID:          v.ID,
+		Year:        v.Year,
+		Term:        v.Term,
+		Version:     v.Version,
+		Note:        v.Note.String,
+		PublishedBy: v.PublishedBy.String,
+		PublishedAt: v.PublishedAt,
+		Active:      v.Active,
+	}
+}
+
+type draftValidationResponse struct {
+	Year      int32                `json:"year"`
+	Term      int16                `json:"term"`
+	Schedules int                  `json:"schedules"`
+	Valid     bool                 `json:"valid"`
+	Problems  []validation.Problem `json:"problems"`
+}
+
+// errDraftInvalid aborts a publish whose draft does not validate.
+var errDraftInvalid = errors.New("draft routine has conflicts, fix them before publishing")
+
+func (server *Server) listRoutineVersions(ctx *gin.Context) {
+	var uri routineYearRequest
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	term, err := server.termFromQuery(ctx, uri.Year, time.Time{})
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	versions, err := server.store.ListRoutineVersions(ctx, db.ListRoutineVersionsParams{Year: uri.Year, Term: term})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	res := make([]routineVersionResponse, 0, len(versions))
+	for _, v := range versions {
+		r := newRoutineVersionResponse(db.RoutineVersion{
+			ID:          v.ID,
+			Year:        v.Year,
+			Term:        v.Term,
+			Version:     v.Version,
+			Note:        v.Note,
+			PublishedBy: v.PublishedBy,
+			PublishedAt: v.PublishedAt,
+			Active:      v.Active,
+		})
+		r.ScheduleCount = v.ScheduleCount
+		res = append(res, r)
+	}
+	ctx.JSON(http.StatusOK, res)
+}
+
+// validateDraft checks every draft schedule of a term without publishing.
+func (server *Server) validateDraft(ctx *gin.Context) {
+	var uri routineYearRequest
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	term, err := server.termFromQuery(ctx, uri.Year, time.Time{})
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	adminStatus, err := checkAdmin(ctx, "admin")
+	if err != nil {
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return
+	}
+	if !adminStatus {
+		ctx.JSON(http.StatusForbidden, errorResponse(fmt.Errorf("not authorized to validate routines")))
+		return
+	}
+
+	schedules, err := server.store.ListSchedulesByTerm(ctx, db.ListSchedulesByTermParams{Year: uri.Year, Term: term})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	problems, err := validation.New(server.store).ValidateAll(ctx, schedules)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	ctx.JSON(http.StatusOK, draftValidationResponse{
+		Year:      uri.Year,
+		Term:      term,
+		Schedules: len(schedules),
+		Valid:     len(problems) == 0,
+		Problems:  problems,
+	})
+}
+
+// publishRoutine validates a term's draft and, if it is clean, snapshots it
+// as a new version and makes that version live, all in one transaction.
+// Emails about the classes that changed since the last version are queued
+// in the same transaction.
+func (server *Server) publishRoutine(ctx *gin.Context) {
+	var uri routineYearRequest
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	term, err := server.termFromQuery(ctx, uri.Year, time.Time{})
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	var req publishRoutineRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	payload, err := payloadFromContext(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return
+	}
+	if payload.Role != "admin" {
+		ctx.JSON(http.StatusForbidden, errorResponse(fmt.Errorf("not authorized to publish routines")))
+		return
+	}
+
+	var version db.RoutineVersion
+	var problems []validation.Problem
+	err = server.store.ExecTx(ctx, func(q *db.Queries) error {
+		schedules, err := q.ListSchedulesByTerm(ctx, db.ListSchedulesByTermParams{Year: uri.Year, Term: term})
+		if err != nil {
+			return err
+		}
+		problems, err = validation.New(q).ValidateAll(ctx, schedules)
+		if err != nil {
+			return err
+		}
+		if len(problems) > 0 {
+			return errDraftInvalid
+		}
+
+		version, err = q.CreateRoutineVersion(ctx, db.CreateRoutineVersionParams{
+			Year:        uri.Year,
+			Term:        term,
+			Note:        StringToSQLNullString(req.Note),
+			PublishedBy: StringToSQLNullString(payload.Email),
+		})
+		if err != nil {
+			return err
+		}
+		if _, err := q.SnapshotSchedules(ctx, db.SnapshotSchedulesParams{VersionID: version.ID, Year: uri.Year, Term: term}); err != nil {
+			return err
+		}
+		previous, err := activeVersion(ctx, q, uri.Year, term)
+		if err != nil {
+			return err
+		}
+		if err := q.DeactivateRoutineVersions(ctx, db.DeactivateRoutineVersionsParams{Year: uri.Year, Term: term}); err != nil {
+			return err
+		}
+		version, err = q.ActivateRoutineVersion(ctx, version.ID)
+		if err != nil {
+			return err
+		}
+		return server.notifyPublished(ctx, q, previous, version)
+	})
+	if err != nil {
+		if errors.Is(err, errDraftInvalid) {
+			ctx.JSON(http.StatusConflict, gin.H{"error": err.Error(), "problems": problems})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newRoutineVersionResponse(version))
+}
+
+// rollbackRoutine makes an earlier published version live again.
+func (server *Server) rollbackRoutine(ctx *gin.Context) {
+	var uri routineYearRequest
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	term, err := server.termFromQuery(ctx, uri.Year, time.Time{})
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	var req rollbackRoutineRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	adminStatus, err := checkAdmin(ctx, "admin")
+	if err != nil {
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return
+	}
+	if !adminStatus {
+		ctx.JSON(http.StatusForbidden, errorResponse(fmt.Errorf("not authorized to roll back routines")))
+		return
+	}
+	// restored schedules are written to the term like any others
+	if req.RestoreDraft {
+		if status, err := server.checkTerm(ctx, uri.Year, term); err != nil {
+			ctx.JSON(status, errorResponse(err))
+			return
+		}
+	}
+
+	var version db.RoutineVersion
+	err = server.store.ExecTx(ctx, func(q *db.Queries) error {
+		target, err := q.GetRoutineVersion(ctx, db.GetRoutineVersionParams{Year: uri.Year, Term: term, Version: req.Version})
+		if err != nil {
+			return err
+		}
+		previous, err := activeVersion(ctx, q, uri.Year, term)
+		if err != nil {
+			return err
+		}
+		if err := q.DeactivateRoutineVersions(ctx, db.DeactivateRoutineVersionsParams{Year: uri.Year, Term: term}); err != nil {
+			return err
+		}
+		version, err = q.ActivateRoutineVersion(ctx, target.ID)
+		if err != nil {
+			return err
+		}
+		if err := server.notifyPublished(ctx, q, previous, version); err != nil {
+			return err
+		}
+
+		if req.RestoreDraft {
+			if err := q.DeleteSchedulesByTerm(ctx, db.DeleteSchedulesByTermParams{Year: uri.Year, Term: term}); err != nil {
+				return err
+			}
+			if _, err := q.RestoreSchedulesFromVersion(ctx, target.ID); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(http.StatusNotFound, errorResponse(fmt.Errorf("version %d of term %d of %d not found", req.Version, term, uri.Year)))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newRoutineVersionResponse(version))
+}
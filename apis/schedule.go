@@ -4,11 +4,16 @@ import (
 	"database/sql"
 	"fmt"
 	"net/http"
+	"sort"
 	"strconv"
-	"time"
+	"strings"
 
 	"github.com/gin-gonic/gin"
+	"github.com/nirajan1111/routiney/bs"
 	db "github.com/nirajan1111/routiney/db/sqlc"
+	"github.com/nirajan1111/routiney/timeslot"
+	"github.com/nirajan1111/routiney/token"
+	"github.com/nirajan1111/routiney/validation"
 )
 
 // Request/Response Types
@@ -19,6 +24,17 @@ type createScheduleRequest struct {
 	TeacherEmail string `json:"teacher_email" binding:"required,email"`
 	TimeSlot     string `json:"time_slot" binding:"required"`
 	Year         int32  `json:"year" binding:"required"`
+	// Term is the term of Year the class is in, the first by default.
+	Term        int16  `json:"term" binding:"omitempty,min=1"`
+	SessionType string `json:"session_type" binding:"omitempty,oneof=lecture lab tutorial"`
+	// SubGroupID limits the session to a sub-group of the section.
+	SubGroupID int64 `json:"sub_group_id" binding:"omitempty,min=1"`
+	// CombinedGroupIDs are other sections attending the session together
+	// with GroupID, making it a combined class.
+	CombinedGroupIDs []int64 `json:"combined_group_ids" binding:"omitempty,dive,min=1"`
+	// Periods makes the session take that many consecutive periods,
+	// starting with the one TimeSlot begins at, e.g. 3 for a long lab.
+	Periods int `json:"periods" binding:"omitempty,min=1,max=12"`
 }
 
 type scheduleResponse struct {
@@ -29,23 +45,49 @@ type scheduleResponse struct {
 	TeacherEmail string `json:"teacher_email,omitempty"`
 	TimeSlot     string `json:"time_slot,omitempty"`
 	Year         int32  `json:"year,omitempty"`
+	Term         int16  `json:"term,omitempty"`
+	SessionType  string `json:"session_type,omitempty"`
+	SubGroupID   int64  `json:"sub_group_id,omitempty"`
+	// CombinedGroupIDs are the other sections of a combined class.
+	CombinedGroupIDs []int64 `json:"combined_group_ids,omitempty"`
+	// Period is the name of the first grid period the session takes and
+	// Periods how many it spans, so grids can merge its cells.
+	Period  string `json:"period,omitempty"`
+	Periods int    `json:"periods,omitempty"`
+	// Warnings are soft problems found while validating a write, such as
+	// a slot the teacher dislikes.
+	Warnings []validation.Warning `json:"warnings,omitempty"`
 }
 
 type detailedScheduleResponse struct {
-	ID                 int64  `json:"id"`
-	GroupID            int64  `json:"group_id,omitempty"`
-	RoomID             int64  `json:"room_id,omitempty"`
-	SubjectID          int64  `json:"subject_id,omitempty"`
-	TeacherEmail       string `json:"teacher_email,omitempty"`
-	TimeSlot           string `json:"time_slot,omitempty"`
-	Year               int32  `json:"year,omitempty"`
-	TeacherName        string `json:"teacher_name,omitempty"`
-	RoomCode           string `json:"room_code,omitempty"`
-	BlockNo            string `json:"block_no,omitempty"`
-	SubjectCode        string `json:"subject_code,omitempty"`
-	SubjectName        string `json:"subject_name,omitempty"`
-	GroupName          string `json:"group_name,omitempty"`
-	TeacherDesignation string `json:"teacher_designation,omitempty"`
+	ID           int64  `json:"id"`
+	GroupID      int64  `json:"group_id,omitempty"`
+	RoomID       int64  `json:"room_id,omitempty"`
+	SubjectID    int64  `json:"subject_id,omitempty"`
+	TeacherEmail string `json:"teacher_email,omitempty"`
+	TimeSlot     string `json:"time_slot,omitempty"`
+	Year         int32  `json:"year,omitempty"`
+	Term         int16  `json:"term,omitempty"`
+	SessionType  string `json:"session_type,omitempty"`
+	TeacherName  string `json:"teacher_name,omitempty"`
+	RoomCode     string `json:"room_code,omitempty"`
+	BlockNo      string `json:"block_no,omitempty"`
+	SubjectCode  string `json:"subject_code,omitempty"`
+	SubjectName  string `json:"subject_name,omitempty"`
+	GroupName    string `json:"group_name,omitempty"`
+	SubGroupID   int64  `json:"sub_group_id,omitempty"`
+	SubGroupName string `json:"sub_group_name,omitempty"`
+	// CombinedGroupIDs and CombinedGroupNames are the other sections of a
+	// combined class.
+	CombinedGroupIDs   []int64  `json:"combined_group_ids,omitempty"`
+	CombinedGroupNames []string `json:"combined_group_names,omitempty"`
+	TeacherDesignation string   `json:"teacher_designation,omitempty"`
+	Period             string   `json:"period,omitempty"`
+	Periods            int      `json:"periods,omitempty"`
+	// Cover is set on dated routines when the session is covered or cancelled.
+	Cover *coverResponse `json:"cover,omitempty"`
+	// Booking is set for one-off room bookings listed in dated room views.
+	Booking *roomBookingResponse `json:"booking,omitempty"`
 }
 
 type getScheduleRequest struct {
@@ -64,25 +106,138 @@ type updateScheduleRequest struct {
 	TeacherEmail string `json:"teacher_email"`
 	TimeSlot     string `json:"time_slot"`
 	Year         int32  `json:"year"`
+	// Term moves the session to another term; 0 keeps the current one.
+	Term        int16  `json:"term" binding:"omitempty,min=1"`
+	SessionType string `json:"session_type" binding:"omitempty,oneof=lecture lab tutorial"`
+	// SubGroupID moves the session to a sub-group of the section, or back
+	// to the whole section with 0.
+	SubGroupID *int64 `json:"sub_group_id" binding:"omitempty,min=0"`
+	// CombinedGroupIDs replaces the other sections of a combined class; an
+	// empty list makes it an ordinary class again.
+	CombinedGroupIDs *[]int64 `json:"combined_group_ids"`
+	// Periods re-spans the session over that many periods from its start.
+	// A session moved without it keeps its length in periods.
+	Periods int `json:"periods" binding:"omitempty,min=1,max=12"`
 }
 
-type checkConflictRequest struct {
-	TimeSlot     string `json:"time_slot" binding:"required"`
-	RoomID       int64  `json:"room_id" binding:"required"`
-	TeacherEmail string `json:"teacher_email" binding:"required,email"`
-	GroupID      int64  `json:"group_id" binding:"required"`
+// sessionType reads an optional session_type, lecture when empty.
+func sessionType(s string) db.SessionType {
+	if s == "" {
+		return db.SessionTypeLecture
+	}
+	return db.SessionType(s)
+}
+
+// checkSubGroup makes sure subGroupID, when set, is a sub-group of the
+// section the schedule is for.
+func (server *Server) checkSubGroup(ctx *gin.Context, groupID, subGroupID int64) (int, error) {
+	if subGroupID == 0 {
+		return http.StatusOK, nil
+	}
+	subGroup, err := server.store.GetSubGroup(ctx, subGroupID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return http.StatusNotFound, fmt.Errorf("sub-group not found")
+		}
+		return http.StatusInternalServerError, err
+	}
+	if int64(subGroup.GroupID) != groupID {
+		return http.StatusBadRequest, fmt.Errorf("sub-group %s is not part of section %d", subGroup.Name, groupID)
+	}
+	return http.StatusOK, nil
+}
+
+// checkCombinedGroups makes sure the sections joining a combined class
+// exist and are not the section it is booked for, and returns them without
+// duplicates. Sub-groups cannot be combined.
+func (server *Server) checkCombinedGroups(ctx *gin.Context, groupID, subGroupID int64, ids []int64) ([]int64, int, error) {
+	if len(ids) == 0 {
+		return nil, http.StatusOK, nil
+	}
+	if subGroupID != 0 {
+		return nil, http.StatusBadRequest, fmt.Errorf("a sub-group class cannot be combined with other sections")
+	}
+	seen := map[int64]bool{groupID: true}
+	combined := make([]int64, 0, len(ids))
+	for _, id := range ids {
+		if id == groupID {
+			return nil, http.StatusBadRequest, fmt.Errorf("section %d is already the one the class is for", id)
+		}
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		if _, err := server.store.GetStudentSection(ctx, int32(id)); err != nil {
+			if err == sql.ErrNoRows {
+				return nil, http.StatusNotFound, fmt.Errorf("student section %d not found", id)
+			}
+			return nil, http.StatusInternalServerError, err
+		}
+		combined = append(combined, id)
+	}
+	sort.Slice(combined, func(i, j int) bool { return combined[i] < combined[j] })
+	return combined, http.StatusOK, nil
+}
+
+// periodSpan returns the first teaching period of week a time slot takes
+// and how many periods it spans, or "" and 0 when it lies outside the grid.
+func periodSpan(week timeslot.Week, timeSlot string) (string, int) {
+	slot, err := timeslot.Parse(timeSlot)
+	if err != nil {
+		return "", 0
+	}
+	periods := week.Teaching()
+	first, n := timeslot.Covers(periods, slot)
+	if first < 0 {
+		return "", 0
+	}
+	return periods[first].Name, n
+}
+
+// withPeriodSpans fills in the period span of every schedule, against the
+// grid of its section.
+func (server *Server) withPeriodSpans(ctx *gin.Context, schedules []detailedScheduleResponse) ([]detailedScheduleResponse, error) {
+	weeks := make(map[int64]timeslot.Week)
+	for i := range schedules {
+		week, ok := weeks[schedules[i].GroupID]
+		if !ok {
+			var err error
+			if week, err = validation.GridFor(ctx, server.store, schedules[i].GroupID); err != nil {
+				return nil, err
+			}
+			weeks[schedules[i].GroupID] = week
+		}
+		schedules[i].Period, schedules[i].Periods = periodSpan(week, schedules[i].TimeSlot)
+	}
+	return schedules, nil
+}
+
+// conflictResponse is the 409 body listing each clashing schedule and the
+// dimension (room, teacher or group) it clashes on.
+func conflictResponse(err *validation.ConflictError) gin.H {
+	return gin.H{
+		"error":     err.Error(),
+		"conflicts": err.Conflicts,
+	}
 }
 
 // Helper function to convert DB schedule to API response
-func newScheduleResponse(schedule db.Schedule) scheduleResponse {
+func newScheduleResponse(schedule db.Schedule, week timeslot.Week) scheduleResponse {
+	period, periods := periodSpan(week, schedule.TimeSlot.String)
 	return scheduleResponse{
-		ID:           schedule.ID,
-		GroupID:      schedule.GroupID.Int64,
-		RoomID:       schedule.RoomID.Int64,
-		SubjectID:    schedule.SubjectID.Int64,
-		TeacherEmail: schedule.TeacherEmail.String,
-		TimeSlot:     schedule.TimeSlot.String,
-		Year:         schedule.Year,
+		Period:           period,
+		Periods:          periods,
+		ID:               schedule.ID,
+		GroupID:          schedule.GroupID.Int64,
+		RoomID:           schedule.RoomID.Int64,
+		SubjectID:        schedule.SubjectID.Int64,
+		TeacherEmail:     schedule.TeacherEmail.String,
+		TimeSlot:         schedule.TimeSlot.String,
+		Year:             schedule.Year,
+		Term:             schedule.Term,
+		SessionType:      string(schedule.SessionType),
+		SubGroupID:       schedule.SubGroupID.Int64,
+		CombinedGroupIDs: schedule.CombinedGroupIds,
 	}
 }
 
@@ -95,12 +250,18 @@ func newDetailedScheduleByTeacherResponse(schedule db.GetSchedulesByTeacherRow)
 		TeacherEmail:       schedule.TeacherEmail.String,
 		TimeSlot:           schedule.TimeSlot.String,
 		Year:               schedule.Year,
+		Term:               schedule.Term,
+		SessionType:        string(schedule.SessionType),
 		TeacherName:        schedule.TeacherName.String,
 		RoomCode:           schedule.RoomCode.String,
 		BlockNo:            schedule.BlockNo.String,
 		SubjectCode:        schedule.SubjectCode.String,
 		SubjectName:        schedule.SubjectName.String,
 		GroupName:          schedule.GroupName.String,
+		SubGroupID:         schedule.SubGroupID.Int64,
+		SubGroupName:       schedule.SubGroupName.String,
+		CombinedGroupIDs:   schedule.CombinedGroupIds,
+		CombinedGroupNames: schedule.CombinedGroupNames,
 		TeacherDesignation: schedule.TeacherDesignation.String,
 	}
 }
@@ -115,11 +276,17 @@ func newDetailedScheduleByRoomResponse(schedule db.GetSchedulesByRoomRow) detail
 		TimeSlot:           schedule.TimeSlot.String,
 		TeacherName:        schedule.TeacherName.String,
 		Year:               schedule.Year,
+		Term:               schedule.Term,
+		SessionType:        string(schedule.SessionType),
 		RoomCode:           schedule.RoomCode.String,
 		BlockNo:            schedule.BlockNo.String,
 		SubjectCode:        schedule.SubjectCode.String,
 		SubjectName:        schedule.SubjectName.String,
 		GroupName:          schedule.GroupName.String,
+		SubGroupID:         schedule.SubGroupID.Int64,
+		SubGroupName:       schedule.SubGroupName.String,
+		CombinedGroupIDs:   schedule.CombinedGroupIds,
+		CombinedGroupNames: schedule.CombinedGroupNames,
 		TeacherDesignation: schedule.TeacherDesignation.String,
 	}
 }
@@ -134,24 +301,228 @@ func newDetailedScheduleByGroupResponse(schedule db.GetSchedulesByGroupRow) deta
 		TimeSlot:           schedule.TimeSlot.String,
 		TeacherName:        schedule.TeacherName.String,
 		Year:               schedule.Year,
+		Term:               schedule.Term,
+		SessionType:        string(schedule.SessionType),
+		RoomCode:           schedule.RoomCode.String,
+		BlockNo:            schedule.BlockNo.String,
+		SubjectCode:        schedule.SubjectCode.String,
+		SubjectName:        schedule.SubjectName.String,
+		GroupName:          schedule.GroupName.String,
+		SubGroupID:         schedule.SubGroupID.Int64,
+		SubGroupName:       schedule.SubGroupName.String,
+		CombinedGroupIDs:   schedule.CombinedGroupIds,
+		CombinedGroupNames: schedule.CombinedGroupNames,
+		TeacherDesignation: schedule.TeacherDesignation.String,
+	}
+}
+
+func newDetailedPublishedScheduleByTeacherResponse(schedule db.GetPublishedSchedulesByTeacherRow) detailedScheduleResponse {
+	return detailedScheduleResponse{
+		ID:                 schedule.ID,
+		GroupID:            schedule.GroupID.Int64,
+		RoomID:             schedule.RoomID.Int64,
+		SubjectID:          schedule.SubjectID.Int64,
+		TeacherEmail:       schedule.TeacherEmail.String,
+		TimeSlot:           schedule.TimeSlot.String,
+		Year:               schedule.Year,
+		Term:               schedule.Term,
+		SessionType:        string(schedule.SessionType),
+		TeacherName:        schedule.TeacherName.String,
+		RoomCode:           schedule.RoomCode.String,
+		BlockNo:            schedule.BlockNo.String,
+		SubjectCode:        schedule.SubjectCode.String,
+		SubjectName:        schedule.SubjectName.String,
+		GroupName:          schedule.GroupName.String,
+		SubGroupID:         schedule.SubGroupID.Int64,
+		SubGroupName:       schedule.SubGroupName.String,
+		CombinedGroupIDs:   schedule.CombinedGroupIds,
+		CombinedGroupNames: schedule.CombinedGroupNames,
+		TeacherDesignation: schedule.TeacherDesignation.String,
+	}
+}
+
+func newDetailedPublishedScheduleByRoomResponse(schedule db.GetPublishedSchedulesByRoomRow) detailedScheduleResponse {
+	return detailedScheduleResponse{
+		ID:                 schedule.ID,
+		GroupID:            schedule.GroupID.Int64,
+		RoomID:             schedule.RoomID.Int64,
+		SubjectID:          schedule.SubjectID.Int64,
+		TeacherEmail:       schedule.TeacherEmail.String,
+		TimeSlot:           schedule.TimeSlot.String,
+		Year:               schedule.Year,
+		Term:               schedule.Term,
+		SessionType:        string(schedule.SessionType),
+		TeacherName:        schedule.TeacherName.String,
+		RoomCode:           schedule.RoomCode.String,
+		BlockNo:            schedule.BlockNo.String,
+		SubjectCode:        schedule.SubjectCode.String,
+		SubjectName:        schedule.SubjectName.String,
+		GroupName:          schedule.GroupName.String,
+		SubGroupID:         schedule.SubGroupID.Int64,
+		SubGroupName:       schedule.SubGroupName.String,
+		CombinedGroupIDs:   schedule.CombinedGroupIds,
+		CombinedGroupNames: schedule.CombinedGroupNames,
+		TeacherDesignation: schedule.TeacherDesignation.String,
+	}
+}
+
+func newDetailedPublishedScheduleByGroupResponse(schedule db.GetPublishedSchedulesByGroupRow) detailedScheduleResponse {
+	return detailedScheduleResponse{
+		ID:                 schedule.ID,
+		GroupID:            schedule.GroupID.Int64,
+		RoomID:             schedule.RoomID.Int64,
+		SubjectID:          schedule.SubjectID.Int64,
+		TeacherEmail:       schedule.TeacherEmail.String,
+		TimeSlot:           schedule.TimeSlot.String,
+		Year:               schedule.Year,
+		Term:               schedule.Term,
+		SessionType:        string(schedule.SessionType),
+		TeacherName:        schedule.TeacherName.String,
 		RoomCode:           schedule.RoomCode.String,
 		BlockNo:            schedule.BlockNo.String,
 		SubjectCode:        schedule.SubjectCode.String,
 		SubjectName:        schedule.SubjectName.String,
 		GroupName:          schedule.GroupName.String,
+		SubGroupID:         schedule.SubGroupID.Int64,
+		SubGroupName:       schedule.SubGroupName.String,
+		CombinedGroupIDs:   schedule.CombinedGroupIds,
+		CombinedGroupNames: schedule.CombinedGroupNames,
 		TeacherDesignation: schedule.TeacherDesignation.String,
 	}
 }
 
-func getNepaliYear() int {
-	currentYear := time.Now().Year()
-	currentMonth := time.Now().Month()
-	currentDay := time.Now().Day()
+// teacherSchedules, roomSchedules and groupSchedules read a term's routine
+// for display: the published version, or the working draft when draft is set.
+func (server *Server) teacherSchedules(ctx *gin.Context, email string, year int32, term int16, draft bool) ([]detailedScheduleResponse, error) {
+	schedules := make([]detailedScheduleResponse, 0)
+	if draft {
+		rows, err := server.store.GetSchedulesByTeacher(ctx, db.GetSchedulesByTeacherParams{
+			TeacherEmail: StringToSQLNullString(email),
+			Year:         year,
+			Term:         term,
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, row := range rows {
+			schedules = append(schedules, newDetailedScheduleByTeacherResponse(row))
+		}
+		return server.withPeriodSpans(ctx, schedules)
+	}
+
+	rows, err := server.store.GetPublishedSchedulesByTeacher(ctx, db.GetPublishedSchedulesByTeacherParams{
+		TeacherEmail: StringToSQLNullString(email),
+		Year:         year,
+		Term:         term,
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		schedules = append(schedules, newDetailedPublishedScheduleByTeacherResponse(row))
+	}
+	return server.withPeriodSpans(ctx, schedules)
+}
+
+func (server *Server) roomSchedules(ctx *gin.Context, roomID int64, year int32, term int16, draft bool) ([]detailedScheduleResponse, error) {
+	schedules := make([]detailedScheduleResponse, 0)
+	if draft {
+		rows, err := server.store.GetSchedulesByRoom(ctx, db.GetSchedulesByRoomParams{
+			RoomID: sql.NullInt64{Int64: roomID, Valid: true},
+			Year:   year,
+			Term:   term,
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, row := range rows {
+			schedules = append(schedules, newDetailedScheduleByRoomResponse(row))
+		}
+		return server.withPeriodSpans(ctx, schedules)
+	}
+
+	rows, err := server.store.GetPublishedSchedulesByRoom(ctx, db.GetPublishedSchedulesByRoomParams{
+		RoomID: sql.NullInt64{Int64: roomID, Valid: true},
+		Year:   year,
+		Term:   term,
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		schedules = append(schedules, newDetailedPublishedScheduleByRoomResponse(row))
+	}
+	return server.withPeriodSpans(ctx, schedules)
+}
+
+func (server *Server) groupSchedules(ctx *gin.Context, groupID int64, year int32, term int16, draft bool) ([]detailedScheduleResponse, error) {
+	schedules := make([]detailedScheduleResponse, 0)
+	if draft {
+		rows, err := server.store.GetSchedulesByGroup(ctx, db.GetSchedulesByGroupParams{
+			GroupID: sql.NullInt64{Int64: groupID, Valid: true},
+			Year:    year,
+			Term:    term,
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, row := range rows {
+			schedules = append(schedules, newDetailedScheduleByGroupResponse(row))
+		}
+		return server.withPeriodSpans(ctx, schedules)
+	}
 
-	if currentMonth < 4 || (currentMonth == 4 && currentDay < 14) {
-		return currentYear + 56
+	rows, err := server.store.GetPublishedSchedulesByGroup(ctx, db.GetPublishedSchedulesByGroupParams{
+		GroupID: sql.NullInt64{Int64: groupID, Valid: true},
+		Year:    year,
+		Term:    term,
+	})
+	if err != nil {
+		return nil, err
 	}
-	return currentYear + 57
+	for _, row := range rows {
+		schedules = append(schedules, newDetailedPublishedScheduleByGroupResponse(row))
+	}
+	return server.withPeriodSpans(ctx, schedules)
+}
+
+// wantsDraft reports whether the request asks for the draft routine with
+// ?draft=true. Drafts are for admins only; the public routes have no auth
+// middleware, so the bearer token is checked here.
+func (server *Server) wantsDraft(ctx *gin.Context) (bool, error) {
+	if ctx.Query("draft") != "true" {
+		return false, nil
+	}
+	payload, ok := ctx.Value("user").(*token.Payload)
+	if !ok {
+		authHeader := ctx.GetHeader("Authorization")
+		if authHeader == "" {
+			return false, fmt.Errorf("drafts are only visible to admins")
+		}
+		var err error
+		payload, err = server.tokenMaker.VerifyToken(strings.TrimPrefix(authHeader, "Bearer "))
+		if err != nil {
+			return false, err
+		}
+	}
+	if payload.Role != "admin" {
+		return false, fmt.Errorf("drafts are only visible to admins")
+	}
+	return true, nil
+}
+
+// yearFromQuery reads the ?year= query parameter, defaulting to the current
+// Nepali year.
+func yearFromQuery(ctx *gin.Context) (int32, error) {
+	yearStr := ctx.Query("year")
+	if yearStr == "" {
+		return int32(bs.CurrentYear()), nil
+	}
+	year, err := strconv.Atoi(yearStr)
+	if err != nil {
+		return 0, fmt.Errorf("invalid year")
+	}
+	return int32(year), nil
 }
 
 func (server *Server) createSchedule(ctx *gin.Context) {
@@ -171,30 +542,34 @@ func (server *Server) createSchedule(ctx *gin.Context) {
 		return
 	}
 	if req.Year == 0 {
-		req.Year = int32(getNepaliYear())
+		req.Year = int32(bs.CurrentYear())
 	}
-
-	conflictParams := db.CheckScheduleConflictsParams{
-		TimeSlot: StringToSQLNullString(req.TimeSlot),
-		RoomID: sql.NullInt64{
-			Int64: req.RoomID,
-			Valid: true,
-		},
-		TeacherEmail: StringToSQLNullString(req.TeacherEmail),
-		GroupID: sql.NullInt64{
-			Int64: req.GroupID,
-			Valid: true,
-		},
+	if req.Term == 0 {
+		req.Term = 1
+	}
+	if status, err := server.checkTerm(ctx, req.Year, req.Term); err != nil {
+		ctx.JSON(status, errorResponse(err))
+		return
 	}
 
-	hasConflict, err := server.store.CheckScheduleConflicts(ctx, conflictParams)
+	// The slot must exist in the grid of the section's program
+	week, err := validation.GridFor(ctx, server.store, req.GroupID)
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
 		return
 	}
-
-	if hasConflict {
-		ctx.JSON(http.StatusConflict, errorResponse(fmt.Errorf("schedule conflict detected: room, teacher, or group already scheduled for this time slot")))
+	slot, err := timeslot.Parse(req.TimeSlot)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	if req.Periods > 0 {
+		slot, err = week.Span(slot.Day, slot.Start, req.Periods)
+	} else {
+		err = week.Check(slot)
+	}
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
 		return
 	}
 
@@ -212,17 +587,48 @@ func (server *Server) createSchedule(ctx *gin.Context) {
 			Valid: true,
 		},
 		TeacherEmail: StringToSQLNullString(req.TeacherEmail),
-		TimeSlot:     StringToSQLNullString(req.TimeSlot),
+		TimeSlot:     StringToSQLNullString(slot.String()),
 		Year:         req.Year,
+		Term:         req.Term,
+		DayOfWeek:    int16(slot.Day),
+		StartMinute:  slot.Start,
+		EndMinute:    slot.End,
+		SessionType:  sessionType(req.SessionType),
+		SubGroupID:   sql.NullInt64{Int64: req.SubGroupID, Valid: req.SubGroupID != 0},
+	}
+	if status, err := server.checkSubGroup(ctx, req.GroupID, req.SubGroupID); err != nil {
+		ctx.JSON(status, errorResponse(err))
+		return
+	}
+	combined, status, err := server.checkCombinedGroups(ctx, req.GroupID, req.SubGroupID, req.CombinedGroupIDs)
+	if err != nil {
+		ctx.JSON(status, errorResponse(err))
+		return
+	}
+	arg.CombinedGroupIds = combined
+
+	result, err := validation.New(server.store).Validate(ctx, validation.FromCreateParams(arg))
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	if conflictErr := result.ConflictErr(); conflictErr != nil {
+		ctx.JSON(http.StatusConflict, conflictResponse(conflictErr))
+		return
 	}
 
 	schedule, err := server.store.CreateSchedule(ctx, arg)
 	if err != nil {
+		if validation.IsExclusionViolation(err) {
+			ctx.JSON(http.StatusConflict, errorResponse(fmt.Errorf("schedule conflict detected: room, teacher, or group already scheduled in an overlapping time slot")))
+			return
+		}
 		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
 		return
 	}
 
-	res := newScheduleResponse(schedule)
+	res := newScheduleResponse(schedule, week)
+	res.Warnings = result.Warnings
 	ctx.JSON(http.StatusOK, res)
 }
 
@@ -253,7 +659,7 @@ func (server *Server) updateSchedule(ctx *gin.Context) {
 	}
 
 	// Get current schedule to ensure it exists
-	_, err = server.store.GetSchedule(ctx, uri.ID)
+	current, err := server.store.GetSchedule(ctx, uri.ID)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			ctx.JSON(http.StatusNotFound, errorResponse(fmt.Errorf("schedule not found")))
@@ -263,6 +669,121 @@ func (server *Server) updateSchedule(ctx *gin.Context) {
 		return
 	}
 
+	groupID := req.GroupID
+	if groupID == 0 {
+		groupID = current.GroupID.Int64
+	}
+	week, err := validation.GridFor(ctx, server.store, groupID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	// Keep the current slot unless a new one is given
+	slot := timeslot.TimeSlot{
+		Day:   timeslot.Day(current.DayOfWeek),
+		Start: current.StartMinute,
+		End:   current.EndMinute,
+	}
+	if req.TimeSlot != "" {
+		currentSlot := slot
+		slot, err = timeslot.Parse(req.TimeSlot)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, errorResponse(err))
+			return
+		}
+		// A multi-period session moved to a single period's slot keeps
+		// its length
+		if req.Periods == 0 {
+			if _, n := timeslot.Covers(week.Teaching(), currentSlot); n > 1 {
+				if _, given := timeslot.Covers(week.Teaching(), slot); given == 1 {
+					req.Periods = n
+				}
+			}
+		}
+	}
+	// A schedule that is moved, or given to another section, must fit the
+	// section's grid; one left where it is keeps its slot even if the grid
+	// has changed since.
+	switch {
+	case req.Periods > 0:
+		slot, err = week.Span(slot.Day, slot.Start, req.Periods)
+	case req.TimeSlot != "" || groupID != current.GroupID.Int64:
+		err = week.Check(slot)
+	}
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	// Fields left out of the request keep their current values
+	if req.GroupID == 0 {
+		req.GroupID = current.GroupID.Int64
+	}
+	if req.RoomID == 0 {
+		req.RoomID = current.RoomID.Int64
+	}
+	if req.SubjectID == 0 {
+		req.SubjectID = current.SubjectID.Int64
+	}
+	if req.TeacherEmail == "" {
+		req.TeacherEmail = current.TeacherEmail.String
+	}
+	if req.Year == 0 {
+		req.Year = current.Year
+	}
+	if req.Term == 0 {
+		req.Term = current.Term
+	}
+	if req.Year != current.Year || req.Term != current.Term {
+		if status, err := server.checkTerm(ctx, req.Year, req.Term); err != nil {
+			ctx.JSON(status, errorResponse(err))
+			return
+		}
+	}
+	if req.SessionType == "" {
+		req.SessionType = string(current.SessionType)
+	}
+	subGroupID := current.SubGroupID.Int64
+	if req.SubGroupID != nil {
+		subGroupID = *req.SubGroupID
+	}
+	if status, err := server.checkSubGroup(ctx, req.GroupID, subGroupID); err != nil {
+		ctx.JSON(status, errorResponse(err))
+		return
+	}
+	combinedGroupIDs := current.CombinedGroupIds
+	if req.CombinedGroupIDs != nil {
+		combinedGroupIDs = *req.CombinedGroupIDs
+	}
+	combined, status, err := server.checkCombinedGroups(ctx, req.GroupID, subGroupID, combinedGroupIDs)
+	if err != nil {
+		ctx.JSON(status, errorResponse(err))
+		return
+	}
+
+	result, err := validation.New(server.store).Validate(ctx, validation.Schedule{
+		ID:               uri.ID,
+		GroupID:          req.GroupID,
+		RoomID:           req.RoomID,
+		SubjectID:        req.SubjectID,
+		TeacherEmail:     req.TeacherEmail,
+		Year:             req.Year,
+		Term:             req.Term,
+		SessionType:      sessionType(req.SessionType),
+		SubGroupID:       subGroupID,
+		Slot:             slot,
+		CombinedGroupIDs: combined,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	if conflictErr := result.ConflictErr(); conflictErr != nil {
+		ctx.JSON(http.StatusConflict, conflictResponse(conflictErr))
+		return
+	}
+
 	arg := db.UpdateScheduleParams{
 		ID: uri.ID,
 		GroupID: sql.NullInt64{
@@ -277,17 +798,30 @@ func (server *Server) updateSchedule(ctx *gin.Context) {
 			Int64: req.SubjectID,
 			Valid: req.SubjectID != 0,
 		},
-		TeacherEmail: StringToSQLNullString(req.TeacherEmail),
-		TimeSlot:     StringToSQLNullString(req.TimeSlot),
+		TeacherEmail:     StringToSQLNullString(req.TeacherEmail),
+		TimeSlot:         StringToSQLNullString(slot.String()),
+		DayOfWeek:        int16(slot.Day),
+		StartMinute:      slot.Start,
+		EndMinute:        slot.End,
+		Year:             req.Year,
+		Term:             req.Term,
+		SessionType:      db.SessionType(req.SessionType),
+		SubGroupID:       sql.NullInt64{Int64: subGroupID, Valid: subGroupID != 0},
+		CombinedGroupIds: combined,
 	}
 
 	updatedSchedule, err := server.store.UpdateSchedule(ctx, arg)
 	if err != nil {
+		if validation.IsExclusionViolation(err) {
+			ctx.JSON(http.StatusConflict, errorResponse(fmt.Errorf("schedule conflict detected: room, teacher, or group already scheduled in an overlapping time slot")))
+			return
+		}
 		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
 		return
 	}
 
-	res := newScheduleResponse(updatedSchedule)
+	res := newScheduleResponse(updatedSchedule, week)
+	res.Warnings = result.Warnings
 	ctx.JSON(http.StatusOK, res)
 }
 
@@ -323,35 +857,41 @@ func (server *Server) getSchedulesByTeacher(ctx *gin.Context) {
 		return
 	}
 
-	yearStr := ctx.Query("year")
-	if yearStr == "" {
-		yearStr = strconv.Itoa(getNepaliYear())
+	date, dated, err := dateFromQuery(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
 	}
-
-	yearInt, err := strconv.Atoi(yearStr)
+	year, err := yearFromQuery(ctx)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, errorResponse(fmt.Errorf("invalid year")))
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
 		return
 	}
-
-	// Convert to int32
-	year := int32(yearInt)
-
-	// Create query parameters with year
-	arg := db.GetSchedulesByTeacherParams{
-		TeacherEmail: StringToSQLNullString(teacherEmail),
-		Year:         year,
+	if dated && ctx.Query("year") == "" {
+		year = int32(bs.YearOf(date))
+	}
+	term, err := server.termFromQuery(ctx, year, date)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	draft, err := server.wantsDraft(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusForbidden, errorResponse(err))
+		return
 	}
 
-	schedules, err := server.store.GetSchedulesByTeacher(ctx, arg)
+	scheduleResponses, err := server.teacherSchedules(ctx, teacherEmail, year, term, draft)
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
 		return
 	}
-
-	scheduleResponses := make([]detailedScheduleResponse, 0)
-	for _, schedule := range schedules {
-		scheduleResponses = append(scheduleResponses, newDetailedScheduleByTeacherResponse(schedule))
+	if dated {
+		scheduleResponses, err = server.overlayCovers(ctx, scheduleResponses, date, teacherEmail)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+			return
+		}
 	}
 
 	ctx.JSON(http.StatusOK, scheduleResponses)
@@ -370,41 +910,48 @@ func (server *Server) getSchedulesByRoom(ctx *gin.Context) {
 		return
 	}
 
-	// Extract year from query parameters
-	yearStr := ctx.Query("year")
-	if yearStr == "" {
-		yearStr = strconv.Itoa(getNepaliYear())
+	date, dated, err := dateFromQuery(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
 	}
-
-	yearInt, err := strconv.Atoi(yearStr)
+	year, err := yearFromQuery(ctx)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, errorResponse(fmt.Errorf("invalid year")))
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
 		return
 	}
-
-	// Convert to int32
-	year := int32(yearInt)
-
-	// Create query parameters
-	arg := db.GetSchedulesByRoomParams{
-		RoomID: sql.NullInt64{
-			Int64: roomID,
-			Valid: true,
-		},
-		Year: year,
+	if dated && ctx.Query("year") == "" {
+		year = int32(bs.YearOf(date))
 	}
-
-	// Use the arg parameter that includes both room ID and year
-	schedules, err := server.store.GetSchedulesByRoom(ctx, arg)
+	term, err := server.termFromQuery(ctx, year, date)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	draft, err := server.wantsDraft(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusForbidden, errorResponse(err))
 		return
 	}
 
 	// Return empty array if no schedules found
-	scheduleResponses := make([]detailedScheduleResponse, 0)
-	for _, schedule := range schedules {
-		scheduleResponses = append(scheduleResponses, newDetailedScheduleByRoomResponse(schedule))
+	scheduleResponses, err := server.roomSchedules(ctx, roomID, year, term, draft)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	if dated {
+		scheduleResponses, err = server.overlayCovers(ctx, scheduleResponses, date, "")
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+			return
+		}
+		bookings, err := server.roomBookings(ctx, roomID, date, date)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+			return
+		}
+		scheduleResponses = append(scheduleResponses, bookingEntries(bookings, year)...)
 	}
 
 	ctx.JSON(http.StatusOK, scheduleResponses)
@@ -424,39 +971,41 @@ func (server *Server) getSchedulesByGroup(ctx *gin.Context) {
 		return
 	}
 
-	// Extract year from query parameters
-	yearStr := ctx.Query("year")
-	if yearStr == "" {
-		yearStr = strconv.Itoa(getNepaliYear())
+	date, dated, err := dateFromQuery(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
 	}
-
-	yearInt, err := strconv.Atoi(yearStr)
+	year, err := yearFromQuery(ctx)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, errorResponse(fmt.Errorf("invalid year")))
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
 		return
 	}
-
-	// Convert to int32
-	year := int32(yearInt)
-
-	// Create query parameters with year
-	arg := db.GetSchedulesByGroupParams{
-		GroupID: sql.NullInt64{
-			Int64: groupID,
-			Valid: true,
-		},
-		Year: year,
+	if dated && ctx.Query("year") == "" {
+		year = int32(bs.YearOf(date))
+	}
+	term, err := server.termFromQuery(ctx, year, date)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	draft, err := server.wantsDraft(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusForbidden, errorResponse(err))
+		return
 	}
 
-	schedules, err := server.store.GetSchedulesByGroup(ctx, arg)
+	scheduleResponses, err := server.groupSchedules(ctx, groupID, year, term, draft)
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
 		return
 	}
-
-	scheduleResponses := make([]detailedScheduleResponse, 0)
-	for _, schedule := range schedules {
-		scheduleResponses = append(scheduleResponses, newDetailedScheduleByGroupResponse(schedule))
+	if dated {
+		scheduleResponses, err = server.overlayCovers(ctx, scheduleResponses, date, "")
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+			return
+		}
 	}
 
 	ctx.JSON(http.StatusOK, scheduleResponses)
@@ -471,7 +1020,7 @@ func (server *Server) getAvailableYears(ctx *gin.Context) {
 
 	// If no years found, return the current Nepali year in an array
 	if len(years) == 0 {
-		currentYear := int32(getNepaliYear())
+		currentYear := int32(bs.CurrentYear())
 		years = []int32{currentYear}
 	}
 
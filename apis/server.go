@@ -16,6 +16,9 @@ type Server struct {
 	router              *gin.Engine
 	tokenMaker          token.Maker
 	accessTokenDuration time.Duration
+	// notifications is set when an SMTP server is configured; routine
+	// changes are only queued as emails then.
+	notifications bool
 }
 
 func NewServer(store *db.Store, accessTokenSymmetricKey string, accessTokenDuration time.Duration) (*Server, error) {
@@ -68,18 +71,43 @@ func (server *Server) setRouter(router *gin.Engine) {
 	authRoutes.PUT("/teachers/:email", server.updateTeacher)
 	authRoutes.DELETE("/teachers/:email", server.deleteTeacher)
 	authRoutes.GET("/get_me_teacher", server.getMe)
+	authRoutes.GET("/teachers/:email/availability", server.listTeacherAvailability)
+	authRoutes.POST("/teachers/:email/availability", server.createTeacherAvailability)
+	authRoutes.DELETE("/teachers/:email/availability/:id", server.deleteTeacherAvailability)
+	authRoutes.GET("/get_me_teacher/availability", server.listTeacherAvailability)
+	authRoutes.POST("/get_me_teacher/availability", server.createTeacherAvailability)
+	authRoutes.DELETE("/get_me_teacher/availability/:id", server.deleteTeacherAvailability)
+
+	authRoutes.GET("/workload-limits", server.listWorkloadLimits)
+	authRoutes.POST("/workload-limits", server.createWorkloadLimit)
+	authRoutes.PUT("/workload-limits/:id", server.updateWorkloadLimit)
+	authRoutes.DELETE("/workload-limits/:id", server.deleteWorkloadLimit)
+	authRoutes.GET("/reports/workload", server.getWorkloadReport)
+
+	authRoutes.GET("/curriculum", server.listCurriculumRequirements)
+	authRoutes.PUT("/curriculum", server.setCurriculumRequirement)
+	authRoutes.DELETE("/curriculum/:id", server.deleteCurriculumRequirement)
+	authRoutes.GET("/reports/completeness", server.getCompletenessReport)
 
 	authRoutes.POST("/rooms", server.addRoom)
 	authRoutes.GET("/rooms/:room_code", server.getRoom)
 	router.GET("/rooms", server.listRooms)
+	router.GET("/rooms/free", server.findFreeRooms)
 	authRoutes.PUT("/rooms/:id", server.updateRoom)
 	authRoutes.DELETE("/rooms/:id", server.deleteRoom)
 
+	router.GET("/room-bookings", server.listRoomBookings)
+	authRoutes.POST("/room-bookings", server.createRoomBooking)
+	authRoutes.DELETE("/room-bookings/:id", server.deleteRoomBooking)
+
 	authRoutes.POST("/subjects", server.createSubject)
 	authRoutes.GET("/subjects/:id", server.getSubject)
 	router.GET("/subjects", server.listSubjects)
 	authRoutes.PUT("/subjects/:id", server.updateSubject)
 	authRoutes.DELETE("/subjects/:id", server.deleteSubject)
+	router.GET("/subjects/:id/room-requirements", server.listRoomRequirements)
+	authRoutes.PUT("/subjects/:id/room-requirements", server.setRoomRequirement)
+	authRoutes.DELETE("/subjects/:id/room-requirements/:requirement_id", server.deleteRoomRequirement)
 	authRoutes.POST("/subject/:id/:email", server.assignTeacherToSubject)
 	authRoutes.GET("/subject/:id/teachers", server.getAssignedTeacher)
 	authRoutes.GET("/subject/remove/:id/:email", server.removeTeacherFromSubject)
@@ -90,17 +118,83 @@ func (server *Server) setRouter(router *gin.Engine) {
 	authRoutes.PUT("/student-sections/:id", server.updateStudentSection)
 	authRoutes.DELETE("/student-sections/:id", server.deleteStudentSection)
 	authRoutes.GET("/student-sections/:id/students", server.getStudentsInSection)
+	authRoutes.GET("/student-sections/:id/sub-groups", server.listSubGroups)
+	authRoutes.POST("/student-sections/:id/sub-groups", server.createSubGroup)
+	authRoutes.PUT("/student-sections/:id/sub-groups/:sub_group_id", server.updateSubGroup)
+	authRoutes.DELETE("/student-sections/:id/sub-groups/:sub_group_id", server.deleteSubGroup)
+	authRoutes.GET("/student-sections/:id/sub-groups/:sub_group_id/students", server.listSubGroupStudents)
+	authRoutes.PUT("/student-sections/:id/sub-groups/:sub_group_id/students", server.setSubGroupStudents)
+	router.GET("/student-sections/:id/grid", server.getSectionGrid)
 
 	router.GET("/schedules/room/:room_id", server.getSchedulesByRoom)
 	router.GET("/schedules/group/:group_id", server.getSchedulesByGroup)
+	router.GET("/schedules/room/:room_id/pdf", server.getRoomRoutinePDF)
+	router.GET("/schedules/group/:group_id/pdf", server.getGroupRoutinePDF)
+	router.GET("/schedules/department/:department/pdf", server.getDepartmentBookletPDF)
 
 	authRoutes.POST("/schedules", server.createSchedule)
 	authRoutes.GET("/schedules/teacher/:email", server.getSchedulesByTeacher)
+	authRoutes.GET("/schedules/teacher/:email/pdf", server.getTeacherRoutinePDF)
 
+	authRoutes.POST("/schedules/rollover", server.rolloverSchedules)
 	authRoutes.PUT("/schedules/:id", server.updateSchedule)
 	authRoutes.DELETE("/schedules/:id", server.deleteSchedule)
 
 	router.GET("/years/schedules", server.getAvailableYears)
+	router.GET("/terms", server.listAcademicTerms)
+	authRoutes.POST("/terms", server.createAcademicTerm)
+	authRoutes.PUT("/terms/:id", server.updateAcademicTerm)
+	authRoutes.DELETE("/terms/:id", server.deleteAcademicTerm)
+	router.GET("/grids", server.listGrids)
+	router.GET("/grids/:id", server.getGrid)
+	authRoutes.POST("/grids", server.createGrid)
+	authRoutes.PUT("/grids/:id", server.updateGrid)
+	authRoutes.DELETE("/grids/:id", server.deleteGrid)
+
+	router.GET("/holidays/", server.listCalendarEvents)
+	router.GET("/holidays/:id", server.getCalendarEvent)
+	authRoutes.POST("/holidays/", server.createCalendarEvent)
+	authRoutes.POST("/holidays/import", server.importCalendarEvents)
+	authRoutes.PUT("/holidays/:id", server.updateCalendarEvent)
+	authRoutes.DELETE("/holidays/:id", server.deleteCalendarEvent)
+
+	// calendar apps cannot send a bearer header, the token in the URL
+	// authenticates the feed instead
+	router.GET("/calendar/:token/teacher/:email", server.teacherCalendar)
+	router.GET("/calendar/:token/room/:room_id", server.roomCalendar)
+	router.GET("/calendar/:token/group/:group_id", server.groupCalendar)
+	authRoutes.GET("/calendar-token", server.getCalendarToken)
+	authRoutes.POST("/calendar-token/rotate", server.rotateCalendarToken)
+
+	authRoutes.GET("/absences", server.listTeacherAbsences)
+	authRoutes.POST("/absences", server.createTeacherAbsence)
+	authRoutes.GET("/absences/:id", server.getTeacherAbsence)
+	authRoutes.DELETE("/absences/:id", server.deleteTeacherAbsence)
+	authRoutes.GET("/absences/:id/substitutes", server.listSubstitutes)
+	authRoutes.POST("/absences/:id/covers", server.assignCover)
+	authRoutes.DELETE("/absences/:id/covers/:cover_id", server.deleteCover)
+
+	authRoutes.POST("/routines/optimize_routine/", server.optimizeRoutine)
+	authRoutes.POST("/routines/optimize_routine/apply", server.applyRoutine)
+	authRoutes.POST("/routines/natural_language_query/", server.naturalLanguageQuery)
+	authRoutes.GET("/routines/:year/versions", server.listRoutineVersions)
+	authRoutes.GET("/routines/:year/draft/validate", server.validateDraft)
+	authRoutes.POST("/routines/:year/publish", server.publishRoutine)
+	authRoutes.POST("/routines/:year/rollback", server.rollbackRoutine)
+
+	authRoutes.POST("/import/:entity", server.importSheet)
+
+	authRoutes.GET("/notifications/preferences", server.getNotificationPreferences)
+	authRoutes.PUT("/notifications/preferences", server.updateNotificationPreferences)
+	authRoutes.GET("/notifications/outbox", server.listEmailOutbox)
+	authRoutes.POST("/notifications/outbox/:id/retry", server.retryEmail)
+}
+
+// EnableNotifications makes publishing or rolling back a routine queue
+// emails to the teachers and students of the classes it moved or
+// cancelled. Something must run a notify.Worker to send them.
+func (server *Server) EnableNotifications() {
+	server.notifications = true
 }
 
 func (server *Server) Start(address string) error {
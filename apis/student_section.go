@@ -16,6 +16,7 @@ type createStudentSectionRequest struct {
 	YearEnrolled int32  `json:"year_enrolled" binding:"required"`
 	GroupName    string `json:"group_name" binding:"required"`
 	Department   string `json:"department" binding:"required"`
+	Size         *int32 `json:"size" binding:"omitempty,min=0"`
 }
 
 type studentSectionResponse struct {
@@ -25,6 +26,11 @@ type studentSectionResponse struct {
 	YearEnrolled int32  `json:"year_enrolled"`
 	GroupName    string `json:"group_name"`
 	Department   string `json:"department"`
+	// Size is the headcount entered by hand, null when it is counted from
+	// the section's students.
+	Size *int32 `json:"size"`
+	// Headcount is the size used for room capacity checks.
+	Headcount *int32 `json:"headcount,omitempty"`
 }
 
 type getStudentSectionRequest struct {
@@ -45,6 +51,8 @@ type updateStudentSectionRequest struct {
 	YearEnrolled int32  `json:"year_enrolled"`
 	GroupName    string `json:"group_name"`
 	Department   string `json:"department"`
+	// Size replaces the manual headcount; omitted keeps the current one.
+	Size *int32 `json:"size" binding:"omitempty,min=0"`
 }
 
 func newStudentSectionResponse(section db.StudentSection) studentSectionResponse {
@@ -55,6 +63,7 @@ func newStudentSectionResponse(section db.StudentSection) studentSectionResponse
 		YearEnrolled: section.YearEnrolled.Int32,
 		GroupName:    SQLNullStringToString(section.GroupName),
 		Department:   SQLNullStringToString(section.Department),
+		Size:         nullInt32Ptr(section.Size),
 	}
 
 }
@@ -87,6 +96,9 @@ func (server *Server) createStudentSection(ctx *gin.Context) {
 		GroupName:  StringToSQLNullString(req.GroupName),
 		Department: StringToSQLNullString(req.Department),
 	}
+	if req.Size != nil {
+		arg.Size = sql.NullInt32{Int32: *req.Size, Valid: true}
+	}
 
 	section, err := server.store.CreateStudentSection(ctx, arg)
 	if err != nil {
@@ -116,7 +128,14 @@ func (server *Server) getStudentSection(ctx *gin.Context) {
 		return
 	}
 
+	headcount, err := server.store.GetStudentSectionSize(ctx, section.ID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
 	res := newStudentSectionResponse(section)
+	res.Headcount = &headcount
 	ctx.JSON(http.StatusOK, res)
 }
 
@@ -193,7 +212,7 @@ func (server *Server) updateStudentSection(ctx *gin.Context) {
 	}
 
 	// Get current section to ensure it exists
-	_, err = server.store.GetStudentSection(ctx, uri.ID)
+	current, err := server.store.GetStudentSection(ctx, uri.ID)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			ctx.JSON(http.StatusNotFound, errorResponse(fmt.Errorf("student section not found")))
@@ -214,6 +233,10 @@ func (server *Server) updateStudentSection(ctx *gin.Context) {
 		},
 		GroupName:  StringToSQLNullString(req.GroupName),
 		Department: StringToSQLNullString(req.Department),
+		Size:       current.Size,
+	}
+	if req.Size != nil {
+		arg.Size = sql.NullInt32{Int32: *req.Size, Valid: true}
 	}
 
 	updatedSection, err := server.store.UpdateStudentSection(ctx, arg)
@@ -0,0 +1,330 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lib/pq"
+	db "github.com/nirajan1111/routiney/db/sqlc"
+)
+
+type subGroupRequest struct {
+	Name string `json:"name" binding:"required,max=20"`
+	// Size is the sub-group's headcount for room capacity checks; without
+	// it the whole section's is used.
+	Size *int32 `json:"size" binding:"omitempty,min=1"`
+}
+
+type subGroupURI struct {
+	ID         int32 `uri:"id" binding:"required,min=1"`
+	SubGroupID int64 `uri:"sub_group_id" binding:"required,min=1"`
+}
+
+type subGroupResponse struct {
+	ID      int64  `json:"id"`
+	GroupID int32  `json:"group_id"`
+	Name    string `json:"name"`
+	Size    *int32 `json:"size"`
+}
+
+func newSubGroupResponse(g db.SubGroup) subGroupResponse {
+	return subGroupResponse{
+		ID:      g.ID,
+		GroupID: g.GroupID,
+		Name:    g.Name,
+		Size:    nullInt32Ptr(g.Size),
+	}
+}
+
+func isForeignKeyViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "23503"
+}
+
+func (server *Server) listSubGroups(ctx *gin.Context) {
+	var uri getStudentSectionRequest
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	subGroups, err := server.store.ListSubGroups(ctx, uri.ID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	res := make([]subGroupResponse, 0, len(subGroups))
+	for _, g := range subGroups {
+		res = append(res, newSubGroupResponse(g))
+	}
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (server *Server) createSubGroup(ctx *gin.Context) {
+	var uri getStudentSectionRequest
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	var req subGroupRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	adminStatus, err := checkAdmin(ctx, "admin")
+	if err != nil {
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return
+	}
+	if !adminStatus {
+		ctx.JSON(http.StatusForbidden, errorResponse(fmt.Errorf("not authorized to edit sub-groups")))
+		return
+	}
+
+	if _, err := server.store.GetStudentSection(ctx, uri.ID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(http.StatusNotFound, errorResponse(fmt.Errorf("student section not found")))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	arg := db.CreateSubGroupParams{
+		GroupID: uri.ID,
+		Name:    req.Name,
+	}
+	if req.Size != nil {
+		arg.Size = sql.NullInt32{Int32: *req.Size, Valid: true}
+	}
+	subGroup, err := server.store.CreateSubGroup(ctx, arg)
+	if err != nil {
+		if isUniqueViolation(err) {
+			ctx.JSON(http.StatusConflict, errorResponse(fmt.Errorf("the section already has a sub-group %s", req.Name)))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	ctx.JSON(http.StatusOK, newSubGroupResponse(subGroup))
+}
+
+func (server *Server) updateSubGroup(ctx *gin.Context) {
+	var uri subGroupURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	var req subGroupRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	adminStatus, err := checkAdmin(ctx, "admin")
+	if err != nil {
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return
+	}
+	if !adminStatus {
+		ctx.JSON(http.StatusForbidden, errorResponse(fmt.Errorf("not authorized to edit sub-groups")))
+		return
+	}
+
+	current, err := server.store.GetSubGroup(ctx, uri.SubGroupID)
+	if err == nil && current.GroupID != uri.ID {
+		err = sql.ErrNoRows
+	}
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(http.StatusNotFound, errorResponse(fmt.Errorf("sub-group not found")))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	// an omitted size keeps the current one
+	arg := db.UpdateSubGroupParams{
+		ID:   uri.SubGroupID,
+		Name: req.Name,
+		Size: current.Size,
+	}
+	if req.Size != nil {
+		arg.Size = sql.NullInt32{Int32: *req.Size, Valid: true}
+	}
+	subGroup, err := server.store.UpdateSubGroup(ctx, arg)
+	if err != nil {
+		if isUniqueViolation(err) {
+			ctx.JSON(http.StatusConflict, errorResponse(fmt.Errorf("the section already has a sub-group %s", req.Name)))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	ctx.JSON(http.StatusOK, newSubGroupResponse(subGroup))
+}
+
+func (server *Server) deleteSubGroup(ctx *gin.Context) {
+	var uri subGroupURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	adminStatus, err := checkAdmin(ctx, "admin")
+	if err != nil {
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return
+	}
+	if !adminStatus {
+		ctx.JSON(http.StatusForbidden, errorResponse(fmt.Errorf("not authorized to edit sub-groups")))
+		return
+	}
+
+	current, err := server.store.GetSubGroup(ctx, uri.SubGroupID)
+	if err == nil && current.GroupID != uri.ID {
+		err = sql.ErrNoRows
+	}
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(http.StatusNotFound, errorResponse(fmt.Errorf("sub-group not found")))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	if _, err := server.store.DeleteSubGroup(ctx, uri.SubGroupID); err != nil {
+		if isForeignKeyViolation(err) {
+			ctx.JSON(http.StatusConflict, errorResponse(fmt.Errorf("sub-group %s still has classes scheduled", current.Name)))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"message": "Sub-group deleted successfully"})
+}
+
+// errNotInSection aborts setting sub-group students that are not all
+// students of its section.
+var errNotInSection = errors.New("some students are not students")
+
+type subGroupStudentsRequest struct {
+	StudentIDs []int64 `json:"student_ids" binding:"required,dive,min=1"`
+}
+
+// listSubGroupStudents lists the students of a sub-group, who are the only
+// ones emailed about its classes.
+func (server *Server) listSubGroupStudents(ctx *gin.Context) {
+	var uri subGroupURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	subGroup, err := server.store.GetSubGroup(ctx, uri.SubGroupID)
+	if err == nil && subGroup.GroupID != uri.ID {
+		err = sql.ErrNoRows
+	}
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(http.StatusNotFound, errorResponse(fmt.Errorf("sub-group not found")))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	students, err := server.store.ListSubGroupStudents(ctx, uri.SubGroupID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	if students == nil {
+		students = []db.Student{}
+	}
+	ctx.JSON(http.StatusOK, students)
+}
+
+// setSubGroupStudents replaces the students of a sub-group. They must all
+// be students of its section and in none of its other sub-groups, which
+// may have classes at the same time.
+func (server *Server) setSubGroupStudents(ctx *gin.Context) {
+	var uri subGroupURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	var req subGroupStudentsRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	adminStatus, err := checkAdmin(ctx, "admin")
+	if err != nil {
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return
+	}
+	if !adminStatus {
+		ctx.JSON(http.StatusForbidden, errorResponse(fmt.Errorf("not authorized to edit sub-groups")))
+		return
+	}
+
+	subGroup, err := server.store.GetSubGroup(ctx, uri.SubGroupID)
+	if err == nil && subGroup.GroupID != uri.ID {
+		err = sql.ErrNoRows
+	}
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(http.StatusNotFound, errorResponse(fmt.Errorf("sub-group not found")))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ids := make(map[int64]bool, len(req.StudentIDs))
+	for _, id := range req.StudentIDs {
+		ids[id] = true
+	}
+	var students []db.Student
+	err = server.store.ExecTx(ctx, func(q *db.Queries) error {
+		if err := q.DeleteSubGroupStudents(ctx, uri.SubGroupID); err != nil {
+			return err
+		}
+		added, err := q.AddSubGroupStudents(ctx, db.AddSubGroupStudentsParams{
+			SubGroupID: uri.SubGroupID,
+			StudentIds: req.StudentIDs,
+		})
+		if err != nil {
+			return err
+		}
+		if int(added) != len(ids) {
+			return errNotInSection
+		}
+		students, err = q.ListSubGroupStudents(ctx, uri.SubGroupID)
+		return err
+	})
+	if err != nil {
+		if errors.Is(err, errNotInSection) {
+			ctx.JSON(http.StatusBadRequest, errorResponse(fmt.Errorf("%v of section %d", err, uri.ID)))
+			return
+		}
+		if isUniqueViolation(err) {
+			ctx.JSON(http.StatusConflict, errorResponse(fmt.Errorf("some students are already in another sub-group of section %d", uri.ID)))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	if students == nil {
+		students = []db.Student{}
+	}
+	ctx.JSON(http.StatusOK, students)
+}
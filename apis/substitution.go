@@ -0,0 +1,623 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nirajan1111/routiney/bs"
+	db "github.com/nirajan1111/routiney/db/sqlc"
+	"github.com/nirajan1111/routiney/timeslot"
+)
+
+// maxAbsenceDays bounds the session listing of a single absence.
+const maxAbsenceDays = 120
+
+const (
+	coverStatusCovered   = "covered"
+	coverStatusCancelled = "cancelled"
+)
+
+type createTeacherAbsenceRequest struct {
+	TeacherEmail string `json:"teacher_email" binding:"required,email"`
+	StartDate    string `json:"start_date" binding:"required"`
+	EndDate      string `json:"end_date"`
+	Reason       string `json:"reason"`
+}
+
+type listTeacherAbsencesRequest struct {
+	TeacherEmail string `form:"teacher_email"`
+	From         string `form:"from"`
+	To           string `form:"to"`
+}
+
+type teacherAbsenceResponse struct {
+	ID           int64                     `json:"id"`
+	TeacherEmail string                    `json:"teacher_email"`
+	StartDate    string                    `json:"start_date"`
+	EndDate      string                    `json:"end_date"`
+	StartDateBs  string                    `json:"start_date_bs,omitempty"`
+	EndDateBs    string                    `json:"end_date_bs,omitempty"`
+	Reason       string                    `json:"reason,omitempty"`
+	CreatedBy    string                    `json:"created_by,omitempty"`
+	CreatedAt    time.Time                 `json:"created_at"`
+	Sessions     []affectedSessionResponse `json:"sessions,omitempty"`
+}
+
+type coverResponse struct {
+	ID              int64  `json:"id"`
+	Date            string `json:"date"`
+	DateBs          string `json:"date_bs,omitempty"`
+	ScheduleID      int64  `json:"schedule_id"`
+	Status          string `json:"status"`
+	SubstituteEmail string `json:"substitute_email,omitempty"`
+	SubstituteName  string `json:"substitute_name,omitempty"`
+	Note            string `json:"note,omitempty"`
+}
+
+// affectedSessionResponse is one class the absent teacher would have taught.
+type affectedSessionResponse struct {
+	Date     string                   `json:"date"`
+	DateBs   string                   `json:"date_bs,omitempty"`
+	Schedule detailedScheduleResponse `json:"schedule"`
+	Cover    *coverResponse           `json:"cover,omitempty"`
+}
+
+type assignCoverRequest struct {
+	ScheduleID      int64  `json:"schedule_id" binding:"required,min=1"`
+	Date            string `json:"date" binding:"required"`
+	SubstituteEmail string `json:"substitute_email" binding:"omitempty,email"`
+	Note            string `json:"note"`
+}
+
+type listSubstitutesRequest struct {
+	ScheduleID int64  `form:"schedule_id" binding:"required,min=1"`
+	Date       string `form:"date" binding:"required"`
+}
+
+type deleteCoverRequest struct {
+	ID      int64 `uri:"id" binding:"required,min=1"`
+	CoverID int64 `uri:"cover_id" binding:"required,min=1"`
+}
+
+func newTeacherAbsenceResponse(a db.TeacherAbsence) teacherAbsenceResponse {
+	return teacherAbsenceResponse{
+		ID:           a.ID,
+		TeacherEmail: a.TeacherEmail,
+		StartDate:    a.StartDate.Format(dateLayout),
+		EndDate:      a.EndDate.Format(dateLayout),
+		StartDateBs:  bsDate(a.StartDate),
+		EndDateBs:    bsDate(a.EndDate),
+		Reason:       a.Reason.String,
+		CreatedBy:    a.CreatedBy.String,
+		CreatedAt:    a.CreatedAt,
+	}
+}
+
+func newCoverResponse(o db.ScheduleOverride, substituteName sql.NullString) *coverResponse {
+	status := coverStatusCovered
+	if !o.SubstituteEmail.Valid {
+		status = coverStatusCancelled
+	}
+	return &coverResponse{
+		ID:              o.ID,
+		Date:            o.Date.Format(dateLayout),
+		DateBs:          bsDate(o.Date),
+		ScheduleID:      o.ScheduleID,
+		Status:          status,
+		SubstituteEmail: o.SubstituteEmail.String,
+		SubstituteName:  substituteName.String,
+		Note:            o.Note.String,
+	}
+}
+
+func newCoverResponseFromRow(o db.ListScheduleOverridesByAbsenceRow) *coverResponse {
+	return newCoverResponse(db.ScheduleOverride{
+		ID:              o.ID,
+		AbsenceID:       o.AbsenceID,
+		ScheduleID:      o.ScheduleID,
+		Date:            o.Date,
+		SubstituteEmail: o.SubstituteEmail,
+		Note:            o.Note,
+		CreatedBy:       o.CreatedBy,
+		CreatedAt:       o.CreatedAt,
+	}, o.SubstituteName)
+}
+
+// dateFromQuery reads the optional ?date= of the routine endpoints.
+func dateFromQuery(ctx *gin.Context) (time.Time, bool, error) {
+	value := ctx.Query("date")
+	if value == "" {
+		return time.Time{}, false, nil
+	}
+	t, err := parseDate("date", value)
+	return t, err == nil, err
+}
+
+// absenceSessions lists the classes the absent teacher has on each day of
+// the absence, skipping holidays, together with any cover already recorded.
+func (server *Server) absenceSessions(ctx *gin.Context, absence db.TeacherAbsence) ([]affectedSessionResponse, error) {
+	overrides, err := server.store.ListScheduleOverridesByAbsence(ctx, absence.ID)
+	if err != nil {
+		return nil, err
+	}
+	covers := make(map[string]*coverResponse, len(overrides))
+	for _, o := range overrides {
+		covers[fmt.Sprintf("%d/%s", o.ScheduleID, o.Date.Format(dateLayout))] = newCoverResponseFromRow(o)
+	}
+
+	// an absence can run across the end of a term or year
+	type yearTerm struct {
+		year int32
+		term int16
+	}
+	schedulesByTerm := make(map[yearTerm][]detailedScheduleResponse)
+	holidaysByYear := make(map[int32][]db.CalendarEvent)
+	sessions := make([]affectedSessionResponse, 0)
+	for d := absence.StartDate; !d.After(absence.EndDate); d = d.AddDate(0, 0, 1) {
+		year := int32(bs.YearOf(d))
+		term, err := server.termOn(ctx, year, d)
+		if err != nil {
+			return nil, err
+		}
+		key := yearTerm{year, term}
+		schedules, ok := schedulesByTerm[key]
+		if !ok {
+			rows, err := server.store.GetSchedulesByTeacher(ctx, db.GetSchedulesByTeacherParams{
+				TeacherEmail: StringToSQLNullString(absence.TeacherEmail),
+				Year:         year,
+				Term:         term,
+			})
+			if err != nil {
+				return nil, err
+			}
+			for _, row := range rows {
+				schedules = append(schedules, newDetailedScheduleByTeacherResponse(row))
+			}
+			schedulesByTerm[key] = schedules
+		}
+		if _, ok := holidaysByYear[year]; !ok {
+			if holidaysByYear[year], err = server.noClassDays(ctx, year); err != nil {
+				return nil, err
+			}
+		}
+		if onHoliday(holidaysByYear[year], d) {
+			continue
+		}
+
+		date := d.Format(dateLayout)
+		for _, s := range schedules {
+			slot, err := timeslot.Parse(s.TimeSlot)
+			if err != nil || slot.Day != timeslot.Day(d.Weekday()) {
+				continue
+			}
+			sessions = append(sessions, affectedSessionResponse{
+				Date:     date,
+				DateBs:   bsDate(d),
+				Schedule: s,
+				Cover:    covers[fmt.Sprintf("%d/%s", s.ID, date)],
+			})
+		}
+	}
+	return sessions, nil
+}
+
+func onHoliday(events []db.CalendarEvent, d time.Time) bool {
+	date := d.Format(dateLayout)
+	for _, e := range events {
+		if date >= e.StartDate.Format(dateLayout) && date <= e.EndDate.Format(dateLayout) {
+			return true
+		}
+	}
+	return false
+}
+
+// absenceSession finds one session of an absence.
+func (server *Server) absenceSession(ctx *gin.Context, absence db.TeacherAbsence, scheduleID int64, date string) (affectedSessionResponse, error) {
+	sessions, err := server.absenceSessions(ctx, absence)
+	if err != nil {
+		return affectedSessionResponse{}, err
+	}
+	for _, s := range sessions {
+		if s.Schedule.ID == scheduleID && s.Date == date {
+			return s, nil
+		}
+	}
+	return affectedSessionResponse{}, errNoSession
+}
+
+var errNoSession = errors.New("the absent teacher has no such session during the absence")
+
+// substitutes lists the teachers who can take over a session.
+func (server *Server) substitutes(ctx *gin.Context, absence db.TeacherAbsence, session affectedSessionResponse) ([]db.Teacher, error) {
+	slot, err := timeslot.Parse(session.Schedule.TimeSlot)
+	if err != nil {
+		return nil, err
+	}
+	date, err := parseDate("date", session.Date)
+	if err != nil {
+		return nil, err
+	}
+	return server.store.ListSubstituteCandidates(ctx, db.ListSubstituteCandidatesParams{
+		SubjectID:   sql.NullInt64{Int64: session.Schedule.SubjectID, Valid: true},
+		AbsentEmail: absence.TeacherEmail,
+		Date:        date,
+		Year:        session.Schedule.Year,
+		Term:        session.Schedule.Term,
+		DayOfWeek:   int16(slot.Day),
+		EndMinute:   slot.End,
+		StartMinute: slot.Start,
+	})
+}
+
+// loadAbsence fetches the absence named by the :id parameter, writing the
+// error response itself when it cannot.
+func (server *Server) loadAbsence(ctx *gin.Context, id int64) (db.TeacherAbsence, bool) {
+	absence, err := server.store.GetTeacherAbsence(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(http.StatusNotFound, errorResponse(fmt.Errorf("absence not found")))
+			return absence, false
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return absence, false
+	}
+	return absence, true
+}
+
+func (server *Server) createTeacherAbsence(ctx *gin.Context) {
+	var req createTeacherAbsenceRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	adminStatus, err := checkAdmin(ctx, "admin")
+	if err != nil {
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return
+	}
+	if !adminStatus {
+		ctx.JSON(http.StatusForbidden, errorResponse(fmt.Errorf("not authorized to record absences")))
+		return
+	}
+
+	start, err := parseDate("start_date", req.StartDate)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	end := start
+	if req.EndDate != "" {
+		if end, err = parseDate("end_date", req.EndDate); err != nil {
+			ctx.JSON(http.StatusBadRequest, errorResponse(err))
+			return
+		}
+	}
+	if end.Before(start) {
+		ctx.JSON(http.StatusBadRequest, errorResponse(fmt.Errorf("end_date is before start_date")))
+		return
+	}
+	if end.Sub(start) >= maxAbsenceDays*24*time.Hour {
+		ctx.JSON(http.StatusBadRequest, errorResponse(fmt.Errorf("an absence can span at most %d days", maxAbsenceDays)))
+		return
+	}
+
+	if _, err := server.store.GetTeacherByEmail(ctx, req.TeacherEmail); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(http.StatusNotFound, errorResponse(fmt.Errorf("teacher not found")))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	payload, err := payloadFromContext(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return
+	}
+	absence, err := server.store.CreateTeacherAbsence(ctx, db.CreateTeacherAbsenceParams{
+		TeacherEmail: req.TeacherEmail,
+		StartDate:    start,
+		EndDate:      end,
+		Reason:       StringToSQLNullString(req.Reason),
+		CreatedBy:    StringToSQLNullString(payload.Email),
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	res := newTeacherAbsenceResponse(absence)
+	if res.Sessions, err = server.absenceSessions(ctx, absence); err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (server *Server) listTeacherAbsences(ctx *gin.Context) {
+	var req listTeacherAbsencesRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	arg := db.ListTeacherAbsencesParams{
+		TeacherEmail: StringToSQLNullString(req.TeacherEmail),
+	}
+	if req.From != "" {
+		from, err := parseDate("from", req.From)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, errorResponse(err))
+			return
+		}
+		arg.FromDate = sql.NullTime{Time: from, Valid: true}
+	}
+	if req.To != "" {
+		to, err := parseDate("to", req.To)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, errorResponse(err))
+			return
+		}
+		arg.ToDate = sql.NullTime{Time: to, Valid: true}
+	}
+
+	absences, err := server.store.ListTeacherAbsences(ctx, arg)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	res := make([]teacherAbsenceResponse, 0, len(absences))
+	for _, a := range absences {
+		res = append(res, newTeacherAbsenceResponse(a))
+	}
+	ctx.JSON(http.StatusOK, res)
+}
+
+// getTeacherAbsence returns an absence with its affected sessions.
+func (server *Server) getTeacherAbsence(ctx *gin.Context) {
+	var uri getScheduleRequest
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	absence, ok := server.loadAbsence(ctx, uri.ID)
+	if !ok {
+		return
+	}
+
+	res := newTeacherAbsenceResponse(absence)
+	var err error
+	if res.Sessions, err = server.absenceSessions(ctx, absence); err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (server *Server) deleteTeacherAbsence(ctx *gin.Context) {
+	var uri getScheduleRequest
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	adminStatus, err := checkAdmin(ctx, "admin")
+	if err != nil {
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return
+	}
+	if !adminStatus {
+		ctx.JSON(http.StatusForbidden, errorResponse(fmt.Errorf("not authorized to record absences")))
+		return
+	}
+
+	if err := server.store.DeleteTeacherAbsence(ctx, uri.ID); err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"message": "Absence deleted successfully"})
+}
+
+// listSubstitutes suggests teachers of the same subject who are free for
+// one session of the absence.
+func (server *Server) listSubstitutes(ctx *gin.Context) {
+	var uri getScheduleRequest
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	var req listSubstitutesRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	absence, ok := server.loadAbsence(ctx, uri.ID)
+	if !ok {
+		return
+	}
+
+	session, err := server.absenceSession(ctx, absence, req.ScheduleID, req.Date)
+	if err != nil {
+		if errors.Is(err, errNoSession) {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	teachers, err := server.substitutes(ctx, absence, session)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	res := make([]addTeacherResponse, len(teachers))
+	for i, teacher := range teachers {
+		res[i] = TeacherToResponse(teacher)
+	}
+	ctx.JSON(http.StatusOK, res)
+}
+
+// assignCover records who covers a session, or that it is cancelled when
+// no substitute is given. Assigning again replaces the earlier cover.
+func (server *Server) assignCover(ctx *gin.Context) {
+	var uri getScheduleRequest
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	var req assignCoverRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	adminStatus, err := checkAdmin(ctx, "admin")
+	if err != nil {
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return
+	}
+	if !adminStatus {
+		ctx.JSON(http.StatusForbidden, errorResponse(fmt.Errorf("not authorized to assign covers")))
+		return
+	}
+
+	date, err := parseDate("date", req.Date)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	absence, ok := server.loadAbsence(ctx, uri.ID)
+	if !ok {
+		return
+	}
+	session, err := server.absenceSession(ctx, absence, req.ScheduleID, date.Format(dateLayout))
+	if err != nil {
+		if errors.Is(err, errNoSession) {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	// the substitute already covering this session shows up as busy, so
+	// keeping them is always allowed
+	if req.SubstituteEmail != "" && (session.Cover == nil || session.Cover.SubstituteEmail != req.SubstituteEmail) {
+		teachers, err := server.substitutes(ctx, absence, session)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+			return
+		}
+		qualified := false
+		for _, t := range teachers {
+			if t.Email == req.SubstituteEmail {
+				qualified = true
+				break
+			}
+		}
+		if !qualified {
+			ctx.JSON(http.StatusConflict, errorResponse(fmt.Errorf("%s does not teach this subject or is not free at %s on %s", req.SubstituteEmail, session.Schedule.TimeSlot, req.Date)))
+			return
+		}
+	}
+
+	payload, err := payloadFromContext(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return
+	}
+	override, err := server.store.UpsertScheduleOverride(ctx, db.UpsertScheduleOverrideParams{
+		AbsenceID:       absence.ID,
+		ScheduleID:      req.ScheduleID,
+		Date:            date,
+		SubstituteEmail: StringToSQLNullString(req.SubstituteEmail),
+		Note:            StringToSQLNullString(req.Note),
+		CreatedBy:       StringToSQLNullString(payload.Email),
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	var name sql.NullString
+	if override.SubstituteEmail.Valid {
+		if teacher, err := server.store.GetTeacherByEmail(ctx, override.SubstituteEmail.String); err == nil {
+			name = teacher.Name
+		}
+	}
+	ctx.JSON(http.StatusOK, newCoverResponse(override, name))
+}
+
+func (server *Server) deleteCover(ctx *gin.Context) {
+	var uri deleteCoverRequest
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	adminStatus, err := checkAdmin(ctx, "admin")
+	if err != nil {
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return
+	}
+	if !adminStatus {
+		ctx.JSON(http.StatusForbidden, errorResponse(fmt.Errorf("not authorized to assign covers")))
+		return
+	}
+
+	if err := server.store.DeleteScheduleOverride(ctx, db.DeleteScheduleOverrideParams{
+		ID:        uri.CoverID,
+		AbsenceID: uri.ID,
+	}); err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"message": "Cover removed successfully"})
+}
+
+// overlayCovers narrows a routine to the weekday of date and marks the
+// sessions covered or cancelled on that date. For a teacher's routine the
+// sessions they cover for others are added.
+func (server *Server) overlayCovers(ctx *gin.Context, schedules []detailedScheduleResponse, date time.Time, teacherEmail string) ([]detailedScheduleResponse, error) {
+	overrides, err := server.store.ListScheduleOverridesOnDate(ctx, date)
+	if err != nil {
+		return nil, err
+	}
+	covers := make(map[int64]*coverResponse, len(overrides))
+	for _, o := range overrides {
+		covers[o.ScheduleID] = newCoverResponseFromRow(db.ListScheduleOverridesByAbsenceRow(o))
+	}
+
+	day := timeslot.Day(date.Weekday())
+	res := make([]detailedScheduleResponse, 0, len(schedules))
+	for _, s := range schedules {
+		slot, err := timeslot.Parse(s.TimeSlot)
+		if err != nil || slot.Day != day {
+			continue
+		}
+		s.Cover = covers[s.ID]
+		res = append(res, s)
+	}
+
+	if teacherEmail != "" {
+		rows, err := server.store.GetCoversBySubstitute(ctx, db.GetCoversBySubstituteParams{
+			SubstituteEmail: StringToSQLNullString(teacherEmail),
+			Date:            date,
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, row := range rows {
+			s := newDetailedScheduleByTeacherResponse(db.GetSchedulesByTeacherRow(row))
+			s.Cover = covers[s.ID]
+			res = append(res, s)
+		}
+	}
+	return res, nil
+}
@@ -0,0 +1,357 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lib/pq"
+	"github.com/nirajan1111/routiney/bs"
+	db "github.com/nirajan1111/routiney/db/sqlc"
+)
+
+type workloadLimitRequest struct {
+	// Exactly one of TeacherEmail and Designation is set on create; both are
+	// ignored on update.
+	TeacherEmail string `json:"teacher_email" binding:"omitempty,email"`
+	Designation  string `json:"designation" binding:"max=20"`
+	MinPeriods   int32  `json:"min_periods" binding:"min=0"`
+	MaxPeriods   *int32 `json:"max_periods" binding:"omitempty,min=0"`
+}
+
+type workloadLimitResponse struct {
+	ID           int64     `json:"id"`
+	TeacherEmail string    `json:"teacher_email,omitempty"`
+	Designation  string    `json:"designation,omitempty"`
+	MinPeriods   int32     `json:"min_periods"`
+	MaxPeriods   *int32    `json:"max_periods"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+func newWorkloadLimitResponse(l db.WorkloadLimit) workloadLimitResponse {
+	return workloadLimitResponse{
+		ID:           l.ID,
+		TeacherEmail: l.TeacherEmail.String,
+		Designation:  l.Designation.String,
+		MinPeriods:   l.MinPeriods,
+		MaxPeriods:   nullInt32Ptr(l.MaxPeriods),
+		UpdatedAt:    l.UpdatedAt,
+	}
+}
+
+func nullInt32Ptr(n sql.NullInt32) *int32 {
+	if !n.Valid {
+		return nil
+	}
+	return &n.Int32
+}
+
+func (req workloadLimitRequest) maxPeriods() (sql.NullInt32, error) {
+	if req.MaxPeriods == nil {
+		return sql.NullInt32{}, nil
+	}
+	if *req.MaxPeriods < req.MinPeriods {
+		return sql.NullInt32{}, fmt.Errorf("max_periods must not be less than min_periods")
+	}
+	return sql.NullInt32{Int32: *req.MaxPeriods, Valid: true}, nil
+}
+
+func isUniqueViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "23505"
+}
+
+func (server *Server) listWorkloadLimits(ctx *gin.Context) {
+	limits, err := server.store.ListWorkloadLimits(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	res := make([]workloadLimitResponse, 0, len(limits))
+	for _, l := range limits {
+		res = append(res, newWorkloadLimitResponse(l))
+	}
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (server *Server) createWorkloadLimit(ctx *gin.Context) {
+	var req workloadLimitRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	adminStatus, err := checkAdmin(ctx, "admin")
+	if err != nil {
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return
+	}
+	if !adminStatus {
+		ctx.JSON(http.StatusForbidden, errorResponse(fmt.Errorf("not authorized to set workload limits")))
+		return
+	}
+
+	if (req.TeacherEmail == "") == (req.Designation == "") {
+		ctx.JSON(http.StatusBadRequest, errorResponse(fmt.Errorf("exactly one of teacher_email and designation is required")))
+		return
+	}
+	maxPeriods, err := req.maxPeriods()
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	if req.TeacherEmail != "" {
+		if _, err := server.store.GetTeacherByEmail(ctx, req.TeacherEmail); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				ctx.JSON(http.StatusNotFound, errorResponse(fmt.Errorf("teacher not found")))
+				return
+			}
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+			return
+		}
+	}
+
+	limit, err := server.store.CreateWorkloadLimit(ctx, db.CreateWorkloadLimitParams{
+		TeacherEmail: StringToSQLNullString(req.TeacherEmail),
+		Designation:  StringToSQLNullString(req.Designation),
+		MinPeriods:   req.MinPeriods,
+		MaxPeriods:   maxPeriods,
+	})
+	if err != nil {
+		if isUniqueViolation(err) {
+			ctx.JSON(http.StatusConflict, errorResponse(fmt.Errorf("a workload limit for %s already exists", req.TeacherEmail+req.Designation)))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	ctx.JSON(http.StatusOK, newWorkloadLimitResponse(limit))
+}
+
+func (server *Server) updateWorkloadLimit(ctx *gin.Context) {
+	var uri getScheduleRequest
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	var req workloadLimitRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	adminStatus, err := checkAdmin(ctx, "admin")
+	if err != nil {
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return
+	}
+	if !adminStatus {
+		ctx.JSON(http.StatusForbidden, errorResponse(fmt.Errorf("not authorized to set workload limits")))
+		return
+	}
+
+	maxPeriods, err := req.maxPeriods()
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	limit, err := server.store.UpdateWorkloadLimit(ctx, db.UpdateWorkloadLimitParams{
+		ID:         uri.ID,
+		MinPeriods: req.MinPeriods,
+		MaxPeriods: maxPeriods,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(http.StatusNotFound, errorResponse(fmt.Errorf("workload limit not found")))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	ctx.JSON(http.StatusOK, newWorkloadLimitResponse(limit))
+}
+
+func (server *Server) deleteWorkloadLimit(ctx *gin.Context) {
+	var uri getScheduleRequest
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	adminStatus, err := checkAdmin(ctx, "admin")
+	if err != nil {
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return
+	}
+	if !adminStatus {
+		ctx.JSON(http.StatusForbidden, errorResponse(fmt.Errorf("not authorized to set workload limits")))
+		return
+	}
+
+	n, err := server.store.DeleteWorkloadLimit(ctx, uri.ID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	if n == 0 {
+		ctx.JSON(http.StatusNotFound, errorResponse(fmt.Errorf("workload limit not found")))
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"message": "Workload limit deleted successfully"})
+}
+
+type workloadReportRequest struct {
+	Year       int32  `form:"year"`
+	Term       int16  `form:"term" binding:"omitempty,min=1"`
+	Department string `form:"department"`
+	Sort       string `form:"sort" binding:"omitempty,oneof=name department designation periods hours"`
+	Order      string `form:"order" binding:"omitempty,oneof=asc desc"`
+	Format     string `form:"format" binding:"omitempty,oneof=json csv"`
+}
+
+// workload statuses compare a teacher's periods with their limit.
+const (
+	workloadUnder = "under"
+	workloadOK    = "ok"
+	workloadOver  = "over"
+)
+
+type workloadResponse struct {
+	Email       string   `json:"email"`
+	Name        string   `json:"name"`
+	Department  string   `json:"department,omitempty"`
+	Designation string   `json:"designation,omitempty"`
+	Periods     int64    `json:"periods"`
+	Hours       float64  `json:"hours"`
+	Subjects    []string `json:"subjects"`
+	Sections    []string `json:"sections"`
+	MinPeriods  *int32   `json:"min_periods"`
+	MaxPeriods  *int32   `json:"max_periods"`
+	Status      string   `json:"status"`
+}
+
+func newWorkloadResponse(row db.ListTeacherWorkloadsRow) workloadResponse {
+	w := workloadResponse{
+		Email:       row.Email,
+		Name:        row.Name.String,
+		Department:  row.Department.String,
+		Designation: row.Designation.String,
+		Periods:     row.Periods,
+		Hours:       float64(row.Minutes) / 60,
+		Subjects:    row.Subjects,
+		Sections:    row.Sections,
+		MinPeriods:  nullInt32Ptr(row.MinPeriods),
+		MaxPeriods:  nullInt32Ptr(row.MaxPeriods),
+		Status:      workloadOK,
+	}
+	switch {
+	case row.MaxPeriods.Valid && row.Periods > int64(row.MaxPeriods.Int32):
+		w.Status = workloadOver
+	case row.MinPeriods.Valid && row.Periods < int64(row.MinPeriods.Int32):
+		w.Status = workloadUnder
+	}
+	return w
+}
+
+func sortWorkloads(rows []workloadResponse, by string, desc bool) {
+	less := func(a, b workloadResponse) bool {
+		switch by {
+		case "department":
+			return a.Department < b.Department
+		case "designation":
+			return a.Designation < b.Designation
+		case "periods":
+			return a.Periods < b.Periods
+		case "hours":
+			return a.Hours < b.Hours
+		}
+		return strings.ToLower(a.Name) < strings.ToLower(b.Name)
+	}
+	sort.SliceStable(rows, func(i, j int) bool {
+		if desc {
+			return less(rows[j], rows[i])
+		}
+		return less(rows[i], rows[j])
+	})
+}
+
+// getWorkloadReport lists every teacher's weekly load in a term, with the
+// subjects and sections they teach and how it compares to their limit.
+func (server *Server) getWorkloadReport(ctx *gin.Context) {
+	var req workloadReportRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	if req.Year == 0 {
+		req.Year = int32(bs.CurrentYear())
+	}
+	if req.Term == 0 {
+		term, err := server.termOn(ctx, req.Year, time.Time{})
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+			return
+		}
+		req.Term = term
+	}
+
+	rows, err := server.store.ListTeacherWorkloads(ctx, db.ListTeacherWorkloadsParams{
+		Year:       req.Year,
+		Term:       req.Term,
+		Department: StringToSQLNullString(req.Department),
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	res := make([]workloadResponse, 0, len(rows))
+	for _, row := range rows {
+		res = append(res, newWorkloadResponse(row))
+	}
+	sortWorkloads(res, req.Sort, req.Order == "desc")
+
+	if req.Format == "csv" {
+		writeWorkloadCSV(ctx, fmt.Sprintf("workload-%d-%d.csv", req.Year, req.Term), res)
+		return
+	}
+	ctx.JSON(http.StatusOK, res)
+}
+
+func writeWorkloadCSV(ctx *gin.Context, filename string, rows []workloadResponse) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	w.Write([]string{"email", "name", "department", "designation", "periods", "hours", "subjects", "sections", "min_periods", "max_periods", "status"})
+	limit := func(n *int32) string {
+		if n == nil {
+			return ""
+		}
+		return strconv.Itoa(int(*n))
+	}
+	for _, r := range rows {
+		w.Write([]string{
+			r.Email,
+			r.Name,
+			r.Department,
+			r.Designation,
+			strconv.FormatInt(r.Periods, 10),
+			strconv.FormatFloat(r.Hours, 'f', 2, 64),
+			strings.Join(r.Subjects, "; "),
+			strings.Join(r.Sections, "; "),
+			limit(r.MinPeriods),
+			limit(r.MaxPeriods),
+			r.Status,
+		})
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	ctx.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+	ctx.Data(http.StatusOK, "text/csv; charset=utf-8", buf.Bytes())
+}
@@ -0,0 +1,263 @@
+// Package bs converts between Bikram Sambat (BS), the official calendar of
+// Nepal, and Gregorian (AD) dates.
+//
+// BS month lengths vary from year to year, so conversion goes through a
+// lookup table covering MinYear to MaxYear. AD dates are handled as UTC
+// midnights, the same way the API stores plain dates; Today and CurrentYear
+// use Nepal time.
+package bs
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// The range of years the lookup table covers.
+const (
+	MinYear = 2000
+	MaxYear = MinYear + len(monthDays) - 1
+)
+
+// ErrOutOfRange is returned for dates outside the lookup table.
+var ErrOutOfRange = errors.New("date outside the supported Bikram Sambat range")
+
+// nepal is Nepal Standard Time, which has no daylight saving.
+var nepal = time.FixedZone("NPT", 5*60*60+45*60)
+
+var monthNames = [12]string{
+	"Baisakh", "Jestha", "Ashadh", "Shrawan", "Bhadra", "Ashwin",
+	"Kartik", "Mangsir", "Poush", "Magh", "Falgun", "Chaitra",
+}
+
+var devanagariMonthNames = [12]string{
+	"बैशाख", "जेठ", "असार", "साउन", "भदौ", "असोज",
+	"कात्तिक", "मंसिर", "पुस", "माघ", "फागुन", "चैत",
+}
+
+const devanagariDigits = "०१२३४५६७८९"
+
+// Date is a day of the Bikram Sambat calendar. Month 1 is Baisakh.
+type Date struct {
+	Year  int
+	Month int
+	Day   int
+}
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+// DaysInMonth returns the number of days in a month of year.
+func DaysInMonth(year, month int) (int, error) {
+	if year < MinYear || year > MaxYear {
+		return 0, ErrOutOfRange
+	}
+	if month < 1 || month > 12 {
+		return 0, fmt.Errorf("invalid month %d", month)
+	}
+	return monthDays[year-MinYear][month-1], nil
+}
+
+func daysInYear(year int) int {
+	n := 0
+	for _, d := range monthDays[year-MinYear] {
+		n += d
+	}
+	return n
+}
+
+// New returns the date year-month-day, checking that it exists.
+func New(year, month, day int) (Date, error) {
+	n, err := DaysInMonth(year, month)
+	if err != nil {
+		return Date{}, err
+	}
+	if day < 1 || day > n {
+		return Date{}, fmt.Errorf("%s %d has %d days", monthNames[month-1], year, n)
+	}
+	return Date{Year: year, Month: month, Day: day}, nil
+}
+
+// Parse reads a date written as YYYY-MM-DD (or with slashes), in Latin or
+// Devanagari digits.
+func Parse(s string) (Date, error) {
+	parts := strings.FieldsFunc(FromDevanagari(strings.TrimSpace(s)), func(r rune) bool { return r == '-' || r == '/' })
+	if len(parts) != 3 {
+		return Date{}, fmt.Errorf("invalid BS date %q, expected YYYY-MM-DD", s)
+	}
+	var n [3]int
+	for i, p := range parts {
+		v, err := strconv.Atoi(p)
+		if err != nil {
+			return Date{}, fmt.Errorf("invalid BS date %q, expected YYYY-MM-DD", s)
+		}
+		n[i] = v
+	}
+	return New(n[0], n[1], n[2])
+}
+
+// FromAD converts the calendar date of t, in t's location, to BS.
+func FromAD(t time.Time) (Date, error) {
+	days := int(date(t.Year(), t.Month(), t.Day()).Sub(epoch).Hours() / 24)
+	if days < 0 {
+		return Date{}, ErrOutOfRange
+	}
+	for year := MinYear; year <= MaxYear; year++ {
+		if n := daysInYear(year); days >= n {
+			days -= n
+			continue
+		}
+		for month, n := range monthDays[year-MinYear] {
+			if days < n {
+				return Date{Year: year, Month: month + 1, Day: days + 1}, nil
+			}
+			days -= n
+		}
+	}
+	return Date{}, ErrOutOfRange
+}
+
+// AD returns the Gregorian date of d at midnight UTC.
+func (d Date) AD() (time.Time, error) {
+	if _, err := New(d.Year, d.Month, d.Day); err != nil {
+		return time.Time{}, err
+	}
+	days := d.Day - 1
+	for year := MinYear; year < d.Year; year++ {
+		days += daysInYear(year)
+	}
+	for month := 1; month < d.Month; month++ {
+		days += monthDays[d.Year-MinYear][month-1]
+	}
+	return epoch.AddDate(0, 0, days), nil
+}
+
+// String formats d as YYYY-MM-DD.
+func (d Date) String() string {
+	return fmt.Sprintf("%04d-%02d-%02d", d.Year, d.Month, d.Day)
+}
+
+// Devanagari formats d as YYYY-MM-DD in Devanagari digits, e.g.
+// "२०८१-०१-१५".
+func (d Date) Devanagari() string {
+	return ToDevanagari(d.String())
+}
+
+// Long formats d with the month's name, e.g. "15 Baisakh 2081".
+func (d Date) Long() string {
+	return fmt.Sprintf("%d %s %d", d.Day, MonthName(d.Month), d.Year)
+}
+
+// LongDevanagari formats d the way it is written in Nepali, e.g.
+// "२०८१ बैशाख १५".
+func (d Date) LongDevanagari() string {
+	name := ""
+	if d.Month >= 1 && d.Month <= 12 {
+		name = devanagariMonthNames[d.Month-1]
+	}
+	return ToDevanagari(strconv.Itoa(d.Year)) + " " + name + " " + ToDevanagari(strconv.Itoa(d.Day))
+}
+
+// Term returns the half of the year d falls in: 1 from Baisakh to Ashwin and
+// 2 from Kartik to Chaitra. It is the default split into terms when a year's
+// terms have not been set up.
+func (d Date) Term() int {
+	if d.Month <= 6 {
+		return 1
+	}
+	return 2
+}
+
+// MonthName returns the English name of a BS month, "Baisakh" for 1.
+func MonthName(month int) string {
+	if month < 1 || month > 12 {
+		return fmt.Sprintf("Month(%d)", month)
+	}
+	return monthNames[month-1]
+}
+
+// ToDevanagari replaces the Latin digits in s with Devanagari ones.
+func ToDevanagari(s string) string {
+	digits := []rune(devanagariDigits)
+	var b strings.Builder
+	for _, r := range s {
+		if r >= '0' && r <= '9' {
+			r = digits[r-'0']
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// FromDevanagari replaces the Devanagari digits in s with Latin ones.
+func FromDevanagari(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if r >= '०' && r <= '९' {
+			r = '0' + (r - '०')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// YearOf returns the BS year t falls in. Outside the lookup table it is
+// estimated from a new year on April 14.
+func YearOf(t time.Time) int {
+	if d, err := FromAD(t); err == nil {
+		return d.Year
+	}
+	if t.Month() < time.April || (t.Month() == time.April && t.Day() < 14) {
+		return t.Year() + 56
+	}
+	return t.Year() + 57
+}
+
+// YearBounds returns the AD dates of Baisakh 1 and the last day of Chaitra
+// of year. Outside the lookup table they are estimated from a new year on
+// April 14.
+func YearBounds(year int) (time.Time, time.Time) {
+	if year < MinYear || year > MaxYear {
+		return date(year-57, time.April, 14), date(year-56, time.April, 13)
+	}
+	start, _ := Date{Year: year, Month: 1, Day: 1}.AD()
+	return start, start.AddDate(0, 0, daysInYear(year)-1)
+}
+
+// TermBounds returns the AD dates a default term of year runs between, the
+// halves Date.Term splits the year into: Baisakh 1 to the end of Ashwin
+// for term 1 and Kartik 1 to the end of Chaitra for term 2. Other terms,
+// and years outside the lookup table, get the whole year.
+func TermBounds(year, term int) (time.Time, time.Time) {
+	start, end := YearBounds(year)
+	if year < MinYear || year > MaxYear || (term != 1 && term != 2) {
+		return start, end
+	}
+	kartik, _ := Date{Year: year, Month: 7, Day: 1}.AD()
+	if term == 1 {
+		return start, kartik.AddDate(0, 0, -1)
+	}
+	return kartik, end
+}
+
+// Today returns today's date in Nepal.
+func Today() (Date, error) {
+	return FromAD(time.Now().In(nepal))
+}
+
+// CurrentYear returns the BS year it is in Nepal.
+func CurrentYear() int {
+	return YearOf(time.Now().In(nepal))
+}
+
+// CurrentTerm returns the default term it is in Nepal; see Date.Term.
+func CurrentTerm() int {
+	d, err := Today()
+	if err != nil {
+		return 1
+	}
+	return d.Term()
+}
@@ -0,0 +1,111 @@
+package bs
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFromAD(t *testing.T) {
+	for _, c := range []struct {
+		ad time.Time
+		bs string
+	}{
+		{date(1943, time.April, 14), "2000-01-01"},
+		{date(2015, time.September, 20), "2072-06-03"}, // the constitution was promulgated
+		{date(2016, time.April, 13), "2073-01-01"},
+		{date(2024, time.April, 12), "2080-12-30"},
+		{date(2024, time.April, 13), "2081-01-01"},
+		{date(2025, time.April, 14), "2082-01-01"},
+		{date(2025, time.October, 2), "2082-06-16"},
+	} {
+		d, err := FromAD(c.ad)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if d.String() != c.bs {
+			t.Errorf("FromAD(%s) = %s, want %s", c.ad.Format("2006-01-02"), d, c.bs)
+		}
+		ad, err := d.AD()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !ad.Equal(c.ad) {
+			t.Errorf("%s.AD() = %s, want %s", d, ad.Format("2006-01-02"), c.ad.Format("2006-01-02"))
+		}
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	start, _ := YearBounds(MinYear)
+	_, end := YearBounds(MaxYear)
+	prev := Date{}
+	for ad := start; !ad.After(end); ad = ad.AddDate(0, 0, 1) {
+		d, err := FromAD(ad)
+		if err != nil {
+			t.Fatalf("FromAD(%s): %v", ad.Format("2006-01-02"), err)
+		}
+		if back, _ := d.AD(); !back.Equal(ad) {
+			t.Fatalf("%s converts back to %s", ad.Format("2006-01-02"), back.Format("2006-01-02"))
+		}
+		if prev.Year != 0 && d.Day != prev.Day+1 && d.Day != 1 {
+			t.Fatalf("%s follows %s", d, prev)
+		}
+		prev = d
+	}
+	if _, err := FromAD(end.AddDate(0, 0, 1)); err != ErrOutOfRange {
+		t.Errorf("expected ErrOutOfRange after %s, got %v", end.Format("2006-01-02"), err)
+	}
+	if _, err := FromAD(start.AddDate(0, 0, -1)); err != ErrOutOfRange {
+		t.Errorf("expected ErrOutOfRange before %s, got %v", start.Format("2006-01-02"), err)
+	}
+}
+
+func TestFormatAndParse(t *testing.T) {
+	d := Date{Year: 2081, Month: 1, Day: 15}
+	if got := d.Devanagari(); got != "२०८१-०१-१५" {
+		t.Errorf("Devanagari() = %s", got)
+	}
+	if got := d.LongDevanagari(); got != "२०८१ बैशाख १५" {
+		t.Errorf("LongDevanagari() = %s", got)
+	}
+	if got := d.Long(); got != "15 Baisakh 2081" {
+		t.Errorf("Long() = %s", got)
+	}
+	for _, s := range []string{"2081-01-15", "2081/1/15", "२०८१-०१-१५"} {
+		if p, err := Parse(s); err != nil || p != d {
+			t.Errorf("Parse(%q) = %v, %v", s, p, err)
+		}
+	}
+	for _, s := range []string{"2081-13-01", "2081-01-32", "1990-01-01", "2081-01"} {
+		if _, err := Parse(s); err == nil {
+			t.Errorf("expected Parse(%q) to fail", s)
+		}
+	}
+}
+
+func TestYearOf(t *testing.T) {
+	if y := YearOf(date(2024, time.April, 13)); y != 2081 {
+		t.Errorf("YearOf(2024-04-13) = %d, want 2081", y)
+	}
+	if y := YearOf(date(2040, time.May, 1)); y != 2097 {
+		t.Errorf("YearOf outside the table = %d, want 2097", y)
+	}
+	start, end := YearBounds(2081)
+	if start != date(2024, time.April, 13) || end != date(2025, time.April, 13) {
+		t.Errorf("YearBounds(2081) = %s, %s", start.Format("2006-01-02"), end.Format("2006-01-02"))
+	}
+}
+
+func TestTermBounds(t *testing.T) {
+	start, end := TermBounds(2081, 1)
+	if start != date(2024, time.April, 13) || end != date(2024, time.October, 16) {
+		t.Errorf("TermBounds(2081, 1) = %s, %s", start.Format("2006-01-02"), end.Format("2006-01-02"))
+	}
+	start, end = TermBounds(2081, 2)
+	if start != date(2024, time.October, 17) || end != date(2025, time.April, 13) {
+		t.Errorf("TermBounds(2081, 2) = %s, %s", start.Format("2006-01-02"), end.Format("2006-01-02"))
+	}
+	if s, e := TermBounds(2081, 3); s != date(2024, time.April, 13) || e != date(2025, time.April, 13) {
+		t.Errorf("TermBounds(2081, 3) = %s, %s, want the whole year", s.Format("2006-01-02"), e.Format("2006-01-02"))
+	}
+}
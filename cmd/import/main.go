@@ -0,0 +1,96 @@
+// Command import loads a CSV or XLSX sheet of rooms, teachers, subjects,
+// student sections or schedules into the database configured by the same
+// environment (or .env file) as the server.
+//
+//	go run ./cmd/import -entity schedules -file routine.xlsx -dry-run
+//
+// The report is printed as JSON. The exit status is 1 when any row was
+// rejected, in which case nothing was written.
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"flag"
+	"log"
+	"net/url"
+	"os"
+
+	"github.com/joho/godotenv"
+	_ "github.com/lib/pq"
+	"github.com/nirajan1111/routiney/bs"
+	db "github.com/nirajan1111/routiney/db/sqlc"
+	"github.com/nirajan1111/routiney/importer"
+)
+
+func main() {
+	entityName := flag.String("entity", "", "room, teacher, subject, student_section or schedules")
+	path := flag.String("file", "", "path to a .csv or .xlsx file")
+	dryRun := flag.Bool("dry-run", false, "validate and report without writing anything")
+	year := flag.Int("year", 0, "routine year for schedule rows without a year column (default: current Nepali year)")
+	term := flag.Int("term", 1, "term of the year for schedule rows without a term column")
+	flag.Parse()
+
+	if *entityName == "" || *path == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+	entity, err := importer.ParseEntity(*entityName)
+	if err != nil {
+		log.Fatal(err)
+	}
+	format, err := importer.FormatFromName(*path)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	f, err := os.Open(*path)
+	if err != nil {
+		log.Fatal(err)
+	}
+	table, err := importer.Read(f, format)
+	f.Close()
+	if err != nil {
+		log.Fatal("cannot read sheet:", err)
+	}
+
+	if err := godotenv.Load(); err != nil {
+		log.Println("Warning: Error loading .env file:", err)
+	}
+	dbDriver := os.Getenv("DB_DRIVER")
+	if dbDriver == "" {
+		dbDriver = "postgres"
+	}
+	connURL, err := url.Parse(os.Getenv("DB_SOURCE"))
+	if err != nil {
+		log.Fatal("invalid DB_SOURCE:", err)
+	}
+	connURL.RawQuery = "sslmode=require"
+	conn, err := sql.Open(dbDriver, connURL.String())
+	if err != nil {
+		log.Fatal("cannot connect to db:", err)
+	}
+	defer conn.Close()
+
+	if *year == 0 {
+		*year = bs.CurrentYear()
+	}
+	report, err := importer.Run(context.Background(), db.NewStore(conn), entity, table, importer.Options{
+		DryRun: *dryRun,
+		Year:   int32(*year),
+		Term:   int16(*term),
+	})
+	if err != nil {
+		log.Fatal("import failed:", err)
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(report); err != nil {
+		log.Fatal(err)
+	}
+	if len(report.Errors) > 0 {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,112 @@
+// Package curriculum compares what a section is scheduled for against what
+// its program's curriculum asks for in the semester it is in.
+package curriculum
+
+import "sort"
+
+// Status tells how a section's scheduled periods compare with a requirement.
+type Status string
+
+const (
+	OK    Status = "ok"
+	Under Status = "under"
+	Over  Status = "over"
+)
+
+// Key identifies a line of the comparison.
+type Key struct {
+	SubjectID   int64
+	SessionType string
+}
+
+// Line is the comparison for one subject and session type. Scheduled
+// sessions without any requirement show up with Required zero.
+type Line struct {
+	Key
+	Required  int
+	Scheduled int
+	Status    Status
+}
+
+// Session is the periods a section is scheduled for under one key, for
+// the whole section or, when SubGroupID is set, one of its sub-groups.
+type Session struct {
+	Key
+	SubGroupID int64
+	Periods    int
+}
+
+// Scheduled adds up the periods each student of a section attends per
+// key. Sub-groups take their sessions in parallel and a student is in at
+// most one of them, so the whole-section periods count along with those of
+// the sub-group that has the most, not the sum over all sub-groups.
+func Scheduled(sessions []Session) map[Key]int {
+	whole := map[Key]int{}
+	most := map[Key]int{}
+	for _, s := range sessions {
+		if s.SubGroupID == 0 {
+			whole[s.Key] += s.Periods
+		} else if s.Periods > most[s.Key] {
+			most[s.Key] = s.Periods
+		}
+	}
+	for k, n := range most {
+		whole[k] += n
+	}
+	return whole
+}
+
+// SemesterOf returns the semester a section enrolled in yearEnrolled is in
+// during part (1 or 2) of year, or 0 when that is before it enrolled. Year
+// of study is counted the same way as for routine generation: the year of
+// enrolment is the first.
+func SemesterOf(yearEnrolled, year int32, part int) int32 {
+	yearOfStudy := year - yearEnrolled + 1
+	if yearEnrolled == 0 || yearOfStudy < 1 || part < 1 || part > 2 {
+		return 0
+	}
+	return 2*(yearOfStudy-1) + int32(part)
+}
+
+// Compare lines up required and scheduled periods per key, ordered by
+// subject and session type.
+func Compare(required, scheduled map[Key]int) []Line {
+	keys := make([]Key, 0, len(required)+len(scheduled))
+	for k := range required {
+		keys = append(keys, k)
+	}
+	for k := range scheduled {
+		if _, ok := required[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].SubjectID != keys[j].SubjectID {
+			return keys[i].SubjectID < keys[j].SubjectID
+		}
+		return keys[i].SessionType < keys[j].SessionType
+	})
+
+	lines := make([]Line, 0, len(keys))
+	for _, k := range keys {
+		l := Line{Key: k, Required: required[k], Scheduled: scheduled[k], Status: OK}
+		switch {
+		case l.Scheduled < l.Required:
+			l.Status = Under
+		case l.Scheduled > l.Required:
+			l.Status = Over
+		}
+		lines = append(lines, l)
+	}
+	return lines
+}
+
+// Complete reports whether every line is OK.
+func Complete(lines []Line) bool {
+	for _, l := range lines {
+		if l.Status != OK {
+			return false
+		}
+	}
+	return true
+}
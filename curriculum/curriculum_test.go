@@ -0,0 +1,78 @@
+package curriculum
+
+import "testing"
+
+func TestSemesterOf(t *testing.T) {
+	tests := []struct {
+		enrolled, year int32
+		part           int
+		want           int32
+	}{
+		{2080, 2080, 1, 1},
+		{2080, 2080, 2, 2},
+		{2080, 2082, 1, 5},
+		{2081, 2080, 1, 0},
+		{0, 2080, 1, 0},
+		{2080, 2081, 3, 0},
+	}
+	for _, tt := range tests {
+		if got := SemesterOf(tt.enrolled, tt.year, tt.part); got != tt.want {
+			t.Errorf("SemesterOf(%d, %d, %d) = %d, want %d", tt.enrolled, tt.year, tt.part, got, tt.want)
+		}
+	}
+}
+
+func TestCompare(t *testing.T) {
+	required := map[Key]int{
+		{SubjectID: 1, SessionType: "lecture"}: 3,
+		{SubjectID: 1, SessionType: "lab"}:     1,
+		{SubjectID: 2, SessionType: "lecture"}: 2,
+	}
+	scheduled := map[Key]int{
+		{SubjectID: 1, SessionType: "lecture"}: 3,
+		{SubjectID: 2, SessionType: "lecture"}: 1,
+		{SubjectID: 3, SessionType: "lecture"}: 2,
+	}
+
+	lines := Compare(required, scheduled)
+	want := []Line{
+		{Key: Key{1, "lab"}, Required: 1, Scheduled: 0, Status: Under},
+		{Key: Key{1, "lecture"}, Required: 3, Scheduled: 3, Status: OK},
+		{Key: Key{2, "lecture"}, Required: 2, Scheduled: 1, Status: Under},
+		{Key: Key{3, "lecture"}, Required: 0, Scheduled: 2, Status: Over},
+	}
+	if len(lines) != len(want) {
+		t.Fatalf("got %+v, want %+v", lines, want)
+	}
+	for i := range want {
+		if lines[i] != want[i] {
+			t.Errorf("line %d = %+v, want %+v", i, lines[i], want[i])
+		}
+	}
+	if Complete(lines) {
+		t.Error("expected the section to be incomplete")
+	}
+	if !Complete(lines[1:2]) {
+		t.Error("expected a matching line to be complete")
+	}
+}
+
+func TestCompareParallelSubGroups(t *testing.T) {
+	lab := Key{SubjectID: 1, SessionType: "lab"}
+	lecture := Key{SubjectID: 1, SessionType: "lecture"}
+	required := map[Key]int{lab: 1, lecture: 3}
+	// the section is split in halves that take the same lab in parallel,
+	// and each half has its third lecture period on its own
+	scheduled := Scheduled([]Session{
+		{Key: lab, SubGroupID: 7, Periods: 1},
+		{Key: lab, SubGroupID: 8, Periods: 1},
+		{Key: lecture, Periods: 2},
+		{Key: lecture, SubGroupID: 7, Periods: 1},
+		{Key: lecture, SubGroupID: 8, Periods: 1},
+	})
+
+	lines := Compare(required, scheduled)
+	if !Complete(lines) {
+		t.Errorf("got %+v, want both lines to meet the curriculum", lines)
+	}
+}
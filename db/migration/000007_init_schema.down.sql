@@ -0,0 +1,14 @@
+DROP INDEX IF EXISTS idx_schedules_year_day;
+
+ALTER TABLE schedules DROP CONSTRAINT IF EXISTS exclude_room_overlap;
+ALTER TABLE schedules DROP CONSTRAINT IF EXISTS exclude_teacher_overlap;
+ALTER TABLE schedules DROP CONSTRAINT IF EXISTS exclude_group_overlap;
+
+ALTER TABLE schedules ADD CONSTRAINT unique_room_timeslot UNIQUE (room_id, time_slot, year);
+ALTER TABLE schedules ADD CONSTRAINT unique_teacher_timeslot UNIQUE (teacher_email, time_slot, year);
+ALTER TABLE schedules ADD CONSTRAINT unique_group_timeslot UNIQUE (group_id, time_slot, year);
+
+ALTER TABLE schedules
+  DROP COLUMN IF EXISTS day_of_week,
+  DROP COLUMN IF EXISTS start_minute,
+  DROP COLUMN IF EXISTS end_minute;
@@ -0,0 +1,32 @@
+CREATE EXTENSION IF NOT EXISTS btree_gist;
+
+ALTER TABLE schedules
+  ADD COLUMN day_of_week SMALLINT,
+  ADD COLUMN start_minute INT4,
+  ADD COLUMN end_minute INT4;
+
+-- time_slot is stored as DAY-HH:MM-HH:MM, e.g. SUN-16:15-17:55
+UPDATE schedules SET
+  day_of_week = array_position(ARRAY['SUN','MON','TUE','WED','THU','FRI','SAT'], upper(split_part(time_slot, '-', 1))) - 1,
+  start_minute = split_part(split_part(time_slot, '-', 2), ':', 1)::int * 60 + split_part(split_part(time_slot, '-', 2), ':', 2)::int,
+  end_minute = split_part(split_part(time_slot, '-', 3), ':', 1)::int * 60 + split_part(split_part(time_slot, '-', 3), ':', 2)::int;
+
+ALTER TABLE schedules
+  ALTER COLUMN day_of_week SET NOT NULL,
+  ALTER COLUMN start_minute SET NOT NULL,
+  ALTER COLUMN end_minute SET NOT NULL,
+  ADD CONSTRAINT valid_day_of_week CHECK (day_of_week BETWEEN 0 AND 6),
+  ADD CONSTRAINT valid_time_range CHECK (start_minute >= 0 AND end_minute <= 1440 AND start_minute < end_minute);
+
+ALTER TABLE schedules DROP CONSTRAINT unique_room_timeslot;
+ALTER TABLE schedules DROP CONSTRAINT unique_teacher_timeslot;
+ALTER TABLE schedules DROP CONSTRAINT unique_group_timeslot;
+
+ALTER TABLE schedules ADD CONSTRAINT exclude_room_overlap
+  EXCLUDE USING gist (room_id WITH =, year WITH =, day_of_week WITH =, int4range(start_minute, end_minute) WITH &&);
+ALTER TABLE schedules ADD CONSTRAINT exclude_teacher_overlap
+  EXCLUDE USING gist (teacher_email WITH =, year WITH =, day_of_week WITH =, int4range(start_minute, end_minute) WITH &&);
+ALTER TABLE schedules ADD CONSTRAINT exclude_group_overlap
+  EXCLUDE USING gist (group_id WITH =, year WITH =, day_of_week WITH =, int4range(start_minute, end_minute) WITH &&);
+
+CREATE INDEX idx_schedules_year_day ON schedules(year, day_of_week);
@@ -0,0 +1,8 @@
+-- Secret per-user tokens for subscribable calendar feeds. Calendar apps poll
+-- the feed URL without an Authorization header, so the token is the
+-- credential; rotating it revokes every URL handed out before.
+CREATE TABLE calendar_tokens (
+  email VARCHAR(100) PRIMARY KEY REFERENCES "user"(email) ON DELETE CASCADE,
+  token VARCHAR(64) NOT NULL UNIQUE,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
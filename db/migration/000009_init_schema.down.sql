@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS published_schedules;
+DROP TABLE IF EXISTS routine_versions;
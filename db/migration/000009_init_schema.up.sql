@@ -0,0 +1,47 @@
+-- The schedules table becomes the working draft. Publishing a year copies
+-- its draft rows into published_schedules under a new routine version, and
+-- the public views read the active version only.
+CREATE TABLE routine_versions (
+  id BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+  year INT NOT NULL,
+  version INT NOT NULL,
+  note TEXT,
+  published_by VARCHAR(100),
+  published_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  active BOOLEAN NOT NULL DEFAULT false,
+  CONSTRAINT unique_routine_version UNIQUE (year, version)
+);
+
+-- at most one live version per year
+CREATE UNIQUE INDEX idx_routine_versions_active ON routine_versions (year) WHERE active;
+
+-- Snapshot rows keep the ids they had in the draft but no foreign keys, so
+-- history survives later edits to rooms, teachers or sections.
+CREATE TABLE published_schedules (
+  version_id BIGINT NOT NULL REFERENCES routine_versions(id) ON DELETE CASCADE,
+  schedule_id BIGINT NOT NULL,
+  group_id BIGINT,
+  room_id BIGINT,
+  subject_id BIGINT,
+  teacher_email VARCHAR(100),
+  time_slot VARCHAR(20),
+  year INT NOT NULL,
+  day_of_week SMALLINT NOT NULL,
+  start_minute INT4 NOT NULL,
+  end_minute INT4 NOT NULL,
+  PRIMARY KEY (version_id, schedule_id)
+);
+
+-- Whatever is visible today stays visible: publish every existing year as
+-- its first version.
+INSERT INTO routine_versions (year, version, note, active)
+SELECT DISTINCT year, 1, 'initial version', true FROM schedules;
+
+INSERT INTO published_schedules (
+  version_id, schedule_id, group_id, room_id, subject_id, teacher_email,
+  time_slot, year, day_of_week, start_minute, end_minute
+)
+SELECT v.id, s.id, s.group_id, s.room_id, s.subject_id, s.teacher_email,
+  s.time_slot, s.year, s.day_of_week, s.start_minute, s.end_minute
+FROM schedules s
+JOIN routine_versions v ON v.year = s.year;
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS calendar_events;
+DROP TYPE IF EXISTS calendar_event_kind;
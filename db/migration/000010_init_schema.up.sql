@@ -0,0 +1,19 @@
+CREATE TYPE calendar_event_kind AS ENUM ('term', 'holiday', 'exam_week', 'closure');
+
+-- The academic calendar: term dates plus the days without regular classes.
+-- Dates are inclusive. source_uid is the UID of an imported iCalendar event
+-- so that re-importing the same list updates instead of duplicating.
+CREATE TABLE calendar_events (
+  id BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+  kind calendar_event_kind NOT NULL,
+  title VARCHAR(200) NOT NULL,
+  start_date DATE NOT NULL,
+  end_date DATE NOT NULL,
+  year INT NOT NULL,
+  description TEXT,
+  source_uid VARCHAR(255) UNIQUE,
+  CONSTRAINT valid_calendar_event_range CHECK (end_date >= start_date)
+);
+
+CREATE INDEX idx_calendar_events_dates ON calendar_events (start_date, end_date);
+CREATE INDEX idx_calendar_events_year ON calendar_events (year);
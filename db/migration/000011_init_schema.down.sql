@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS schedule_overrides;
+DROP TABLE IF EXISTS teacher_absences;
@@ -0,0 +1,32 @@
+-- A teacher's absence over an inclusive range of dates.
+CREATE TABLE teacher_absences (
+  id BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+  teacher_email VARCHAR(100) NOT NULL REFERENCES teacher(email) ON DELETE CASCADE,
+  start_date DATE NOT NULL,
+  end_date DATE NOT NULL,
+  reason TEXT,
+  created_by VARCHAR(100),
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  CONSTRAINT valid_teacher_absence_range CHECK (end_date >= start_date)
+);
+
+CREATE INDEX idx_teacher_absences_teacher ON teacher_absences (teacher_email, start_date, end_date);
+
+-- A dated override of one session: who covers it, or that it is cancelled
+-- when substitute_email is NULL. schedule_id has no foreign key because a
+-- rollback with restore deletes the term's schedules and inserts them
+-- again; RestoreSchedulesFromVersion keeps their ids so covers still match.
+CREATE TABLE schedule_overrides (
+  id BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+  absence_id BIGINT NOT NULL REFERENCES teacher_absences(id) ON DELETE CASCADE,
+  schedule_id BIGINT NOT NULL,
+  date DATE NOT NULL,
+  substitute_email VARCHAR(100) REFERENCES teacher(email) ON DELETE SET NULL,
+  note TEXT,
+  created_by VARCHAR(100),
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  CONSTRAINT unique_schedule_override UNIQUE (schedule_id, date)
+);
+
+CREATE INDEX idx_schedule_overrides_date ON schedule_overrides (date);
+CREATE INDEX idx_schedule_overrides_substitute ON schedule_overrides (substitute_email, date);
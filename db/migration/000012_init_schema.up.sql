@@ -0,0 +1,17 @@
+-- One-off dated bookings of a room, such as seminars, make-up classes and
+-- defenses. Minutes count from midnight like the schedules columns.
+CREATE TABLE room_bookings (
+  id BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+  room_id INTEGER NOT NULL REFERENCES room(id) ON DELETE CASCADE,
+  date DATE NOT NULL,
+  start_minute INT4 NOT NULL,
+  end_minute INT4 NOT NULL,
+  purpose VARCHAR(200) NOT NULL,
+  booked_by VARCHAR(100) NOT NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  CONSTRAINT valid_booking_time_range CHECK (start_minute >= 0 AND end_minute <= 1440 AND start_minute < end_minute),
+  CONSTRAINT exclude_room_booking_overlap
+    EXCLUDE USING gist (room_id WITH =, date WITH =, int4range(start_minute, end_minute) WITH &&)
+);
+
+CREATE INDEX idx_room_bookings_date ON room_bookings (date);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_room_bookings_room_date;
+ALTER TABLE room DROP COLUMN IF EXISTS capacity;
@@ -0,0 +1,4 @@
+-- Seats in the room; NULL while unknown.
+ALTER TABLE room ADD COLUMN capacity INT4 CHECK (capacity >= 0);
+
+CREATE INDEX idx_room_bookings_room_date ON room_bookings (room_id, date);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS teacher_availability;
+DROP TYPE IF EXISTS availability_kind;
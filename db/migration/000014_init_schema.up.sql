@@ -0,0 +1,18 @@
+CREATE TYPE availability_kind AS ENUM ('unavailable', 'preferred', 'disliked');
+
+-- Standing weekly availability of a teacher. unavailable slots are a hard
+-- constraint on scheduling; preferred and disliked slots only steer it.
+CREATE TABLE teacher_availability (
+  id BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+  teacher_email VARCHAR(100) NOT NULL REFERENCES teacher(email) ON DELETE CASCADE,
+  kind availability_kind NOT NULL,
+  day_of_week SMALLINT NOT NULL,
+  start_minute INT4 NOT NULL,
+  end_minute INT4 NOT NULL,
+  note TEXT,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  CONSTRAINT valid_availability_day CHECK (day_of_week BETWEEN 0 AND 6),
+  CONSTRAINT valid_availability_time_range CHECK (start_minute >= 0 AND end_minute <= 1440 AND start_minute < end_minute)
+);
+
+CREATE INDEX idx_teacher_availability_teacher ON teacher_availability (teacher_email, day_of_week);
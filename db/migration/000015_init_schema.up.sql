@@ -0,0 +1,13 @@
+-- Weekly teaching load, in periods (scheduled classes), a teacher should
+-- carry. A limit names either one teacher or a designation; a teacher's own
+-- limit takes precedence over the one for their designation.
+CREATE TABLE workload_limits (
+  id BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+  teacher_email VARCHAR(100) UNIQUE REFERENCES teacher(email) ON DELETE CASCADE,
+  designation VARCHAR(20) UNIQUE,
+  min_periods INT4 NOT NULL DEFAULT 0,
+  max_periods INT4,
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  CONSTRAINT workload_limit_target CHECK ((teacher_email IS NULL) <> (designation IS NULL)),
+  CONSTRAINT valid_workload_range CHECK (min_periods >= 0 AND (max_periods IS NULL OR max_periods >= min_periods))
+);
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS curriculum_requirements;
+ALTER TABLE published_schedules DROP COLUMN IF EXISTS session_type;
+ALTER TABLE schedules DROP COLUMN IF EXISTS session_type;
+DROP TYPE IF EXISTS session_type;
@@ -0,0 +1,18 @@
+CREATE TYPE session_type AS ENUM ('lecture', 'lab', 'tutorial');
+
+ALTER TABLE schedules ADD COLUMN session_type session_type NOT NULL DEFAULT 'lecture';
+ALTER TABLE published_schedules ADD COLUMN session_type session_type NOT NULL DEFAULT 'lecture';
+
+-- Weekly periods a subject needs in a semester of a program, per session
+-- type. Sections are matched by program and the semester they are in.
+CREATE TABLE curriculum_requirements (
+  id BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+  program VARCHAR(10) NOT NULL,
+  semester INT4 NOT NULL,
+  subject_id INT8 NOT NULL REFERENCES subject(id) ON DELETE CASCADE,
+  session_type session_type NOT NULL,
+  periods_per_week INT4 NOT NULL,
+  CONSTRAINT valid_curriculum_semester CHECK (semester BETWEEN 1 AND 12),
+  CONSTRAINT valid_curriculum_periods CHECK (periods_per_week > 0),
+  CONSTRAINT unique_curriculum_requirement UNIQUE (program, semester, subject_id, session_type)
+);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_student_group_id;
+ALTER TABLE student_section DROP COLUMN IF EXISTS size;
@@ -0,0 +1,5 @@
+-- Headcount of the section when entered by hand. NULL means it is counted
+-- from the students assigned to the section.
+ALTER TABLE student_section ADD COLUMN size INT4 CHECK (size >= 0);
+
+CREATE INDEX idx_student_group_id ON student (group_id);
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS subject_room_requirements;
+DROP TABLE IF EXISTS room_features;
+ALTER TABLE room DROP COLUMN IF EXISTS room_type;
+DROP TYPE IF EXISTS room_type;
@@ -0,0 +1,25 @@
+CREATE TYPE room_type AS ENUM ('lecture_hall', 'computer_lab', 'laboratory', 'workshop');
+
+ALTER TABLE room ADD COLUMN room_type room_type NOT NULL DEFAULT 'lecture_hall';
+
+-- Free-form equipment tags such as projector, computers or benches.
+CREATE TABLE room_features (
+  room_id INTEGER NOT NULL REFERENCES room(id) ON DELETE CASCADE,
+  feature VARCHAR(30) NOT NULL,
+  PRIMARY KEY (room_id, feature)
+);
+
+INSERT INTO room_features (room_id, feature)
+SELECT id, 'projector' FROM room WHERE screen_available;
+
+-- What a room needs for a subject's sessions of one type, e.g. a computer
+-- lab for its practicals. A NULL room_type accepts any type; every listed
+-- feature must be present.
+CREATE TABLE subject_room_requirements (
+  id BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+  subject_id INT8 NOT NULL REFERENCES subject(id) ON DELETE CASCADE,
+  session_type session_type NOT NULL,
+  room_type room_type,
+  features TEXT[] NOT NULL DEFAULT '{}',
+  CONSTRAINT unique_subject_room_requirement UNIQUE (subject_id, session_type)
+);
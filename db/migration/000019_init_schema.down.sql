@@ -0,0 +1,9 @@
+ALTER TABLE schedules DROP CONSTRAINT IF EXISTS exclude_group_overlap;
+DELETE FROM schedules WHERE sub_group_id IS NOT NULL;
+ALTER TABLE schedules ADD CONSTRAINT exclude_group_overlap
+  EXCLUDE USING gist (group_id WITH =, year WITH =, day_of_week WITH =, int4range(start_minute, end_minute) WITH &&);
+
+ALTER TABLE published_schedules DROP COLUMN IF EXISTS sub_group_id;
+ALTER TABLE schedules DROP CONSTRAINT IF EXISTS schedules_sub_group_fk;
+ALTER TABLE schedules DROP COLUMN IF EXISTS sub_group_id;
+DROP TABLE IF EXISTS sub_groups;
@@ -0,0 +1,31 @@
+-- Parts of a section that are taught separately, e.g. the two halves of a
+-- section doing different labs at the same time.
+CREATE TABLE sub_groups (
+  id BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+  group_id INTEGER NOT NULL REFERENCES student_section(id) ON DELETE CASCADE,
+  name VARCHAR(20) NOT NULL,
+  size INT4 CHECK (size > 0),
+  CONSTRAINT unique_sub_group_name UNIQUE (group_id, name),
+  CONSTRAINT unique_sub_group_section UNIQUE (id, group_id)
+);
+
+-- NULL sub_group_id is a class for the whole section. The composite key
+-- keeps a schedule's sub-group inside its section.
+ALTER TABLE schedules ADD COLUMN sub_group_id BIGINT;
+ALTER TABLE schedules ADD CONSTRAINT schedules_sub_group_fk
+  FOREIGN KEY (sub_group_id, group_id) REFERENCES sub_groups (id, group_id);
+ALTER TABLE published_schedules ADD COLUMN sub_group_id BIGINT;
+
+-- Sub-groups of a section may have classes in parallel, but a whole-section
+-- class still clashes with anything else the section has. The whole
+-- section stands for the range of every sub-group id, a sub-group for just
+-- its own, so only those ranges overlapping count as a clash.
+ALTER TABLE schedules DROP CONSTRAINT exclude_group_overlap;
+ALTER TABLE schedules ADD CONSTRAINT exclude_group_overlap
+  EXCLUDE USING gist (
+    group_id WITH =,
+    year WITH =,
+    day_of_week WITH =,
+    int4range(start_minute, end_minute) WITH &&,
+    int8range(COALESCE(sub_group_id, 0), sub_group_id + 1) WITH &&
+  );
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_schedules_combined_groups;
+ALTER TABLE published_schedules DROP COLUMN IF EXISTS combined_group_ids;
+ALTER TABLE schedules DROP COLUMN IF EXISTS combined_group_ids;
@@ -0,0 +1,10 @@
+-- A combined class is one schedule attended by several sections together,
+-- e.g. a common subject taught in a large hall. group_id stays the section
+-- the class is booked for; combined_group_ids lists the others joining it
+-- and is NULL for an ordinary class.
+-- The group exclusion constraint only sees group_id, so clashes for the
+-- joining sections are caught by validation.
+ALTER TABLE schedules ADD COLUMN combined_group_ids BIGINT[];
+ALTER TABLE published_schedules ADD COLUMN combined_group_ids BIGINT[];
+
+CREATE INDEX idx_schedules_combined_groups ON schedules USING gin (combined_group_ids);
@@ -0,0 +1,34 @@
+-- Only the first term of each year survives going back to one routine per
+-- year.
+DELETE FROM schedules WHERE term <> 1;
+DELETE FROM routine_versions WHERE term <> 1;
+
+DROP INDEX IF EXISTS idx_schedules_year_day;
+CREATE INDEX idx_schedules_year_day ON schedules(year, day_of_week);
+
+ALTER TABLE schedules DROP CONSTRAINT IF EXISTS exclude_room_overlap;
+ALTER TABLE schedules ADD CONSTRAINT exclude_room_overlap
+  EXCLUDE USING gist (room_id WITH =, year WITH =, day_of_week WITH =, int4range(start_minute, end_minute) WITH &&);
+ALTER TABLE schedules DROP CONSTRAINT IF EXISTS exclude_teacher_overlap;
+ALTER TABLE schedules ADD CONSTRAINT exclude_teacher_overlap
+  EXCLUDE USING gist (teacher_email WITH =, year WITH =, day_of_week WITH =, int4range(start_minute, end_minute) WITH &&);
+ALTER TABLE schedules DROP CONSTRAINT IF EXISTS exclude_group_overlap;
+ALTER TABLE schedules ADD CONSTRAINT exclude_group_overlap
+  EXCLUDE USING gist (
+    group_id WITH =,
+    year WITH =,
+    day_of_week WITH =,
+    int4range(start_minute, end_minute) WITH &&,
+    int8range(COALESCE(sub_group_id, 0), sub_group_id + 1) WITH &&
+  );
+
+DROP INDEX IF EXISTS idx_routine_versions_active;
+CREATE UNIQUE INDEX idx_routine_versions_active ON routine_versions (year) WHERE active;
+ALTER TABLE routine_versions DROP CONSTRAINT IF EXISTS unique_routine_version;
+ALTER TABLE routine_versions ADD CONSTRAINT unique_routine_version UNIQUE (year, version);
+ALTER TABLE routine_versions DROP COLUMN IF EXISTS term;
+
+ALTER TABLE published_schedules DROP COLUMN IF EXISTS term;
+ALTER TABLE schedules DROP CONSTRAINT IF EXISTS schedules_term_fk;
+ALTER TABLE schedules DROP COLUMN IF EXISTS term;
+DROP TABLE IF EXISTS academic_terms;
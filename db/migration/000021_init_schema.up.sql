@@ -0,0 +1,53 @@
+-- An academic year is split into terms (semesters), each with its own
+-- routine. Dates are kept in both calendars: start_date and end_date in
+-- AD, start_date_bs and end_date_bs as written in BS, e.g. '2081-04-01'.
+CREATE TABLE academic_terms (
+  id BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+  year INT NOT NULL,
+  term SMALLINT NOT NULL CHECK (term > 0),
+  name VARCHAR(50) NOT NULL,
+  start_date DATE NOT NULL,
+  end_date DATE NOT NULL,
+  start_date_bs VARCHAR(10),
+  end_date_bs VARCHAR(10),
+  CONSTRAINT unique_academic_term UNIQUE (year, term),
+  CONSTRAINT academic_term_dates CHECK (end_date >= start_date)
+);
+
+ALTER TABLE schedules ADD COLUMN term SMALLINT NOT NULL DEFAULT 1;
+-- Terms are not seeded: their dates are set by the college, so admins
+-- create them. Existing schedules are left in term 1 of their year, which
+-- NOT VALID lets stay until that term is created; new and moved schedules
+-- need their term to exist. Until then views use the BS half-year.
+ALTER TABLE schedules ADD CONSTRAINT schedules_term_fk
+  FOREIGN KEY (year, term) REFERENCES academic_terms (year, term) ON UPDATE CASCADE
+  NOT VALID;
+ALTER TABLE published_schedules ADD COLUMN term SMALLINT NOT NULL DEFAULT 1;
+
+-- Versions are published per term.
+ALTER TABLE routine_versions ADD COLUMN term SMALLINT NOT NULL DEFAULT 1;
+ALTER TABLE routine_versions DROP CONSTRAINT unique_routine_version;
+ALTER TABLE routine_versions ADD CONSTRAINT unique_routine_version UNIQUE (year, term, version);
+DROP INDEX idx_routine_versions_active;
+CREATE UNIQUE INDEX idx_routine_versions_active ON routine_versions (year, term) WHERE active;
+
+-- Terms of the same year do not clash with each other.
+ALTER TABLE schedules DROP CONSTRAINT exclude_room_overlap;
+ALTER TABLE schedules ADD CONSTRAINT exclude_room_overlap
+  EXCLUDE USING gist (room_id WITH =, year WITH =, term WITH =, day_of_week WITH =, int4range(start_minute, end_minute) WITH &&);
+ALTER TABLE schedules DROP CONSTRAINT exclude_teacher_overlap;
+ALTER TABLE schedules ADD CONSTRAINT exclude_teacher_overlap
+  EXCLUDE USING gist (teacher_email WITH =, year WITH =, term WITH =, day_of_week WITH =, int4range(start_minute, end_minute) WITH &&);
+ALTER TABLE schedules DROP CONSTRAINT exclude_group_overlap;
+ALTER TABLE schedules ADD CONSTRAINT exclude_group_overlap
+  EXCLUDE USING gist (
+    group_id WITH =,
+    year WITH =,
+    term WITH =,
+    day_of_week WITH =,
+    int4range(start_minute, end_minute) WITH &&,
+    int8range(COALESCE(sub_group_id, 0), sub_group_id + 1) WITH &&
+  );
+
+DROP INDEX idx_schedules_year_day;
+CREATE INDEX idx_schedules_year_day ON schedules(year, term, day_of_week);
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS grid_periods;
+DROP TABLE IF EXISTS timetable_grids;
+DROP TYPE IF EXISTS shift;
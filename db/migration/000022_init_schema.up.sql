@@ -0,0 +1,40 @@
+CREATE TYPE shift AS ENUM ('morning', 'day', 'evening');
+
+-- A weekly timetable grid: the working days (0 = Sunday) and the periods
+-- of each day. A grid applies to the sections of the programs it lists;
+-- sections of other programs use the default grid.
+CREATE TABLE timetable_grids (
+  id BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+  name VARCHAR(50) NOT NULL UNIQUE,
+  shift shift NOT NULL DEFAULT 'day',
+  programs TEXT[] NOT NULL DEFAULT '{}',
+  days INT[] NOT NULL,
+  is_default BOOLEAN NOT NULL DEFAULT false
+);
+
+CREATE UNIQUE INDEX idx_timetable_grids_default ON timetable_grids (is_default) WHERE is_default;
+
+-- Periods in minutes after midnight. Breaks are part of the day but no
+-- class can be scheduled in them.
+CREATE TABLE grid_periods (
+  id BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+  grid_id BIGINT NOT NULL REFERENCES timetable_grids(id) ON DELETE CASCADE,
+  name VARCHAR(20) NOT NULL,
+  start_minute INT NOT NULL,
+  end_minute INT NOT NULL,
+  is_break BOOLEAN NOT NULL DEFAULT false,
+  CONSTRAINT unique_grid_period UNIQUE (grid_id, name),
+  CONSTRAINT grid_period_minutes CHECK (start_minute >= 0 AND end_minute <= 1440 AND end_minute > start_minute),
+  CONSTRAINT exclude_grid_period_overlap
+    EXCLUDE USING gist (grid_id WITH =, int4range(start_minute, end_minute) WITH &&)
+);
+
+-- The evening grid the routine tables have used so far.
+WITH g AS (
+  INSERT INTO timetable_grids (name, shift, days, is_default)
+  VALUES ('Evening', 'evening', '{0,1,2,3,4,5}', true)
+  RETURNING id
+)
+INSERT INTO grid_periods (grid_id, name, start_minute, end_minute)
+SELECT g.id, p.name, p.start_minute, p.end_minute
+FROM g, (VALUES ('1', 975, 1075), ('2', 1075, 1175)) AS p(name, start_minute, end_minute);
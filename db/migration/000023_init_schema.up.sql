@@ -0,0 +1,26 @@
+CREATE TYPE email_status AS ENUM ('pending', 'sent', 'failed');
+
+-- Emails waiting to be sent. Rows are written in the same request as the
+-- change they report and sent by a background worker, which retries
+-- failures with a growing delay until it gives up and marks them failed.
+CREATE TABLE email_outbox (
+  id BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+  recipient VARCHAR(100) NOT NULL,
+  subject TEXT NOT NULL,
+  body TEXT NOT NULL,
+  status email_status NOT NULL DEFAULT 'pending',
+  attempts INT NOT NULL DEFAULT 0,
+  last_error TEXT,
+  next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  sent_at TIMESTAMPTZ
+);
+
+CREATE INDEX idx_email_outbox_due ON email_outbox (next_attempt_at) WHERE status = 'pending';
+
+-- Addresses that asked not to be emailed about routine changes. Students
+-- need not have an account, so this is keyed by address rather than user.
+CREATE TABLE notification_opt_outs (
+  email VARCHAR(100) PRIMARY KEY,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
@@ -0,0 +1 @@
+DROP FUNCTION IF EXISTS schedule_periods(BIGINT, INT, INT);
@@ -0,0 +1,26 @@
+-- The number of teaching periods a class covers in its section's grid, at
+-- least one for a class outside the grid. Workload and curriculum totals
+-- add these up, so a three period lab counts three times. Without any grid
+-- the built-in evening periods are used, as the application does.
+CREATE FUNCTION schedule_periods(group_id BIGINT, start_minute INT, end_minute INT)
+RETURNS INT
+LANGUAGE sql STABLE
+AS $$
+  WITH grid AS (
+    SELECT g.id FROM timetable_grids g
+    LEFT JOIN student_section ss ON ss.id = schedule_periods.group_id
+    WHERE upper(ss.program) = ANY(g.programs) OR g.is_default
+    ORDER BY g.is_default, g.id
+    LIMIT 1
+  ), periods AS (
+    SELECT p.start_minute, p.end_minute FROM grid_periods p
+    WHERE p.grid_id = (SELECT id FROM grid) AND NOT p.is_break
+    UNION ALL
+    SELECT d.start_minute, d.end_minute
+    FROM (VALUES (975, 1075), (1075, 1175)) AS d(start_minute, end_minute)
+    WHERE NOT EXISTS (SELECT 1 FROM grid)
+  )
+  SELECT GREATEST(COUNT(*), 1)::int FROM periods p
+  WHERE p.start_minute < schedule_periods.end_minute
+    AND schedule_periods.start_minute < p.end_minute
+$$;
@@ -0,0 +1,3 @@
+DROP TRIGGER IF EXISTS schedules_sync_sections ON schedules;
+DROP FUNCTION IF EXISTS sync_schedule_sections();
+DROP TABLE IF EXISTS schedule_sections;
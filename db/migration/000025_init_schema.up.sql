@@ -0,0 +1,66 @@
+-- One row for every section attending a class: the section it is booked
+-- for and each section joining a combined class. The group exclusion
+-- constraint on schedules only sees group_id, so this table carries the
+-- same constraint for all attending sections and two concurrent writes
+-- cannot double-book a section through combined_group_ids either.
+-- A trigger keeps it in step with schedules; nothing else writes to it.
+CREATE TABLE schedule_sections (
+  schedule_id BIGINT NOT NULL REFERENCES schedules(id) ON DELETE CASCADE,
+  group_id BIGINT NOT NULL,
+  sub_group_id BIGINT,
+  year INT NOT NULL,
+  term SMALLINT NOT NULL,
+  day_of_week SMALLINT NOT NULL,
+  start_minute INT4 NOT NULL,
+  end_minute INT4 NOT NULL,
+  PRIMARY KEY (schedule_id, group_id),
+  -- sub-group ranges as in exclude_group_overlap; a section listed twice
+  -- in the same class is not a clash with itself
+  CONSTRAINT exclude_section_overlap
+    EXCLUDE USING gist (
+      group_id WITH =,
+      year WITH =,
+      term WITH =,
+      day_of_week WITH =,
+      int4range(start_minute, end_minute) WITH &&,
+      int8range(COALESCE(sub_group_id, 0), sub_group_id + 1) WITH &&,
+      schedule_id WITH <>
+    )
+);
+
+-- Joining sections attend as a whole; only the booked section can be
+-- narrowed to a sub-group.
+CREATE FUNCTION sync_schedule_sections() RETURNS trigger
+LANGUAGE plpgsql
+AS $$
+BEGIN
+  IF TG_OP = 'UPDATE' THEN
+    DELETE FROM schedule_sections WHERE schedule_id = OLD.id;
+  END IF;
+  INSERT INTO schedule_sections (
+    schedule_id, group_id, sub_group_id, year, term, day_of_week, start_minute, end_minute
+  )
+  SELECT DISTINCT ON (g.group_id) NEW.id, g.group_id,
+    CASE WHEN g.n = 1 THEN NEW.sub_group_id END,
+    NEW.year, NEW.term, NEW.day_of_week, NEW.start_minute, NEW.end_minute
+  FROM unnest(array_prepend(NEW.group_id, NEW.combined_group_ids)) WITH ORDINALITY AS g(group_id, n)
+  WHERE g.group_id IS NOT NULL
+  ORDER BY g.group_id, g.n;
+  RETURN NULL;
+END
+$$;
+
+CREATE TRIGGER schedules_sync_sections
+AFTER INSERT OR UPDATE ON schedules
+FOR EACH ROW EXECUTE FUNCTION sync_schedule_sections();
+
+INSERT INTO schedule_sections (
+  schedule_id, group_id, sub_group_id, year, term, day_of_week, start_minute, end_minute
+)
+SELECT DISTINCT ON (s.id, g.group_id) s.id, g.group_id,
+  CASE WHEN g.n = 1 THEN s.sub_group_id END,
+  s.year, s.term, s.day_of_week, s.start_minute, s.end_minute
+FROM schedules s
+CROSS JOIN LATERAL unnest(array_prepend(s.group_id, s.combined_group_ids)) WITH ORDINALITY AS g(group_id, n)
+WHERE g.group_id IS NOT NULL
+ORDER BY s.id, g.group_id, g.n;
@@ -0,0 +1,12 @@
+-- The students of a sub-group, so a class for one sub-group is only
+-- reported to the students who attend it. Sub-groups of a section may have
+-- classes in parallel, so a student is in at most one sub-group of their
+-- section; in two they could be booked twice at once.
+CREATE TABLE sub_group_students (
+  sub_group_id BIGINT NOT NULL,
+  group_id INTEGER NOT NULL,
+  student_id INT8 NOT NULL REFERENCES student(id) ON DELETE CASCADE,
+  PRIMARY KEY (sub_group_id, student_id),
+  FOREIGN KEY (sub_group_id, group_id) REFERENCES sub_groups (id, group_id) ON DELETE CASCADE,
+  CONSTRAINT unique_student_sub_group UNIQUE (student_id, group_id)
+);
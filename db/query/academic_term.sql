@@ -0,0 +1,50 @@
+-- name: CreateAcademicTerm :one
+INSERT INTO academic_terms (
+  year,
+  term,
+  name,
+  start_date,
+  end_date,
+  start_date_bs,
+  end_date_bs
+) VALUES (
+  $1, $2, $3, $4, $5, $6, $7
+) RETURNING *;
+
+-- name: GetAcademicTerm :one
+SELECT * FROM academic_terms
+WHERE year = $1 AND term = $2 LIMIT 1;
+
+-- name: GetAcademicTermByID :one
+SELECT * FROM academic_terms
+WHERE id = $1 LIMIT 1;
+
+-- name: GetAcademicTermOn :one
+-- The term running on a date, the later one if two overlap.
+SELECT * FROM academic_terms
+WHERE start_date <= sqlc.arg(date)::date AND end_date >= sqlc.arg(date)::date
+ORDER BY start_date DESC
+LIMIT 1;
+
+-- name: ListAcademicTerms :many
+-- Terms with the number of draft schedules each has.
+SELECT t.*,
+  (SELECT count(*) FROM schedules s WHERE s.year = t.year AND s.term = t.term) AS schedules
+FROM academic_terms t
+WHERE sqlc.narg(year)::int IS NULL OR t.year = sqlc.narg(year)::int
+ORDER BY t.year, t.term;
+
+-- name: UpdateAcademicTerm :one
+UPDATE academic_terms
+SET
+  name = $2,
+  start_date = $3,
+  end_date = $4,
+  start_date_bs = $5,
+  end_date_bs = $6
+WHERE id = $1
+RETURNING *;
+
+-- name: DeleteAcademicTerm :execrows
+DELETE FROM academic_terms
+WHERE id = $1;
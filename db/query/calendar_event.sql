@@ -0,0 +1,67 @@
+-- name: CreateCalendarEvent :one
+INSERT INTO calendar_events (
+  kind,
+  title,
+  start_date,
+  end_date,
+  year,
+  description
+) VALUES (
+  $1, $2, $3, $4, $5, $6
+) RETURNING *;
+
+-- name: GetCalendarEvent :one
+SELECT * FROM calendar_events
+WHERE id = $1 LIMIT 1;
+
+-- name: ListCalendarEvents :many
+SELECT * FROM calendar_events
+WHERE (sqlc.narg(year)::int IS NULL OR year = sqlc.narg(year)::int)
+  AND (sqlc.narg(kind)::calendar_event_kind IS NULL OR kind = sqlc.narg(kind)::calendar_event_kind)
+ORDER BY start_date, id;
+
+-- name: UpdateCalendarEvent :one
+UPDATE calendar_events
+SET
+  kind = $2,
+  title = $3,
+  start_date = $4,
+  end_date = $5,
+  year = $6,
+  description = $7
+WHERE id = $1
+RETURNING *;
+
+-- name: DeleteCalendarEvent :exec
+DELETE FROM calendar_events
+WHERE id = $1;
+
+-- name: UpsertImportedCalendarEvent :one
+INSERT INTO calendar_events (
+  kind,
+  title,
+  start_date,
+  end_date,
+  year,
+  description,
+  source_uid
+) VALUES (
+  $1, $2, $3, $4, $5, $6, $7
+)
+ON CONFLICT (source_uid) DO UPDATE
+SET
+  kind = EXCLUDED.kind,
+  title = EXCLUDED.title,
+  start_date = EXCLUDED.start_date,
+  end_date = EXCLUDED.end_date,
+  year = EXCLUDED.year,
+  description = EXCLUDED.description
+RETURNING *;
+
+-- name: ListNoClassDays :many
+-- Holidays, exam weeks and closures overlapping a date range.
+SELECT * FROM calendar_events
+WHERE kind <> 'term'
+  AND start_date <= sqlc.arg(to_date)
+  AND end_date >= sqlc.arg(from_date)
+ORDER BY start_date, id;
@@ -0,0 +1,12 @@
+-- name: GetCalendarTokenByEmail :one
+SELECT * FROM calendar_tokens WHERE email = $1;
+
+-- name: GetCalendarToken :one
+SELECT * FROM calendar_tokens WHERE token = $1;
+
+-- name: UpsertCalendarToken :one
+INSERT INTO calendar_tokens (email, token)
+VALUES ($1, $2)
+ON CONFLICT (email) DO UPDATE
+SET token = EXCLUDED.token, created_at = now()
+RETURNING *;
@@ -0,0 +1,38 @@
+-- name: UpsertCurriculumRequirement :one
+INSERT INTO curriculum_requirements (
+  program,
+  semester,
+  subject_id,
+  session_type,
+  periods_per_week
+) VALUES (
+  $1, $2, $3, $4, $5
+)
+ON CONFLICT (program, semester, subject_id, session_type)
+DO UPDATE SET periods_per_week = EXCLUDED.periods_per_week
+RETURNING *;
+
+-- name: ListCurriculumRequirements :many
+SELECT c.*, sub.subject_code, sub.name AS subject_name
+FROM curriculum_requirements c
+JOIN subject sub ON sub.id = c.subject_id
+WHERE (sqlc.narg(program)::text IS NULL OR upper(c.program) = upper(sqlc.narg(program)::text))
+  AND (sqlc.narg(semester)::int IS NULL OR c.semester = sqlc.narg(semester)::int)
+ORDER BY c.program, c.semester, sub.subject_code, c.session_type;
+
+-- name: DeleteCurriculumRequirement :execrows
+DELETE FROM curriculum_requirements
+WHERE id = $1;
+
+-- name: ListScheduledPeriods :many
+-- Scheduled periods per section, subject, session type and sub-group in a
+-- term, with a null sub_group_id for whole-section classes. A class
+-- spanning several grid periods counts each of them, and a combined class
+-- counts for every section attending it.
+SELECT g.group_id, s.subject_id, s.session_type, s.sub_group_id,
+  SUM(schedule_periods(s.group_id, s.start_minute, s.end_minute))::int8 AS periods
+FROM schedules s
+CROSS JOIN LATERAL unnest(array_prepend(s.group_id, s.combined_group_ids)) AS g(group_id)
+WHERE s.year = $1 AND s.term = $2 AND g.group_id IS NOT NULL AND s.subject_id IS NOT NULL
+GROUP BY g.group_id, s.subject_id, s.session_type, s.sub_group_id
+ORDER BY g.group_id, s.subject_id, s.session_type, s.sub_group_id;
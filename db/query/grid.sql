@@ -0,0 +1,71 @@
+-- name: CreateGrid :one
+INSERT INTO timetable_grids (
+  name,
+  shift,
+  programs,
+  days,
+  is_default
+) VALUES (
+  $1, $2, $3, $4, $5
+) RETURNING *;
+
+-- name: GetGrid :one
+SELECT * FROM timetable_grids
+WHERE id = $1 LIMIT 1;
+
+-- name: ListGrids :many
+SELECT * FROM timetable_grids
+ORDER BY name;
+
+-- name: GetGridForSection :one
+-- The grid set up for the section's program, or the default grid.
+SELECT g.* FROM timetable_grids g
+LEFT JOIN student_section ss ON ss.id = sqlc.arg(group_id)
+WHERE upper(ss.program) = ANY(g.programs) OR g.is_default
+ORDER BY g.is_default, g.id
+LIMIT 1;
+
+-- name: UpdateGrid :one
+UPDATE timetable_grids
+SET
+  name = $2,
+  shift = $3,
+  programs = $4,
+  days = $5,
+  is_default = $6
+WHERE id = $1
+RETURNING *;
+
+-- name: ClearDefaultGrid :exec
+-- Only one grid can be the default; run before making another one it.
+UPDATE timetable_grids
+SET is_default = false
+WHERE is_default AND id <> $1;
+
+-- name: DeleteGrid :execrows
+DELETE FROM timetable_grids
+WHERE id = $1;
+
+-- name: CreateGridPeriod :one
+INSERT INTO grid_periods (
+  grid_id,
+  name,
+  start_minute,
+  end_minute,
+  is_break
+) VALUES (
+  $1, $2, $3, $4, $5
+) RETURNING *;
+
+-- name: ListGridPeriods :many
+SELECT * FROM grid_periods
+WHERE grid_id = $1
+ORDER BY start_minute;
+
+-- name: ListAllGridPeriods :many
+SELECT * FROM grid_periods
+ORDER BY grid_id, start_minute;
+
+-- name: DeleteGridPeriods :exec
+DELETE FROM grid_periods
+WHERE grid_id = $1;
@@ -0,0 +1,98 @@
+-- name: EnqueueEmail :one
+INSERT INTO email_outbox (
+  recipient,
+  subject,
+  body
+) VALUES (
+  $1, $2, $3
+) RETURNING *;
+
+-- name: ClaimDueEmails :many
+-- Leases up to limit due emails for ten minutes, so a worker that dies
+-- mid-send does not hold them for good and two workers never send the
+-- same email.
+UPDATE email_outbox
+SET next_attempt_at = now() + interval '10 minutes'
+WHERE id IN (
+  SELECT id FROM email_outbox
+  WHERE status = 'pending' AND next_attempt_at <= now()
+  ORDER BY id
+  LIMIT sqlc.arg(max_emails)
+  FOR UPDATE SKIP LOCKED
+)
+RETURNING *;
+
+-- name: MarkEmailSent :exec
+UPDATE email_outbox
+SET status = 'sent', attempts = attempts + 1, last_error = NULL, sent_at = now()
+WHERE id = $1;
+
+-- name: MarkEmailFailed :exec
+-- Records a failed attempt; status is 'pending' to retry at next_attempt_at
+-- or 'failed' to give up.
+UPDATE email_outbox
+SET status = $2, attempts = attempts + 1, last_error = $3, next_attempt_at = $4
+WHERE id = $1;
+
+-- name: ListEmailOutbox :many
+SELECT * FROM email_outbox
+WHERE sqlc.narg(status)::email_status IS NULL OR status = sqlc.narg(status)::email_status
+ORDER BY id DESC
+LIMIT 100;
+
+-- name: RetryEmail :execrows
+UPDATE email_outbox
+SET status = 'pending', next_attempt_at = now()
+WHERE id = $1 AND status = 'failed';
+
+-- name: ListSectionMemberEmails :many
+-- The addresses of the students of the sections: their own and those of
+-- the accounts linked to them.
+SELECT s.email::text AS email FROM student s
+WHERE s.group_id = ANY(sqlc.arg(group_ids)::bigint[]) AND s.email IS NOT NULL AND s.email <> ''
+UNION
+SELECT u.email FROM "user" u
+JOIN student s ON s.id = u.student_id
+WHERE s.group_id = ANY(sqlc.arg(group_ids)::bigint[])
+ORDER BY email;
+
+-- name: ListSubGroupMemberEmails :many
+-- Like ListSectionMemberEmails, for the students of sub-groups only.
+SELECT s.email::text AS email FROM student s
+JOIN sub_group_students m ON m.student_id = s.id
+WHERE m.sub_group_id = ANY(sqlc.arg(sub_group_ids)::bigint[]) AND s.email IS NOT NULL AND s.email <> ''
+UNION
+SELECT u.email FROM "user" u
+JOIN sub_group_students m ON m.student_id = u.student_id
+WHERE m.sub_group_id = ANY(sqlc.arg(sub_group_ids)::bigint[])
+ORDER BY email;
+
+-- name: ListOptedOutEmails :many
+SELECT email FROM notification_opt_outs
+WHERE email = ANY(sqlc.arg(emails)::text[]);
+
+-- name: GetNotificationOptOut :one
+SELECT * FROM notification_opt_outs
+WHERE email = $1;
+
+-- name: CreateNotificationOptOut :exec
+INSERT INTO notification_opt_outs (email)
+VALUES ($1)
+ON CONFLICT (email) DO NOTHING;
+
+-- name: DeleteNotificationOptOut :exec
+DELETE FROM notification_opt_outs
+WHERE email = $1;
+
+-- name: ListUserEmailAddresses :many
+-- The addresses a user can be emailed at: their login and those of the
+-- teacher or student they are linked to.
+SELECT u.email::text AS email FROM "user" u
+WHERE u.email = $1
+UNION
+SELECT u.teacher_email FROM "user" u
+WHERE u.email = $1 AND u.teacher_email IS NOT NULL
+UNION
+SELECT s.email FROM "user" u
+JOIN student s ON s.id = u.student_id
+WHERE u.email = $1 AND s.email IS NOT NULL;
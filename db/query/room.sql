@@ -4,13 +4,17 @@ INSERT INTO room (
     block_no,
     floor_no,
     screen_available,
-  department
+  department,
+    capacity,
+    room_type
 ) VALUES (
     $1,
     $2,
     $3,
     $4,
-   $5
+   $5,
+    $6,
+    $7
 ) RETURNING *;
 
 -- name: GetRoom :one
@@ -30,6 +34,8 @@ SET department = $2
   , block_no = $4
   , floor_no = $5
   , screen_available = $6
+  , capacity = $7
+  , room_type = $8
 WHERE id = $1
 RETURNING *;
 
@@ -43,4 +49,58 @@ WHERE department = $1
 ORDER BY id;
 
 -- name: CountRooms :one
-SELECT count(*) FROM room;
\ No newline at end of file
+SELECT count(*) FROM room;
+
+-- name: ListAllRooms :many
+SELECT * FROM room
+ORDER BY id;
+
+-- name: GetRoomByCode :one
+SELECT * FROM room
+WHERE upper(room_code) = upper(sqlc.arg(room_code)::text)
+LIMIT 1;
+
+-- name: ListFreeRooms :many
+-- Rooms with no weekly class overlapping the slot and, when a date is
+-- given, no booking overlapping it on that date.
+SELECT r.* FROM room r
+WHERE (sqlc.narg(block_no)::text IS NULL OR upper(r.block_no) = upper(sqlc.narg(block_no)::text))
+  AND (sqlc.narg(floor_no)::int IS NULL OR r.floor_no = sqlc.narg(floor_no)::int)
+  AND (sqlc.narg(department)::text IS NULL OR upper(r.department) = upper(sqlc.narg(department)::text))
+  AND (sqlc.narg(screen_available)::bool IS NULL OR r.screen_available = sqlc.narg(screen_available)::bool)
+  AND (sqlc.narg(min_capacity)::int IS NULL OR r.capacity >= sqlc.narg(min_capacity)::int)
+  AND NOT EXISTS (
+    SELECT 1 FROM schedules s
+    WHERE s.room_id = r.id
+      AND s.year = sqlc.arg(year)
+      AND s.term = sqlc.arg(term)
+      AND s.day_of_week = sqlc.arg(day_of_week)
+      AND s.start_minute < sqlc.arg(end_minute)
+      AND s.end_minute > sqlc.arg(start_minute)
+  )
+  AND (sqlc.narg(date)::date IS NULL OR NOT EXISTS (
+    SELECT 1 FROM room_bookings b
+    WHERE b.room_id = r.id
+      AND b.date = sqlc.narg(date)::date
+      AND b.start_minute < sqlc.arg(end_minute)
+      AND b.end_minute > sqlc.arg(start_minute)
+  ))
+ORDER BY r.block_no, r.floor_no, r.room_code;
+
+-- name: ListRoomFeatures :many
+SELECT feature FROM room_features
+WHERE room_id = $1
+ORDER BY feature;
+
+-- name: ListAllRoomFeatures :many
+SELECT room_id, feature FROM room_features
+ORDER BY room_id, feature;
+
+-- name: DeleteRoomFeatures :exec
+DELETE FROM room_features
+WHERE room_id = $1;
+
+-- name: AddRoomFeatures :exec
+INSERT INTO room_features (room_id, feature)
+SELECT sqlc.arg(room_id), unnest(sqlc.arg(features)::text[])
+ON CONFLICT DO NOTHING;
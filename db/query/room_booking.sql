@@ -0,0 +1,36 @@
+-- name: CreateRoomBooking :one
+INSERT INTO room_bookings (
+  room_id,
+  date,
+  start_minute,
+  end_minute,
+  purpose,
+  booked_by
+) VALUES (
+  $1, $2, $3, $4, $5, $6
+) RETURNING *;
+
+-- name: GetRoomBooking :one
+SELECT * FROM room_bookings
+WHERE id = $1 LIMIT 1;
+
+-- name: DeleteRoomBooking :exec
+DELETE FROM room_bookings
+WHERE id = $1;
+
+-- name: ListRoomBookings :many
+SELECT b.*, r.room_code
+FROM room_bookings b
+JOIN room r ON r.id = b.room_id
+WHERE (sqlc.narg(room_id)::int IS NULL OR b.room_id = sqlc.narg(room_id)::int)
+  AND (sqlc.narg(from_date)::date IS NULL OR b.date >= sqlc.narg(from_date)::date)
+  AND (sqlc.narg(to_date)::date IS NULL OR b.date <= sqlc.narg(to_date)::date)
+ORDER BY b.date, b.start_minute, b.id;
+
+-- name: ListRoomBookingConflicts :many
+SELECT * FROM room_bookings
+WHERE room_id = sqlc.arg(room_id)
+  AND date = sqlc.arg(date)
+  AND start_minute < sqlc.arg(end_minute)
+  AND end_minute > sqlc.arg(start_minute)
+ORDER BY start_minute;
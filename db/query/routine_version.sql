@@ -0,0 +1,153 @@
+-- name: CreateRoutineVersion :one
+INSERT INTO routine_versions (year, term, version, note, published_by)
+SELECT sqlc.arg(year)::int, sqlc.arg(term)::smallint, COALESCE(MAX(version), 0) + 1, sqlc.narg(note)::text, sqlc.narg(published_by)::text
+FROM routine_versions
+WHERE year = sqlc.arg(year)::int AND term = sqlc.arg(term)::smallint
+RETURNING *;
+
+-- name: GetRoutineVersion :one
+SELECT * FROM routine_versions
+WHERE year = $1 AND term = $2 AND version = $3;
+
+-- name: GetActiveRoutineVersion :one
+SELECT * FROM routine_versions
+WHERE year = $1 AND term = $2 AND active;
+
+-- name: ListRoutineVersions :many
+SELECT v.*, (SELECT count(*) FROM published_schedules ps WHERE ps.version_id = v.id) AS schedule_count
+FROM routine_versions v
+WHERE v.year = $1 AND v.term = $2
+ORDER BY v.version DESC;
+
+-- name: DeactivateRoutineVersions :exec
+UPDATE routine_versions
+SET active = false
+WHERE year = $1 AND term = $2 AND active;
+
+-- name: ActivateRoutineVersion :one
+UPDATE routine_versions
+SET active = true
+WHERE id = $1
+RETURNING *;
+
+-- name: SnapshotSchedules :execrows
+INSERT INTO published_schedules (
+  version_id, schedule_id, group_id, room_id, subject_id, teacher_email,
+  time_slot, year, day_of_week, start_minute, end_minute, session_type, sub_group_id,
+  combined_group_ids, term
+)
+SELECT sqlc.arg(version_id)::bigint, s.id, s.group_id, s.room_id, s.subject_id, s.teacher_email,
+  s.time_slot, s.year, s.day_of_week, s.start_minute, s.end_minute, s.session_type, s.sub_group_id,
+  s.combined_group_ids, s.term
+FROM schedules s
+WHERE s.year = sqlc.arg(year) AND s.term = sqlc.arg(term);
+
+-- name: ListPublishedSchedules :many
+SELECT * FROM published_schedules
+WHERE version_id = $1
+ORDER BY day_of_week, start_minute, schedule_id;
+
+-- name: DeleteSchedulesByTerm :exec
+DELETE FROM schedules
+WHERE year = $1 AND term = $2;
+
+-- name: RestoreSchedulesFromVersion :execrows
+-- Restored rows keep the ids they had when the version was published, so
+-- cover overrides and calendar UIDs still point at them. An id taken since
+-- by a schedule moved to another term gets a new one instead.
+INSERT INTO schedules (
+  id, group_id, room_id, subject_id, teacher_email, time_slot, year,
+  day_of_week, start_minute, end_minute, session_type, sub_group_id,
+  combined_group_ids, term
+) OVERRIDING SYSTEM VALUE
+SELECT CASE WHEN EXISTS (SELECT 1 FROM schedules s WHERE s.id = ps.schedule_id)
+    THEN nextval(pg_get_serial_sequence('schedules', 'id'))
+    ELSE ps.schedule_id
+  END,
+  ps.group_id, ps.room_id, ps.subject_id, ps.teacher_email, ps.time_slot, ps.year,
+  ps.day_of_week, ps.start_minute, ps.end_minute, ps.session_type, ps.sub_group_id,
+  ps.combined_group_ids, ps.term
+FROM published_schedules ps
+WHERE ps.version_id = $1
+ORDER BY ps.day_of_week, ps.start_minute;
+
+-- name: GetPublishedSchedulesByTeacher :many
+SELECT ps.schedule_id AS id, ps.group_id, ps.room_id, ps.subject_id, ps.teacher_email,
+  ps.time_slot, ps.year, ps.day_of_week, ps.start_minute, ps.end_minute, ps.session_type, ps.sub_group_id,
+  ps.combined_group_ids, ps.term,
+  t.name AS teacher_name,
+  t.designation AS teacher_designation,
+  r.room_code,
+  r.block_no,
+  sub.subject_code,
+  sub.name AS subject_name,
+  ss.name AS group_name,
+  sg.name AS sub_group_name,
+  ARRAY(
+    SELECT c.name FROM student_section c
+    WHERE c.id = ANY(ps.combined_group_ids)
+    ORDER BY c.name
+  )::text[] AS combined_group_names
+FROM published_schedules ps
+JOIN routine_versions v ON v.id = ps.version_id AND v.active
+LEFT JOIN teacher t ON ps.teacher_email = t.email
+LEFT JOIN room r ON ps.room_id = r.id
+LEFT JOIN subject sub ON ps.subject_id = sub.id
+LEFT JOIN student_section ss ON ps.group_id = ss.id
+LEFT JOIN sub_groups sg ON ps.sub_group_id = sg.id
+WHERE ps.teacher_email = $1 AND v.year = $2 AND v.term = $3
+ORDER BY ps.day_of_week, ps.start_minute;
+
+-- name: GetPublishedSchedulesByRoom :many
+SELECT ps.schedule_id AS id, ps.group_id, ps.room_id, ps.subject_id, ps.teacher_email,
+  ps.time_slot, ps.year, ps.day_of_week, ps.start_minute, ps.end_minute, ps.session_type, ps.sub_group_id,
+  ps.combined_group_ids, ps.term,
+  t.name AS teacher_name,
+  t.designation AS teacher_designation,
+  r.room_code,
+  r.block_no,
+  sub.subject_code,
+  sub.name AS subject_name,
+  ss.name AS group_name,
+  sg.name AS sub_group_name,
+  ARRAY(
+    SELECT c.name FROM student_section c
+    WHERE c.id = ANY(ps.combined_group_ids)
+    ORDER BY c.name
+  )::text[] AS combined_group_names
+FROM published_schedules ps
+JOIN routine_versions v ON v.id = ps.version_id AND v.active
+LEFT JOIN teacher t ON ps.teacher_email = t.email
+LEFT JOIN room r ON ps.room_id = r.id
+LEFT JOIN subject sub ON ps.subject_id = sub.id
+LEFT JOIN student_section ss ON ps.group_id = ss.id
+LEFT JOIN sub_groups sg ON ps.sub_group_id = sg.id
+WHERE ps.room_id = $1 AND v.year = $2 AND v.term = $3
+ORDER BY ps.day_of_week, ps.start_minute;
+
+-- name: GetPublishedSchedulesByGroup :many
+SELECT ps.schedule_id AS id, ps.group_id, ps.room_id, ps.subject_id, ps.teacher_email,
+  ps.time_slot, ps.year, ps.day_of_week, ps.start_minute, ps.end_minute, ps.session_type, ps.sub_group_id,
+  ps.combined_group_ids, ps.term,
+  t.name AS teacher_name,
+  t.designation AS teacher_designation,
+  r.room_code,
+  r.block_no,
+  sub.subject_code,
+  sub.name AS subject_name,
+  ss.name AS group_name,
+  sg.name AS sub_group_name,
+  ARRAY(
+    SELECT c.name FROM student_section c
+    WHERE c.id = ANY(ps.combined_group_ids)
+    ORDER BY c.name
+  )::text[] AS combined_group_names
+FROM published_schedules ps
+JOIN routine_versions v ON v.id = ps.version_id AND v.active
+LEFT JOIN teacher t ON ps.teacher_email = t.email
+LEFT JOIN room r ON ps.room_id = r.id
+LEFT JOIN subject sub ON ps.subject_id = sub.id
+LEFT JOIN student_section ss ON ps.group_id = ss.id
+LEFT JOIN sub_groups sg ON ps.sub_group_id = sg.id
+WHERE (ps.group_id = $1 OR $1 = ANY(ps.combined_group_ids)) AND v.year = $2 AND v.term = $3
+ORDER BY ps.day_of_week, ps.start_minute;
@@ -5,9 +5,16 @@ INSERT INTO schedules (
   subject_id,
   teacher_email,
   time_slot,
-  year
+  year,
+  day_of_week,
+  start_minute,
+  end_minute,
+  session_type,
+  sub_group_id,
+  combined_group_ids,
+  term
 ) VALUES (
-  $1, $2, $3, $4, $5, $6
+  $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13
 ) RETURNING *;
 
 -- name: GetSchedule :one
@@ -16,7 +23,7 @@ WHERE id = $1 LIMIT 1;
 
 -- name: ListSchedules :many
 SELECT * FROM schedules
-ORDER BY time_slot
+ORDER BY day_of_week, start_minute
 LIMIT $1
 OFFSET $2;
 
@@ -27,7 +34,15 @@ SET
   room_id = $3,
   subject_id = $4,
   teacher_email = $5,
-  time_slot = $6
+  time_slot = $6,
+  day_of_week = $7,
+  start_minute = $8,
+  end_minute = $9,
+  year = $10,
+  session_type = $11,
+  sub_group_id = $12,
+  combined_group_ids = $13,
+  term = $14
 WHERE id = $1
 RETURNING *;
 
@@ -43,14 +58,21 @@ SELECT s.*,
   r.block_no,
   sub.subject_code,
   sub.name AS subject_name,
-  ss.name AS group_name
+  ss.name AS group_name,
+  sg.name AS sub_group_name,
+  ARRAY(
+    SELECT c.name FROM student_section c
+    WHERE c.id = ANY(s.combined_group_ids)
+    ORDER BY c.name
+  )::text[] AS combined_group_names
 FROM schedules s
 JOIN teacher t ON s.teacher_email = t.email
 JOIN room r ON s.room_id = r.id
 JOIN subject sub ON s.subject_id = sub.id
 JOIN student_section ss ON s.group_id = ss.id
-WHERE s.teacher_email = $1 AND s.year = $2
-ORDER BY s.time_slot;
+LEFT JOIN sub_groups sg ON s.sub_group_id = sg.id
+WHERE s.teacher_email = $1 AND s.year = $2 AND s.term = $3
+ORDER BY s.day_of_week, s.start_minute;
 
 -- name: GetSchedulesByRoom :many
 SELECT s.*, 
@@ -60,14 +82,21 @@ SELECT s.*,
   r.block_no,
   sub.subject_code,
   sub.name AS subject_name,
-  ss.name AS group_name
+  ss.name AS group_name,
+  sg.name AS sub_group_name,
+  ARRAY(
+    SELECT c.name FROM student_section c
+    WHERE c.id = ANY(s.combined_group_ids)
+    ORDER BY c.name
+  )::text[] AS combined_group_names
 FROM schedules s
 JOIN teacher t ON s.teacher_email = t.email
 JOIN room r ON s.room_id = r.id
 JOIN subject sub ON s.subject_id = sub.id
 JOIN student_section ss ON s.group_id = ss.id
-WHERE s.room_id = $1 AND s.year = $2
-ORDER BY s.time_slot;
+LEFT JOIN sub_groups sg ON s.sub_group_id = sg.id
+WHERE s.room_id = $1 AND s.year = $2 AND s.term = $3
+ORDER BY s.day_of_week, s.start_minute;
 
 -- name: GetSchedulesByGroup :many
 SELECT s.*, 
@@ -77,27 +106,41 @@ SELECT s.*,
   r.block_no,
   sub.subject_code,
   sub.name AS subject_name,
-  ss.name AS group_name
+  ss.name AS group_name,
+  sg.name AS sub_group_name,
+  ARRAY(
+    SELECT c.name FROM student_section c
+    WHERE c.id = ANY(s.combined_group_ids)
+    ORDER BY c.name
+  )::text[] AS combined_group_names
 FROM schedules s
 JOIN teacher t ON s.teacher_email = t.email
 JOIN room r ON s.room_id = r.id
 JOIN subject sub ON s.subject_id = sub.id
 JOIN student_section ss ON s.group_id = ss.id
-WHERE s.group_id = $1 AND s.year = $2
-ORDER BY s.time_slot;
+LEFT JOIN sub_groups sg ON s.sub_group_id = sg.id
+WHERE (s.group_id = $1 OR $1 = ANY(s.combined_group_ids)) AND s.year = $2 AND s.term = $3
+ORDER BY s.day_of_week, s.start_minute;
 
 -- name: CountSchedules :one
 SELECT count(*) FROM schedules;
 
--- name: CheckScheduleConflicts :one
-SELECT EXISTS (
-  SELECT 1 FROM schedules
-  WHERE time_slot = $1 AND (
-    room_id = $2 OR 
-    teacher_email = $3 OR 
-    group_id = $4
+-- name: ListScheduleConflicts :many
+SELECT id, time_slot, room_id, teacher_email, group_id, sub_group_id, combined_group_ids
+FROM schedules
+WHERE year = sqlc.arg(year)
+  AND term = sqlc.arg(term)
+  AND day_of_week = sqlc.arg(day_of_week)
+  AND start_minute < sqlc.arg(end_minute)
+  AND end_minute > sqlc.arg(start_minute)
+  AND id <> sqlc.arg(exclude_id)
+  AND (
+    room_id = sqlc.arg(room_id) OR 
+    teacher_email = sqlc.arg(teacher_email) OR 
+    group_id = ANY(sqlc.arg(group_ids)::bigint[]) OR
+    combined_group_ids && sqlc.arg(group_ids)::bigint[]
   )
-) AS conflict_exists;
+ORDER BY id;
 
 -- name: numberofDistinctYears :one
 SELECT COUNT(DISTINCT year) FROM schedules;
@@ -105,3 +148,8 @@ SELECT COUNT(DISTINCT year) FROM schedules;
 -- name: GetDistinctYears :many
 SELECT DISTINCT year FROM schedules
 ORDER BY year;
+
+-- name: ListSchedulesByTerm :many
+SELECT * FROM schedules
+WHERE year = $1 AND term = $2
+ORDER BY day_of_week, start_minute;
@@ -4,9 +4,10 @@ INSERT INTO student_section (
   program,
   year_enrolled,
   group_name,
-  department
+  department,
+  size
 ) VALUES (
-  $1, $2, $3, $4, $5
+  $1, $2, $3, $4, $5, $6
 ) RETURNING *;
 
 -- name: GetStudentSection :one
@@ -25,7 +26,8 @@ SET name = $2,
     program = $3,
     year_enrolled = $4,
     group_name = $5,
-    department = $6
+    department = $6,
+    size = $7
 WHERE id = $1
 RETURNING *;
 
@@ -55,4 +57,33 @@ SELECT count(*) FROM student_section;
 SELECT s.* FROM student s
 JOIN student_section ss ON s.group_id = ss.id
 WHERE ss.id = $1
-ORDER BY s.id;
\ No newline at end of file
+ORDER BY s.id;
+
+-- name: ListAllStudentSections :many
+SELECT * FROM student_section
+ORDER BY id;
+
+-- name: FindStudentSections :many
+SELECT * FROM student_section
+WHERE upper(program) = upper(sqlc.arg(program)::text)
+  AND (sqlc.narg(year_enrolled)::int IS NULL OR year_enrolled = sqlc.narg(year_enrolled)::int)
+  AND (sqlc.narg(group_name)::text IS NULL OR upper(group_name) = upper(sqlc.narg(group_name)::text))
+ORDER BY id;
+
+-- name: ListStudentSectionsByName :many
+SELECT * FROM student_section
+WHERE upper(name) = upper(sqlc.arg(name)::text)
+ORDER BY id;
+
+-- name: GetStudentSectionSize :one
+-- Headcount of a section: the size entered by hand, else its students.
+SELECT COALESCE(ss.size, (SELECT count(*) FROM student st WHERE st.group_id = ss.id))::int4 AS size
+FROM student_section ss
+WHERE ss.id = $1;
+
+-- name: ListStudentSectionSizes :many
+SELECT ss.id, COALESCE(ss.size, count(st.id))::int4 AS size
+FROM student_section ss
+LEFT JOIN student st ON st.group_id = ss.id
+GROUP BY ss.id
+ORDER BY ss.id;
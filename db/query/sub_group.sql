@@ -0,0 +1,49 @@
+-- name: CreateSubGroup :one
+INSERT INTO sub_groups (
+  group_id,
+  name,
+  size
+) VALUES (
+  $1, $2, $3
+) RETURNING *;
+
+-- name: GetSubGroup :one
+SELECT * FROM sub_groups
+WHERE id = $1;
+
+-- name: ListSubGroups :many
+SELECT * FROM sub_groups
+WHERE group_id = $1
+ORDER BY name;
+
+-- name: UpdateSubGroup :one
+UPDATE sub_groups
+SET name = $2, size = $3
+WHERE id = $1
+RETURNING *;
+
+-- name: DeleteSubGroup :execrows
+DELETE FROM sub_groups
+WHERE id = $1;
+
+-- name: GetSubGroupByName :one
+SELECT * FROM sub_groups
+WHERE group_id = $1 AND name = $2;
+
+-- name: ListSubGroupStudents :many
+SELECT s.* FROM student s
+JOIN sub_group_students m ON m.student_id = s.id
+WHERE m.sub_group_id = $1
+ORDER BY s.name, s.id;
+
+-- name: DeleteSubGroupStudents :exec
+DELETE FROM sub_group_students
+WHERE sub_group_id = $1;
+
+-- name: AddSubGroupStudents :execrows
+-- Students not in the sub-group's section are left out; comparing the
+-- count with the ids given tells whether there were any.
+INSERT INTO sub_group_students (sub_group_id, group_id, student_id)
+SELECT sg.id, sg.group_id, s.id FROM sub_groups sg
+JOIN student s ON s.group_id = sg.group_id
+WHERE sg.id = sqlc.arg(sub_group_id) AND s.id = ANY(sqlc.arg(student_ids)::bigint[]);
@@ -64,4 +64,44 @@ ORDER BY st.subject_id, st.teacher_email;
 
 -- name: RemoveTeacherFromSubject :exec
 DELETE FROM subject_teachers
-WHERE subject_id = $1 AND teacher_email = $2;
\ No newline at end of file
+WHERE subject_id = $1 AND teacher_email = $2;
+
+-- name: ListSubjectTeacherAssignments :many
+SELECT st.subject_id, st.teacher_email, sub.department
+FROM subject_teachers st
+JOIN subject sub ON st.subject_id = sub.id
+ORDER BY st.subject_id, st.teacher_email;
+
+-- name: ListAllSubjects :many
+SELECT * FROM subject
+ORDER BY id;
+
+-- name: UpsertSubjectRoomRequirement :one
+INSERT INTO subject_room_requirements (
+  subject_id,
+  session_type,
+  room_type,
+  features
+) VALUES (
+  $1, $2, $3, $4
+)
+ON CONFLICT (subject_id, session_type)
+DO UPDATE SET room_type = EXCLUDED.room_type, features = EXCLUDED.features
+RETURNING *;
+
+-- name: ListSubjectRoomRequirements :many
+SELECT * FROM subject_room_requirements
+WHERE subject_id = $1
+ORDER BY session_type;
+
+-- name: ListAllSubjectRoomRequirements :many
+SELECT * FROM subject_room_requirements
+ORDER BY subject_id, session_type;
+
+-- name: GetSubjectRoomRequirement :one
+SELECT * FROM subject_room_requirements
+WHERE subject_id = $1 AND session_type = $2;
+
+-- name: DeleteSubjectRoomRequirement :execrows
+DELETE FROM subject_room_requirements
+WHERE id = $1 AND subject_id = $2;
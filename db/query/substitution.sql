@@ -0,0 +1,128 @@
+-- name: CreateTeacherAbsence :one
+INSERT INTO teacher_absences (
+  teacher_email,
+  start_date,
+  end_date,
+  reason,
+  created_by
+) VALUES (
+  $1, $2, $3, $4, $5
+) RETURNING *;
+
+-- name: GetTeacherAbsence :one
+SELECT * FROM teacher_absences
+WHERE id = $1 LIMIT 1;
+
+-- name: ListTeacherAbsences :many
+SELECT * FROM teacher_absences
+WHERE (sqlc.narg(teacher_email)::text IS NULL OR teacher_email = sqlc.narg(teacher_email)::text)
+  AND (sqlc.narg(from_date)::date IS NULL OR end_date >= sqlc.narg(from_date)::date)
+  AND (sqlc.narg(to_date)::date IS NULL OR start_date <= sqlc.narg(to_date)::date)
+ORDER BY start_date, id;
+
+-- name: DeleteTeacherAbsence :exec
+DELETE FROM teacher_absences
+WHERE id = $1;
+
+-- name: ListSubstituteCandidates :many
+-- Teachers assigned to the subject who are not absent on the date, have
+-- neither a class nor a cover overlapping the slot and have not marked it
+-- unavailable.
+SELECT t.* FROM teacher t
+JOIN subject_teachers st ON st.teacher_email = t.email
+WHERE st.subject_id = sqlc.arg(subject_id)
+  AND t.email <> sqlc.arg(absent_email)::text
+  AND NOT EXISTS (
+    SELECT 1 FROM teacher_absences a
+    WHERE a.teacher_email = t.email
+      AND sqlc.arg(date)::date BETWEEN a.start_date AND a.end_date
+  )
+  AND NOT EXISTS (
+    SELECT 1 FROM schedules s
+    WHERE s.teacher_email = t.email
+      AND s.year = sqlc.arg(year)
+      AND s.term = sqlc.arg(term)
+      AND s.day_of_week = sqlc.arg(day_of_week)
+      AND s.start_minute < sqlc.arg(end_minute)
+      AND s.end_minute > sqlc.arg(start_minute)
+  )
+  AND NOT EXISTS (
+    SELECT 1 FROM schedule_overrides o
+    JOIN schedules s ON s.id = o.schedule_id
+    WHERE o.substitute_email = t.email
+      AND o.date = sqlc.arg(date)::date
+      AND s.start_minute < sqlc.arg(end_minute)
+      AND s.end_minute > sqlc.arg(start_minute)
+  )
+  AND NOT EXISTS (
+    SELECT 1 FROM teacher_availability av
+    WHERE av.teacher_email = t.email
+      AND av.kind = 'unavailable'
+      AND av.day_of_week = sqlc.arg(day_of_week)
+      AND av.start_minute < sqlc.arg(end_minute)
+      AND av.end_minute > sqlc.arg(start_minute)
+  )
+ORDER BY t.name, t.email;
+
+-- name: UpsertScheduleOverride :one
+INSERT INTO schedule_overrides (
+  absence_id,
+  schedule_id,
+  date,
+  substitute_email,
+  note,
+  created_by
+) VALUES (
+  $1, $2, $3, $4, $5, $6
+)
+ON CONFLICT (schedule_id, date) DO UPDATE
+SET absence_id = EXCLUDED.absence_id,
+    substitute_email = EXCLUDED.substitute_email,
+    note = EXCLUDED.note,
+    created_by = EXCLUDED.created_by,
+    created_at = now()
+RETURNING *;
+
+-- name: DeleteScheduleOverride :exec
+DELETE FROM schedule_overrides
+WHERE id = $1 AND absence_id = $2;
+
+-- name: ListScheduleOverridesByAbsence :many
+SELECT o.*, t.name AS substitute_name
+FROM schedule_overrides o
+LEFT JOIN teacher t ON t.email = o.substitute_email
+WHERE o.absence_id = $1
+ORDER BY o.date, o.schedule_id;
+
+-- name: ListScheduleOverridesOnDate :many
+SELECT o.*, t.name AS substitute_name
+FROM schedule_overrides o
+LEFT JOIN teacher t ON t.email = o.substitute_email
+WHERE o.date = $1
+ORDER BY o.schedule_id;
+
+-- name: GetCoversBySubstitute :many
+-- Sessions a teacher covers for someone else on a date.
+SELECT s.*,
+  t.name AS teacher_name,
+  t.designation AS teacher_designation,
+  r.room_code,
+  r.block_no,
+  sub.subject_code,
+  sub.name AS subject_name,
+  ss.name AS group_name,
+  sg.name AS sub_group_name,
+  ARRAY(
+    SELECT c.name FROM student_section c
+    WHERE c.id = ANY(s.combined_group_ids)
+    ORDER BY c.name
+  )::text[] AS combined_group_names
+FROM schedule_overrides o
+JOIN schedules s ON s.id = o.schedule_id
+JOIN teacher t ON s.teacher_email = t.email
+JOIN room r ON s.room_id = r.id
+JOIN subject sub ON s.subject_id = sub.id
+JOIN student_section ss ON s.group_id = ss.id
+LEFT JOIN sub_groups sg ON s.sub_group_id = sg.id
+WHERE o.substitute_email = $1 AND o.date = $2
+ORDER BY s.start_minute;
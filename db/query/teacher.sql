@@ -18,4 +18,9 @@ DELETE FROM teacher WHERE email = $1
 RETURNING *;
 
 -- name: GetTeachers :many
-SELECT * FROM teacher LIMIT $1 OFFSET $2;
\ No newline at end of file
+SELECT * FROM teacher LIMIT $1 OFFSET $2;
+
+-- name: SearchTeachersByName :many
+SELECT * FROM teacher
+WHERE name ILIKE '%' || sqlc.arg(name)::text || '%'
+ORDER BY name;
@@ -0,0 +1,32 @@
+-- name: CreateTeacherAvailability :one
+INSERT INTO teacher_availability (
+  teacher_email,
+  kind,
+  day_of_week,
+  start_minute,
+  end_minute,
+  note
+) VALUES (
+  $1, $2, $3, $4, $5, $6
+) RETURNING *;
+
+-- name: ListTeacherAvailability :many
+SELECT * FROM teacher_availability
+WHERE teacher_email = $1
+ORDER BY day_of_week, start_minute, id;
+
+-- name: ListAllTeacherAvailability :many
+SELECT * FROM teacher_availability
+ORDER BY teacher_email, day_of_week, start_minute, id;
+
+-- name: DeleteTeacherAvailability :execrows
+DELETE FROM teacher_availability
+WHERE id = $1 AND teacher_email = $2;
+
+-- name: ListTeacherAvailabilityOverlaps :many
+SELECT * FROM teacher_availability
+WHERE teacher_email = sqlc.arg(teacher_email)
+  AND day_of_week = sqlc.arg(day_of_week)
+  AND start_minute < sqlc.arg(end_minute)
+  AND end_minute > sqlc.arg(start_minute)
+ORDER BY start_minute, id;
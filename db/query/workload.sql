@@ -0,0 +1,79 @@
+-- name: CreateWorkloadLimit :one
+INSERT INTO workload_limits (
+  teacher_email,
+  designation,
+  min_periods,
+  max_periods
+) VALUES (
+  $1, $2, $3, $4
+) RETURNING *;
+
+-- name: GetWorkloadLimit :one
+SELECT * FROM workload_limits
+WHERE id = $1;
+
+-- name: ListWorkloadLimits :many
+SELECT * FROM workload_limits
+ORDER BY teacher_email NULLS FIRST, designation;
+
+-- name: UpdateWorkloadLimit :one
+UPDATE workload_limits
+SET min_periods = $2, max_periods = $3, updated_at = now()
+WHERE id = $1
+RETURNING *;
+
+-- name: DeleteWorkloadLimit :execrows
+DELETE FROM workload_limits
+WHERE id = $1;
+
+-- name: GetTeacherWorkloadLimit :one
+-- The limit that applies to a teacher: their own, else their designation's.
+SELECT l.* FROM workload_limits l
+JOIN teacher t ON l.teacher_email = t.email OR l.designation = t.designation
+WHERE t.email = $1
+ORDER BY l.teacher_email IS NULL
+LIMIT 1;
+
+-- name: CountTeacherPeriods :one
+-- The periods a teacher teaches in a term; a class spanning several grid
+-- periods counts each of them.
+SELECT COALESCE(SUM(schedule_periods(group_id, start_minute, end_minute)), 0)::int8 AS periods
+FROM schedules
+WHERE teacher_email = sqlc.arg(teacher_email)
+  AND year = sqlc.arg(year)
+  AND term = sqlc.arg(term)
+  AND id <> sqlc.arg(exclude_id);
+
+-- name: ListTeacherWorkloads :many
+-- One row per teacher with the periods they teach in a term and the limit
+-- that applies to them. Teachers without classes are included. The
+-- sections are all those attending their classes, combined ones too.
+SELECT
+  t.email,
+  t.name,
+  t.department,
+  t.designation,
+  COALESCE(SUM(schedule_periods(s.group_id, s.start_minute, s.end_minute)) FILTER (WHERE s.id IS NOT NULL), 0)::int8 AS periods,
+  COALESCE(SUM(s.end_minute - s.start_minute), 0)::int8 AS minutes,
+  COALESCE(array_agg(DISTINCT sub.subject_code) FILTER (WHERE sub.subject_code IS NOT NULL), '{}')::text[] AS subjects,
+  ARRAY(
+    SELECT DISTINCT c.name FROM schedules cs
+    CROSS JOIN LATERAL unnest(array_prepend(cs.group_id, cs.combined_group_ids)) AS g(group_id)
+    JOIN student_section c ON c.id = g.group_id
+    WHERE cs.teacher_email = t.email AND cs.year = sqlc.arg(year) AND cs.term = sqlc.arg(term) AND c.name IS NOT NULL
+    ORDER BY c.name
+  )::text[] AS sections,
+  wl.min_periods,
+  wl.max_periods
+FROM teacher t
+LEFT JOIN schedules s ON s.teacher_email = t.email AND s.year = sqlc.arg(year) AND s.term = sqlc.arg(term)
+LEFT JOIN subject sub ON sub.id = s.subject_id
+LEFT JOIN LATERAL (
+  SELECT l.min_periods, l.max_periods FROM workload_limits l
+  WHERE l.teacher_email = t.email OR l.designation = t.designation
+  ORDER BY l.teacher_email IS NULL
+  LIMIT 1
+) wl ON true
+WHERE sqlc.narg(department)::text IS NULL OR t.department = sqlc.narg(department)
+GROUP BY t.email, wl.min_periods, wl.max_periods
+ORDER BY t.name, t.email;
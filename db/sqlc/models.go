@@ -8,8 +8,269 @@ import (
 	"database/sql"
 	"database/sql/driver"
 	"fmt"
+	"time"
 )
 
+type AvailabilityKind string
+
+const (
+	AvailabilityKindUnavailable AvailabilityKind = "unavailable"
+	AvailabilityKindPreferred   AvailabilityKind = "preferred"
+	AvailabilityKindDisliked    AvailabilityKind = "disliked"
+)
+
+func (e *AvailabilityKind) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = AvailabilityKind(s)
+	case string:
+		*e = AvailabilityKind(s)
+	default:
+		return fmt.Errorf("unsupported scan type for AvailabilityKind: %T", src)
+	}
+	return nil
+}
+
+type NullAvailabilityKind struct {
+	AvailabilityKind AvailabilityKind `json:"availability_kind"`
+	Valid            bool             `json:"valid"` // Valid is true if AvailabilityKind is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullAvailabilityKind) Scan(value interface{}) error {
+	if value == nil {
+		ns.AvailabilityKind, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.AvailabilityKind.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullAvailabilityKind) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.AvailabilityKind), nil
+}
+
+type CalendarEventKind string
+
+const (
+	CalendarEventKindTerm     CalendarEventKind = "term"
+	CalendarEventKindHoliday  CalendarEventKind = "holiday"
+	CalendarEventKindExamWeek CalendarEventKind = "exam_week"
+	CalendarEventKindClosure  CalendarEventKind = "closure"
+)
+
+func (e *CalendarEventKind) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = CalendarEventKind(s)
+	case string:
+		*e = CalendarEventKind(s)
+	default:
+		return fmt.Errorf("unsupported scan type for CalendarEventKind: %T", src)
+	}
+	return nil
+}
+
+type NullCalendarEventKind struct {
+	CalendarEventKind CalendarEventKind `json:"calendar_event_kind"`
+	Valid             bool              `json:"valid"` // Valid is true if CalendarEventKind is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullCalendarEventKind) Scan(value interface{}) error {
+	if value == nil {
+		ns.CalendarEventKind, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.CalendarEventKind.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullCalendarEventKind) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.CalendarEventKind), nil
+}
+
+type EmailStatus string
+
+const (
+	EmailStatusPending EmailStatus = "pending"
+	EmailStatusSent    EmailStatus = "sent"
+	EmailStatusFailed  EmailStatus = "failed"
+)
+
+func (e *EmailStatus) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = EmailStatus(s)
+	case string:
+		*e = EmailStatus(s)
+	default:
+		return fmt.Errorf("unsupported scan type for EmailStatus: %T", src)
+	}
+	return nil
+}
+
+type NullEmailStatus struct {
+	EmailStatus EmailStatus `json:"email_status"`
+	Valid       bool        `json:"valid"` // Valid is true if EmailStatus is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullEmailStatus) Scan(value interface{}) error {
+	if value == nil {
+		ns.EmailStatus, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.EmailStatus.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullEmailStatus) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.EmailStatus), nil
+}
+
+type RoomType string
+
+const (
+	RoomTypeLectureHall RoomType = "lecture_hall"
+	RoomTypeComputerLab RoomType = "computer_lab"
+	RoomTypeLaboratory  RoomType = "laboratory"
+	RoomTypeWorkshop    RoomType = "workshop"
+)
+
+func (e *RoomType) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = RoomType(s)
+	case string:
+		*e = RoomType(s)
+	default:
+		return fmt.Errorf("unsupported scan type for RoomType: %T", src)
+	}
+	return nil
+}
+
+type NullRoomType struct {
+	RoomType RoomType `json:"room_type"`
+	Valid    bool     `json:"valid"` // Valid is true if RoomType is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullRoomType) Scan(value interface{}) error {
+	if value == nil {
+		ns.RoomType, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.RoomType.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullRoomType) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.RoomType), nil
+}
+
+type SessionType string
+
+const (
+	SessionTypeLecture  SessionType = "lecture"
+	SessionTypeLab      SessionType = "lab"
+	SessionTypeTutorial SessionType = "tutorial"
+)
+
+func (e *SessionType) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = SessionType(s)
+	case string:
+		*e = SessionType(s)
+	default:
+		return fmt.Errorf("unsupported scan type for SessionType: %T", src)
+	}
+	return nil
+}
+
+type NullSessionType struct {
+	SessionType SessionType `json:"session_type"`
+	Valid       bool        `json:"valid"` // Valid is true if SessionType is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullSessionType) Scan(value interface{}) error {
+	if value == nil {
+		ns.SessionType, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.SessionType.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullSessionType) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.SessionType), nil
+}
+
+type Shift string
+
+const (
+	ShiftMorning Shift = "morning"
+	ShiftDay     Shift = "day"
+	ShiftEvening Shift = "evening"
+)
+
+func (e *Shift) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = Shift(s)
+	case string:
+		*e = Shift(s)
+	default:
+		return fmt.Errorf("unsupported scan type for Shift: %T", src)
+	}
+	return nil
+}
+
+type NullShift struct {
+	Shift Shift `json:"shift"`
+	Valid bool  `json:"valid"` // Valid is true if Shift is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullShift) Scan(value interface{}) error {
+	if value == nil {
+		ns.Shift, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.Shift.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullShift) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.Shift), nil
+}
+
 type UserRole string
 
 const (
@@ -53,11 +314,93 @@ func (ns NullUserRole) Value() (driver.Value, error) {
 	return string(ns.UserRole), nil
 }
 
+type AcademicTerm struct {
+	ID          int64          `json:"id"`
+	Year        int32          `json:"year"`
+	Term        int16          `json:"term"`
+	Name        string         `json:"name"`
+	StartDate   time.Time      `json:"start_date"`
+	EndDate     time.Time      `json:"end_date"`
+	StartDateBs sql.NullString `json:"start_date_bs"`
+	EndDateBs   sql.NullString `json:"end_date_bs"`
+}
+
+type CalendarEvent struct {
+	ID          int64             `json:"id"`
+	Kind        CalendarEventKind `json:"kind"`
+	Title       string            `json:"title"`
+	StartDate   time.Time         `json:"start_date"`
+	EndDate     time.Time         `json:"end_date"`
+	Year        int32             `json:"year"`
+	Description sql.NullString    `json:"description"`
+	SourceUid   sql.NullString    `json:"source_uid"`
+}
+
+type CalendarToken struct {
+	Email     string    `json:"email"`
+	Token     string    `json:"token"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type CurriculumRequirement struct {
+	ID             int64       `json:"id"`
+	Program        string      `json:"program"`
+	Semester       int32       `json:"semester"`
+	SubjectID      int64       `json:"subject_id"`
+	SessionType    SessionType `json:"session_type"`
+	PeriodsPerWeek int32       `json:"periods_per_week"`
+}
+
+type EmailOutbox struct {
+	ID            int64          `json:"id"`
+	Recipient     string         `json:"recipient"`
+	Subject       string         `json:"subject"`
+	Body          string         `json:"body"`
+	Status        EmailStatus    `json:"status"`
+	Attempts      int32          `json:"attempts"`
+	LastError     sql.NullString `json:"last_error"`
+	NextAttemptAt time.Time      `json:"next_attempt_at"`
+	CreatedAt     time.Time      `json:"created_at"`
+	SentAt        sql.NullTime   `json:"sent_at"`
+}
+
+type GridPeriod struct {
+	ID          int64  `json:"id"`
+	GridID      int64  `json:"grid_id"`
+	Name        string `json:"name"`
+	StartMinute int32  `json:"start_minute"`
+	EndMinute   int32  `json:"end_minute"`
+	IsBreak     bool   `json:"is_break"`
+}
+
+type NotificationOptOut struct {
+	Email     string    `json:"email"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
 type OauthToken struct {
 	Email        string `json:"email"`
 	RefreshToken string `json:"refresh_token"`
 }
 
+type PublishedSchedule struct {
+	VersionID        int64          `json:"version_id"`
+	ScheduleID       int64          `json:"schedule_id"`
+	GroupID          sql.NullInt64  `json:"group_id"`
+	RoomID           sql.NullInt64  `json:"room_id"`
+	SubjectID        sql.NullInt64  `json:"subject_id"`
+	TeacherEmail     sql.NullString `json:"teacher_email"`
+	TimeSlot         sql.NullString `json:"time_slot"`
+	Year             int32          `json:"year"`
+	DayOfWeek        int16          `json:"day_of_week"`
+	StartMinute      int32          `json:"start_minute"`
+	EndMinute        int32          `json:"end_minute"`
+	SessionType      SessionType    `json:"session_type"`
+	SubGroupID       sql.NullInt64  `json:"sub_group_id"`
+	CombinedGroupIds []int64        `json:"combined_group_ids"`
+	Term             int16          `json:"term"`
+}
+
 type Room struct {
 	ID              int32          `json:"id"`
 	RoomCode        sql.NullString `json:"room_code"`
@@ -65,16 +408,69 @@ type Room struct {
 	Department      sql.NullString `json:"department"`
 	FloorNo         sql.NullInt32  `json:"floor_no"`
 	ScreenAvailable sql.NullBool   `json:"screen_available"`
+	Capacity        sql.NullInt32  `json:"capacity"`
+	RoomType        RoomType       `json:"room_type"`
+}
+
+type RoomBooking struct {
+	ID          int64     `json:"id"`
+	RoomID      int32     `json:"room_id"`
+	Date        time.Time `json:"date"`
+	StartMinute int32     `json:"start_minute"`
+	EndMinute   int32     `json:"end_minute"`
+	Purpose     string    `json:"purpose"`
+	BookedBy    string    `json:"booked_by"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+type RoutineVersion struct {
+	ID          int64          `json:"id"`
+	Year        int32          `json:"year"`
+	Version     int32          `json:"version"`
+	Note        sql.NullString `json:"note"`
+	PublishedBy sql.NullString `json:"published_by"`
+	PublishedAt time.Time      `json:"published_at"`
+	Active      bool           `json:"active"`
+	Term        int16          `json:"term"`
 }
 
 type Schedule struct {
-	ID           int64          `json:"id"`
-	GroupID      sql.NullInt64  `json:"group_id"`
-	RoomID       sql.NullInt64  `json:"room_id"`
-	SubjectID    sql.NullInt64  `json:"subject_id"`
-	TeacherEmail sql.NullString `json:"teacher_email"`
-	TimeSlot     sql.NullString `json:"time_slot"`
-	Year         int32          `json:"year"`
+	ID               int64          `json:"id"`
+	GroupID          sql.NullInt64  `json:"group_id"`
+	RoomID           sql.NullInt64  `json:"room_id"`
+	SubjectID        sql.NullInt64  `json:"subject_id"`
+	TeacherEmail     sql.NullString `json:"teacher_email"`
+	TimeSlot         sql.NullString `json:"time_slot"`
+	Year             int32          `json:"year"`
+	DayOfWeek        int16          `json:"day_of_week"`
+	StartMinute      int32          `json:"start_minute"`
+	EndMinute        int32          `json:"end_minute"`
+	SessionType      SessionType    `json:"session_type"`
+	SubGroupID       sql.NullInt64  `json:"sub_group_id"`
+	CombinedGroupIds []int64        `json:"combined_group_ids"`
+	Term             int16          `json:"term"`
+}
+
+type ScheduleOverride struct {
+	ID              int64          `json:"id"`
+	AbsenceID       int64          `json:"absence_id"`
+	ScheduleID      int64          `json:"schedule_id"`
+	Date            time.Time      `json:"date"`
+	SubstituteEmail sql.NullString `json:"substitute_email"`
+	Note            sql.NullString `json:"note"`
+	CreatedBy       sql.NullString `json:"created_by"`
+	CreatedAt       time.Time      `json:"created_at"`
+}
+
+type ScheduleSection struct {
+	ScheduleID  int64         `json:"schedule_id"`
+	GroupID     int64         `json:"group_id"`
+	SubGroupID  sql.NullInt64 `json:"sub_group_id"`
+	Year        int32         `json:"year"`
+	Term        int16         `json:"term"`
+	DayOfWeek   int16         `json:"day_of_week"`
+	StartMinute int32         `json:"start_minute"`
+	EndMinute   int32         `json:"end_minute"`
 }
 
 type Student struct {
@@ -91,6 +487,20 @@ type StudentSection struct {
 	YearEnrolled sql.NullInt32  `json:"year_enrolled"`
 	GroupName    sql.NullString `json:"group_name"`
 	Department   sql.NullString `json:"department"`
+	Size         sql.NullInt32  `json:"size"`
+}
+
+type SubGroup struct {
+	ID      int64         `json:"id"`
+	GroupID int32         `json:"group_id"`
+	Name    string        `json:"name"`
+	Size    sql.NullInt32 `json:"size"`
+}
+
+type SubGroupStudent struct {
+	SubGroupID int64 `json:"sub_group_id"`
+	GroupID    int32 `json:"group_id"`
+	StudentID  int64 `json:"student_id"`
 }
 
 type Subject struct {
@@ -100,6 +510,14 @@ type Subject struct {
 	Department  sql.NullString `json:"department"`
 }
 
+type SubjectRoomRequirement struct {
+	ID          int64        `json:"id"`
+	SubjectID   int64        `json:"subject_id"`
+	SessionType SessionType  `json:"session_type"`
+	RoomType    NullRoomType `json:"room_type"`
+	Features    []string     `json:"features"`
+}
+
 type SubjectTeacher struct {
 	SubjectID    int64  `json:"subject_id"`
 	TeacherEmail string `json:"teacher_email"`
@@ -112,6 +530,36 @@ type Teacher struct {
 	Designation sql.NullString `json:"designation"`
 }
 
+type TeacherAbsence struct {
+	ID           int64          `json:"id"`
+	TeacherEmail string         `json:"teacher_email"`
+	StartDate    time.Time      `json:"start_date"`
+	EndDate      time.Time      `json:"end_date"`
+	Reason       sql.NullString `json:"reason"`
+	CreatedBy    sql.NullString `json:"created_by"`
+	CreatedAt    time.Time      `json:"created_at"`
+}
+
+type TeacherAvailability struct {
+	ID           int64            `json:"id"`
+	TeacherEmail string           `json:"teacher_email"`
+	Kind         AvailabilityKind `json:"kind"`
+	DayOfWeek    int16            `json:"day_of_week"`
+	StartMinute  int32            `json:"start_minute"`
+	EndMinute    int32            `json:"end_minute"`
+	Note         sql.NullString   `json:"note"`
+	CreatedAt    time.Time        `json:"created_at"`
+}
+
+type TimetableGrid struct {
+	ID        int64    `json:"id"`
+	Name      string   `json:"name"`
+	Shift     Shift    `json:"shift"`
+	Programs  []string `json:"programs"`
+	Days      []int32  `json:"days"`
+	IsDefault bool     `json:"is_default"`
+}
+
 type User struct {
 	Email          string         `json:"email"`
 	Password       string         `json:"password"`
@@ -122,3 +570,12 @@ type User struct {
 	TeacherEmail   sql.NullString `json:"teacher_email"`
 	StudentID      sql.NullInt64  `json:"student_id"`
 }
+
+type WorkloadLimit struct {
+	ID           int64          `json:"id"`
+	TeacherEmail sql.NullString `json:"teacher_email"`
+	Designation  sql.NullString `json:"designation"`
+	MinPeriods   int32          `json:"min_periods"`
+	MaxPeriods   sql.NullInt32  `json:"max_periods"`
+	UpdatedAt    time.Time      `json:"updated_at"`
+}
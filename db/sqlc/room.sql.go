@@ -8,8 +8,26 @@ package db
 import (
 	"context"
 	"database/sql"
+
+	"github.com/lib/pq"
 )
 
+const addRoomFeatures = `-- name: AddRoomFeatures :exec
+INSERT INTO room_features (room_id, feature)
+SELECT $1, unnest($2::text[])
+ON CONFLICT DO NOTHING
+`
+
+type AddRoomFeaturesParams struct {
+	RoomID   int32    `json:"room_id"`
+	Features []string `json:"features"`
+}
+
+func (q *Queries) AddRoomFeatures(ctx context.Context, arg AddRoomFeaturesParams) error {
+	_, err := q.db.ExecContext(ctx, addRoomFeatures, arg.RoomID, pq.Array(arg.Features))
+	return err
+}
+
 const countRooms = `-- name: CountRooms :one
 SELECT count(*) FROM room
 `
@@ -27,14 +45,18 @@ INSERT INTO room (
     block_no,
     floor_no,
     screen_available,
-  department
+  department,
+    capacity,
+    room_type
 ) VALUES (
     $1,
     $2,
     $3,
     $4,
-   $5
-) RETURNING id, room_code, block_no, department, floor_no, screen_available
+   $5,
+    $6,
+    $7
+) RETURNING id, room_code, block_no, department, floor_no, screen_available, capacity, room_type
 `
 
 type CreateRoomParams struct {
@@ -43,6 +65,8 @@ type CreateRoomParams struct {
 	FloorNo         sql.NullInt32  `json:"floor_no"`
 	ScreenAvailable sql.NullBool   `json:"screen_available"`
 	Department      sql.NullString `json:"department"`
+	Capacity        sql.NullInt32  `json:"capacity"`
+	RoomType        RoomType       `json:"room_type"`
 }
 
 func (q *Queries) CreateRoom(ctx context.Context, arg CreateRoomParams) (Room, error) {
@@ -52,6 +76,8 @@ func (q *Queries) CreateRoom(ctx context.Context, arg CreateRoomParams) (Room, e
 		arg.FloorNo,
 		arg.ScreenAvailable,
 		arg.Department,
+		arg.Capacity,
+		arg.RoomType,
 	)
 	var i Room
 	err := row.Scan(
@@ -61,6 +87,8 @@ func (q *Queries) CreateRoom(ctx context.Context, arg CreateRoomParams) (Room, e
 		&i.Department,
 		&i.FloorNo,
 		&i.ScreenAvailable,
+		&i.Capacity,
+		&i.RoomType,
 	)
 	return i, err
 }
@@ -75,8 +103,18 @@ func (q *Queries) DeleteRoom(ctx context.Context, id int32) error {
 	return err
 }
 
+const deleteRoomFeatures = `-- name: DeleteRoomFeatures :exec
+DELETE FROM room_features
+WHERE room_id = $1
+`
+
+func (q *Queries) DeleteRoomFeatures(ctx context.Context, roomID int32) error {
+	_, err := q.db.ExecContext(ctx, deleteRoomFeatures, roomID)
+	return err
+}
+
 const getRoom = `-- name: GetRoom :one
-SELECT id, room_code, block_no, department, floor_no, screen_available FROM room
+SELECT id, room_code, block_no, department, floor_no, screen_available, capacity, room_type FROM room
 WHERE id = $1 LIMIT 1
 `
 
@@ -90,12 +128,36 @@ func (q *Queries) GetRoom(ctx context.Context, id int32) (Room, error) {
 		&i.Department,
 		&i.FloorNo,
 		&i.ScreenAvailable,
+		&i.Capacity,
+		&i.RoomType,
+	)
+	return i, err
+}
+
+const getRoomByCode = `-- name: GetRoomByCode :one
+SELECT id, room_code, block_no, department, floor_no, screen_available, capacity, room_type FROM room
+WHERE upper(room_code) = upper($1::text)
+LIMIT 1
+`
+
+func (q *Queries) GetRoomByCode(ctx context.Context, roomCode string) (Room, error) {
+	row := q.db.QueryRowContext(ctx, getRoomByCode, roomCode)
+	var i Room
+	err := row.Scan(
+		&i.ID,
+		&i.RoomCode,
+		&i.BlockNo,
+		&i.Department,
+		&i.FloorNo,
+		&i.ScreenAvailable,
+		&i.Capacity,
+		&i.RoomType,
 	)
 	return i, err
 }
 
 const getRoomsByDepartment = `-- name: GetRoomsByDepartment :many
-SELECT id, room_code, block_no, department, floor_no, screen_available FROM room
+SELECT id, room_code, block_no, department, floor_no, screen_available, capacity, room_type FROM room
 WHERE department = $1
 ORDER BY id
 `
@@ -116,6 +178,81 @@ func (q *Queries) GetRoomsByDepartment(ctx context.Context, department sql.NullS
 			&i.Department,
 			&i.FloorNo,
 			&i.ScreenAvailable,
+			&i.Capacity,
+			&i.RoomType,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listAllRoomFeatures = `-- name: ListAllRoomFeatures :many
+SELECT room_id, feature FROM room_features
+ORDER BY room_id, feature
+`
+
+type ListAllRoomFeaturesRow struct {
+	RoomID  int32  `json:"room_id"`
+	Feature string `json:"feature"`
+}
+
+func (q *Queries) ListAllRoomFeatures(ctx context.Context) ([]ListAllRoomFeaturesRow, error) {
+	rows, err := q.db.QueryContext(ctx, listAllRoomFeatures)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListAllRoomFeaturesRow
+	for rows.Next() {
+		var i ListAllRoomFeaturesRow
+		if err := rows.Scan(
+			&i.RoomID,
+			&i.Feature,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listAllRooms = `-- name: ListAllRooms :many
+SELECT id, room_code, block_no, department, floor_no, screen_available, capacity, room_type FROM room
+ORDER BY id
+`
+
+func (q *Queries) ListAllRooms(ctx context.Context) ([]Room, error) {
+	rows, err := q.db.QueryContext(ctx, listAllRooms)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Room
+	for rows.Next() {
+		var i Room
+		if err := rows.Scan(
+			&i.ID,
+			&i.RoomCode,
+			&i.BlockNo,
+			&i.Department,
+			&i.FloorNo,
+			&i.ScreenAvailable,
+			&i.Capacity,
+			&i.RoomType,
 		); err != nil {
 			return nil, err
 		}
@@ -130,8 +267,123 @@ func (q *Queries) GetRoomsByDepartment(ctx context.Context, department sql.NullS
 	return items, nil
 }
 
+const listFreeRooms = `-- name: ListFreeRooms :many
+SELECT r.id, r.room_code, r.block_no, r.department, r.floor_no, r.screen_available, r.capacity, r.room_type FROM room r
+WHERE ($1::text IS NULL OR upper(r.block_no) = upper($1::text))
+  AND ($2::int IS NULL OR r.floor_no = $2::int)
+  AND ($3::text IS NULL OR upper(r.department) = upper($3::text))
+  AND ($4::bool IS NULL OR r.screen_available = $4::bool)
+  AND ($5::int IS NULL OR r.capacity >= $5::int)
+  AND NOT EXISTS (
+    SELECT 1 FROM schedules s
+    WHERE s.room_id = r.id
+      AND s.year = $6
+      AND s.term = $7
+      AND s.day_of_week = $8
+      AND s.start_minute < $9
+      AND s.end_minute > $10
+  )
+  AND ($11::date IS NULL OR NOT EXISTS (
+    SELECT 1 FROM room_bookings b
+    WHERE b.room_id = r.id
+      AND b.date = $11::date
+      AND b.start_minute < $9
+      AND b.end_minute > $10
+  ))
+ORDER BY r.block_no, r.floor_no, r.room_code
+`
+
+type ListFreeRoomsParams struct {
+	BlockNo         sql.NullString `json:"block_no"`
+	FloorNo         sql.NullInt32  `json:"floor_no"`
+	Department      sql.NullString `json:"department"`
+	ScreenAvailable sql.NullBool   `json:"screen_available"`
+	MinCapacity     sql.NullInt32  `json:"min_capacity"`
+	Year            int32          `json:"year"`
+	Term            int16          `json:"term"`
+	DayOfWeek       int16          `json:"day_of_week"`
+	EndMinute       int32          `json:"end_minute"`
+	StartMinute     int32          `json:"start_minute"`
+	Date            sql.NullTime   `json:"date"`
+}
+
+// Rooms with no weekly class overlapping the slot and, when a date is
+// given, no booking overlapping it on that date.
+func (q *Queries) ListFreeRooms(ctx context.Context, arg ListFreeRoomsParams) ([]Room, error) {
+	rows, err := q.db.QueryContext(ctx, listFreeRooms,
+		arg.BlockNo,
+		arg.FloorNo,
+		arg.Department,
+		arg.ScreenAvailable,
+		arg.MinCapacity,
+		arg.Year,
+		arg.Term,
+		arg.DayOfWeek,
+		arg.EndMinute,
+		arg.StartMinute,
+		arg.Date,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Room
+	for rows.Next() {
+		var i Room
+		if err := rows.Scan(
+			&i.ID,
+			&i.RoomCode,
+			&i.BlockNo,
+			&i.Department,
+			&i.FloorNo,
+			&i.ScreenAvailable,
+			&i.Capacity,
+			&i.RoomType,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listRoomFeatures = `-- name: ListRoomFeatures :many
+SELECT feature FROM room_features
+WHERE room_id = $1
+ORDER BY feature
+`
+
+func (q *Queries) ListRoomFeatures(ctx context.Context, roomID int32) ([]string, error) {
+	rows, err := q.db.QueryContext(ctx, listRoomFeatures, roomID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []string
+	for rows.Next() {
+		var feature string
+		if err := rows.Scan(&feature); err != nil {
+			return nil, err
+		}
+		items = append(items, feature)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const listRooms = `-- name: ListRooms :many
-SELECT id, room_code, block_no, department, floor_no, screen_available FROM room
+SELECT id, room_code, block_no, department, floor_no, screen_available, capacity, room_type FROM room
 ORDER BY id
 LIMIT $1
 OFFSET $2
@@ -158,6 +410,8 @@ func (q *Queries) ListRooms(ctx context.Context, arg ListRoomsParams) ([]Room, e
 			&i.Department,
 			&i.FloorNo,
 			&i.ScreenAvailable,
+			&i.Capacity,
+			&i.RoomType,
 		); err != nil {
 			return nil, err
 		}
@@ -179,8 +433,10 @@ SET department = $2
   , block_no = $4
   , floor_no = $5
   , screen_available = $6
+  , capacity = $7
+  , room_type = $8
 WHERE id = $1
-RETURNING id, room_code, block_no, department, floor_no, screen_available
+RETURNING id, room_code, block_no, department, floor_no, screen_available, capacity, room_type
 `
 
 type UpdateRoomParams struct {
@@ -190,6 +446,8 @@ type UpdateRoomParams struct {
 	BlockNo         sql.NullString `json:"block_no"`
 	FloorNo         sql.NullInt32  `json:"floor_no"`
 	ScreenAvailable sql.NullBool   `json:"screen_available"`
+	Capacity        sql.NullInt32  `json:"capacity"`
+	RoomType        RoomType       `json:"room_type"`
 }
 
 func (q *Queries) UpdateRoom(ctx context.Context, arg UpdateRoomParams) (Room, error) {
@@ -200,6 +458,8 @@ func (q *Queries) UpdateRoom(ctx context.Context, arg UpdateRoomParams) (Room, e
 		arg.BlockNo,
 		arg.FloorNo,
 		arg.ScreenAvailable,
+		arg.Capacity,
+		arg.RoomType,
 	)
 	var i Room
 	err := row.Scan(
@@ -209,6 +469,8 @@ func (q *Queries) UpdateRoom(ctx context.Context, arg UpdateRoomParams) (Room, e
 		&i.Department,
 		&i.FloorNo,
 		&i.ScreenAvailable,
+		&i.Capacity,
+		&i.RoomType,
 	)
 	return i, err
 }
@@ -0,0 +1,175 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"sort"
+	"testing"
+	"time"
+
+	_ "github.com/lib/pq"
+)
+
+// testTx returns queries inside a transaction that is rolled back when the
+// test ends. The tests need a migrated database in TEST_DB_SOURCE and are
+// skipped without one.
+func testTx(t *testing.T) *Queries {
+	t.Helper()
+	source := os.Getenv("TEST_DB_SOURCE")
+	if source == "" {
+		t.Skip("TEST_DB_SOURCE is not set")
+	}
+	conn, err := sql.Open("postgres", source)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tx, err := conn.Begin()
+	if err != nil {
+		conn.Close()
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		tx.Rollback()
+		conn.Close()
+	})
+	return New(tx)
+}
+
+const testYear = 2999
+
+// createTestTerms creates both terms of testYear.
+func createTestTerms(t *testing.T, q *Queries) {
+	t.Helper()
+	ctx := context.Background()
+	for term := int16(1); term <= 2; term++ {
+		start := time.Date(testYear-57, time.April, 14, 0, 0, 0, 0, time.UTC).AddDate(0, 6*int(term-1), 0)
+		if _, err := q.CreateAcademicTerm(ctx, CreateAcademicTermParams{
+			Year:      testYear,
+			Term:      term,
+			Name:      "Test",
+			StartDate: start,
+			EndDate:   start.AddDate(0, 6, -1),
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// publishTestTerm creates term 1 of testYear with a class on each of the
+// given days and publishes it, returning the classes and the version.
+func publishTestTerm(t *testing.T, q *Queries, days ...int16) ([]Schedule, RoutineVersion) {
+	t.Helper()
+	ctx := context.Background()
+	createTestTerms(t, q)
+	var schedules []Schedule
+	for _, day := range days {
+		s, err := q.CreateSchedule(ctx, CreateScheduleParams{
+			Year:        testYear,
+			Term:        1,
+			DayOfWeek:   day,
+			StartMinute: 16*60 + 15,
+			EndMinute:   17*60 + 55,
+			SessionType: SessionTypeLecture,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		schedules = append(schedules, s)
+	}
+	version, err := q.CreateRoutineVersion(ctx, CreateRoutineVersionParams{Year: testYear, Term: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := q.SnapshotSchedules(ctx, SnapshotSchedulesParams{VersionID: version.ID, Year: testYear, Term: 1}); err != nil {
+		t.Fatal(err)
+	}
+	return schedules, version
+}
+
+func restoreTestTerm(t *testing.T, q *Queries, version RoutineVersion) []int64 {
+	t.Helper()
+	ctx := context.Background()
+	if err := q.DeleteSchedulesByTerm(ctx, DeleteSchedulesByTermParams{Year: testYear, Term: 1}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := q.RestoreSchedulesFromVersion(ctx, version.ID); err != nil {
+		t.Fatal(err)
+	}
+	restored, err := q.ListSchedulesByTerm(context.Background(), ListSchedulesByTermParams{Year: testYear, Term: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ids := make([]int64, 0, len(restored))
+	for _, s := range restored {
+		ids = append(ids, s.ID)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
+
+func TestRestoreSchedulesKeepsIDs(t *testing.T) {
+	q := testTx(t)
+	schedules, version := publishTestTerm(t, q, 0, 1, 2)
+
+	// the last class moves to term 2 after publishing, keeping its id
+	moved := schedules[2]
+	if _, err := q.UpdateSchedule(context.Background(), UpdateScheduleParams{
+		ID:          moved.ID,
+		DayOfWeek:   moved.DayOfWeek,
+		StartMinute: moved.StartMinute,
+		EndMinute:   moved.EndMinute,
+		Year:        testYear,
+		Term:        2,
+		SessionType: moved.SessionType,
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	ids := restoreTestTerm(t, q, version)
+	if len(ids) != 3 {
+		t.Fatalf("restored %d schedules, want 3", len(ids))
+	}
+	if ids[0] != schedules[0].ID || ids[1] != schedules[1].ID {
+		t.Errorf("restored ids %v, want %d and %d kept", ids, schedules[0].ID, schedules[1].ID)
+	}
+	if ids[2] == moved.ID {
+		t.Errorf("restored a second schedule with id %d", moved.ID)
+	}
+}
+
+func TestRestoreSchedulesKeepsCovers(t *testing.T) {
+	q := testTx(t)
+	ctx := context.Background()
+	schedules, version := publishTestTerm(t, q, 0)
+
+	teacher, err := q.CreateTeacher(ctx, CreateTeacherParams{Email: "restore-test@example.com"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	date := time.Date(testYear-57, time.May, 3, 0, 0, 0, 0, time.UTC)
+	absence, err := q.CreateTeacherAbsence(ctx, CreateTeacherAbsenceParams{
+		TeacherEmail: teacher.Email,
+		StartDate:    date,
+		EndDate:      date,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := q.UpsertScheduleOverride(ctx, UpsertScheduleOverrideParams{
+		AbsenceID:  absence.ID,
+		ScheduleID: schedules[0].ID,
+		Date:       date,
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	ids := restoreTestTerm(t, q, version)
+	covers, err := q.ListScheduleOverridesOnDate(ctx, date)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(covers) != 1 || len(ids) != 1 || covers[0].ScheduleID != ids[0] {
+		t.Errorf("cover points at schedule %v after restoring %v", covers, ids)
+	}
+}
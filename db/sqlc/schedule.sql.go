@@ -8,37 +8,9 @@ package db
 import (
 	"context"
 	"database/sql"
-)
-
-const checkScheduleConflicts = `-- name: CheckScheduleConflicts :one
-SELECT EXISTS (
-  SELECT 1 FROM schedules
-  WHERE time_slot = $1 AND (
-    room_id = $2 OR 
-    teacher_email = $3 OR 
-    group_id = $4
-  )
-) AS conflict_exists
-`
 
-type CheckScheduleConflictsParams struct {
-	TimeSlot     sql.NullString `json:"time_slot"`
-	RoomID       sql.NullInt64  `json:"room_id"`
-	TeacherEmail sql.NullString `json:"teacher_email"`
-	GroupID      sql.NullInt64  `json:"group_id"`
-}
-
-func (q *Queries) CheckScheduleConflicts(ctx context.Context, arg CheckScheduleConflictsParams) (bool, error) {
-	row := q.db.QueryRowContext(ctx, checkScheduleConflicts,
-		arg.TimeSlot,
-		arg.RoomID,
-		arg.TeacherEmail,
-		arg.GroupID,
-	)
-	var conflict_exists bool
-	err := row.Scan(&conflict_exists)
-	return conflict_exists, err
-}
+	"github.com/lib/pq"
+)
 
 const countSchedules = `-- name: CountSchedules :one
 SELECT count(*) FROM schedules
@@ -58,19 +30,33 @@ INSERT INTO schedules (
   subject_id,
   teacher_email,
   time_slot,
-  year
+  year,
+  day_of_week,
+  start_minute,
+  end_minute,
+  session_type,
+  sub_group_id,
+  combined_group_ids,
+  term
 ) VALUES (
-  $1, $2, $3, $4, $5, $6
-) RETURNING id, group_id, room_id, subject_id, teacher_email, time_slot, year
+  $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13
+) RETURNING id, group_id, room_id, subject_id, teacher_email, time_slot, year, day_of_week, start_minute, end_minute, session_type, sub_group_id, combined_group_ids, term
 `
 
 type CreateScheduleParams struct {
-	GroupID      sql.NullInt64  `json:"group_id"`
-	RoomID       sql.NullInt64  `json:"room_id"`
-	SubjectID    sql.NullInt64  `json:"subject_id"`
-	TeacherEmail sql.NullString `json:"teacher_email"`
-	TimeSlot     sql.NullString `json:"time_slot"`
-	Year         int32          `json:"year"`
+	GroupID          sql.NullInt64  `json:"group_id"`
+	RoomID           sql.NullInt64  `json:"room_id"`
+	SubjectID        sql.NullInt64  `json:"subject_id"`
+	TeacherEmail     sql.NullString `json:"teacher_email"`
+	TimeSlot         sql.NullString `json:"time_slot"`
+	Year             int32          `json:"year"`
+	DayOfWeek        int16          `json:"day_of_week"`
+	StartMinute      int32          `json:"start_minute"`
+	EndMinute        int32          `json:"end_minute"`
+	SessionType      SessionType    `json:"session_type"`
+	SubGroupID       sql.NullInt64  `json:"sub_group_id"`
+	CombinedGroupIds []int64        `json:"combined_group_ids"`
+	Term             int16          `json:"term"`
 }
 
 func (q *Queries) CreateSchedule(ctx context.Context, arg CreateScheduleParams) (Schedule, error) {
@@ -81,6 +67,13 @@ func (q *Queries) CreateSchedule(ctx context.Context, arg CreateScheduleParams)
 		arg.TeacherEmail,
 		arg.TimeSlot,
 		arg.Year,
+		arg.DayOfWeek,
+		arg.StartMinute,
+		arg.EndMinute,
+		arg.SessionType,
+		arg.SubGroupID,
+		pq.Array(arg.CombinedGroupIds),
+		arg.Term,
 	)
 	var i Schedule
 	err := row.Scan(
@@ -91,6 +84,13 @@ func (q *Queries) CreateSchedule(ctx context.Context, arg CreateScheduleParams)
 		&i.TeacherEmail,
 		&i.TimeSlot,
 		&i.Year,
+		&i.DayOfWeek,
+		&i.StartMinute,
+		&i.EndMinute,
+		&i.SessionType,
+		&i.SubGroupID,
+		pq.Array(&i.CombinedGroupIds),
+		&i.Term,
 	)
 	return i, err
 }
@@ -134,7 +134,7 @@ func (q *Queries) GetDistinctYears(ctx context.Context) ([]int32, error) {
 }
 
 const getSchedule = `-- name: GetSchedule :one
-SELECT id, group_id, room_id, subject_id, teacher_email, time_slot, year FROM schedules
+SELECT id, group_id, room_id, subject_id, teacher_email, time_slot, year, day_of_week, start_minute, end_minute, session_type, sub_group_id, combined_group_ids, term FROM schedules
 WHERE id = $1 LIMIT 1
 `
 
@@ -149,31 +149,46 @@ func (q *Queries) GetSchedule(ctx context.Context, id int64) (Schedule, error) {
 		&i.TeacherEmail,
 		&i.TimeSlot,
 		&i.Year,
+		&i.DayOfWeek,
+		&i.StartMinute,
+		&i.EndMinute,
+		&i.SessionType,
+		&i.SubGroupID,
+		pq.Array(&i.CombinedGroupIds),
+		&i.Term,
 	)
 	return i, err
 }
 
 const getSchedulesByGroup = `-- name: GetSchedulesByGroup :many
-SELECT s.id, s.group_id, s.room_id, s.subject_id, s.teacher_email, s.time_slot, s.year, 
+SELECT s.id, s.group_id, s.room_id, s.subject_id, s.teacher_email, s.time_slot, s.year, s.day_of_week, s.start_minute, s.end_minute, s.session_type, s.sub_group_id, s.combined_group_ids, s.term, 
   t.name AS teacher_name,
   t.designation AS teacher_designation,
   r.room_code,
   r.block_no,
   sub.subject_code,
   sub.name AS subject_name,
-  ss.name AS group_name
+  ss.name AS group_name,
+  sg.name AS sub_group_name,
+  ARRAY(
+    SELECT c.name FROM student_section c
+    WHERE c.id = ANY(s.combined_group_ids)
+    ORDER BY c.name
+  )::text[] AS combined_group_names
 FROM schedules s
 JOIN teacher t ON s.teacher_email = t.email
 JOIN room r ON s.room_id = r.id
 JOIN subject sub ON s.subject_id = sub.id
 JOIN student_section ss ON s.group_id = ss.id
-WHERE s.group_id = $1 AND s.year = $2
-ORDER BY s.time_slot
+LEFT JOIN sub_groups sg ON s.sub_group_id = sg.id
+WHERE (s.group_id = $1 OR $1 = ANY(s.combined_group_ids)) AND s.year = $2 AND s.term = $3
+ORDER BY s.day_of_week, s.start_minute
 `
 
 type GetSchedulesByGroupParams struct {
 	GroupID sql.NullInt64 `json:"group_id"`
 	Year    int32         `json:"year"`
+	Term    int16         `json:"term"`
 }
 
 type GetSchedulesByGroupRow struct {
@@ -184,6 +199,13 @@ type GetSchedulesByGroupRow struct {
 	TeacherEmail       sql.NullString `json:"teacher_email"`
 	TimeSlot           sql.NullString `json:"time_slot"`
 	Year               int32          `json:"year"`
+	DayOfWeek          int16          `json:"day_of_week"`
+	StartMinute        int32          `json:"start_minute"`
+	EndMinute          int32          `json:"end_minute"`
+	SessionType        SessionType    `json:"session_type"`
+	SubGroupID         sql.NullInt64  `json:"sub_group_id"`
+	CombinedGroupIds   []int64        `json:"combined_group_ids"`
+	Term               int16          `json:"term"`
 	TeacherName        sql.NullString `json:"teacher_name"`
 	TeacherDesignation sql.NullString `json:"teacher_designation"`
 	RoomCode           sql.NullString `json:"room_code"`
@@ -191,10 +213,16 @@ type GetSchedulesByGroupRow struct {
 	SubjectCode        sql.NullString `json:"subject_code"`
 	SubjectName        sql.NullString `json:"subject_name"`
 	GroupName          sql.NullString `json:"group_name"`
+	SubGroupName       sql.NullString `json:"sub_group_name"`
+	CombinedGroupNames []string       `json:"combined_group_names"`
 }
 
 func (q *Queries) GetSchedulesByGroup(ctx context.Context, arg GetSchedulesByGroupParams) ([]GetSchedulesByGroupRow, error) {
-	rows, err := q.db.QueryContext(ctx, getSchedulesByGroup, arg.GroupID, arg.Year)
+	rows, err := q.db.QueryContext(ctx, getSchedulesByGroup,
+		arg.GroupID,
+		arg.Year,
+		arg.Term,
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -210,6 +238,13 @@ func (q *Queries) GetSchedulesByGroup(ctx context.Context, arg GetSchedulesByGro
 			&i.TeacherEmail,
 			&i.TimeSlot,
 			&i.Year,
+			&i.DayOfWeek,
+			&i.StartMinute,
+			&i.EndMinute,
+			&i.SessionType,
+			&i.SubGroupID,
+			pq.Array(&i.CombinedGroupIds),
+			&i.Term,
 			&i.TeacherName,
 			&i.TeacherDesignation,
 			&i.RoomCode,
@@ -217,6 +252,8 @@ func (q *Queries) GetSchedulesByGroup(ctx context.Context, arg GetSchedulesByGro
 			&i.SubjectCode,
 			&i.SubjectName,
 			&i.GroupName,
+			&i.SubGroupName,
+			pq.Array(&i.CombinedGroupNames),
 		); err != nil {
 			return nil, err
 		}
@@ -232,26 +269,34 @@ func (q *Queries) GetSchedulesByGroup(ctx context.Context, arg GetSchedulesByGro
 }
 
 const getSchedulesByRoom = `-- name: GetSchedulesByRoom :many
-SELECT s.id, s.group_id, s.room_id, s.subject_id, s.teacher_email, s.time_slot, s.year, 
+SELECT s.id, s.group_id, s.room_id, s.subject_id, s.teacher_email, s.time_slot, s.year, s.day_of_week, s.start_minute, s.end_minute, s.session_type, s.sub_group_id, s.combined_group_ids, s.term, 
   t.name AS teacher_name,
   t.designation AS teacher_designation,
   r.room_code,
   r.block_no,
   sub.subject_code,
   sub.name AS subject_name,
-  ss.name AS group_name
+  ss.name AS group_name,
+  sg.name AS sub_group_name,
+  ARRAY(
+    SELECT c.name FROM student_section c
+    WHERE c.id = ANY(s.combined_group_ids)
+    ORDER BY c.name
+  )::text[] AS combined_group_names
 FROM schedules s
 JOIN teacher t ON s.teacher_email = t.email
 JOIN room r ON s.room_id = r.id
 JOIN subject sub ON s.subject_id = sub.id
 JOIN student_section ss ON s.group_id = ss.id
-WHERE s.room_id = $1 AND s.year = $2
-ORDER BY s.time_slot
+LEFT JOIN sub_groups sg ON s.sub_group_id = sg.id
+WHERE s.room_id = $1 AND s.year = $2 AND s.term = $3
+ORDER BY s.day_of_week, s.start_minute
 `
 
 type GetSchedulesByRoomParams struct {
 	RoomID sql.NullInt64 `json:"room_id"`
 	Year   int32         `json:"year"`
+	Term   int16         `json:"term"`
 }
 
 type GetSchedulesByRoomRow struct {
@@ -262,6 +307,13 @@ type GetSchedulesByRoomRow struct {
 	TeacherEmail       sql.NullString `json:"teacher_email"`
 	TimeSlot           sql.NullString `json:"time_slot"`
 	Year               int32          `json:"year"`
+	DayOfWeek          int16          `json:"day_of_week"`
+	StartMinute        int32          `json:"start_minute"`
+	EndMinute          int32          `json:"end_minute"`
+	SessionType        SessionType    `json:"session_type"`
+	SubGroupID         sql.NullInt64  `json:"sub_group_id"`
+	CombinedGroupIds   []int64        `json:"combined_group_ids"`
+	Term               int16          `json:"term"`
 	TeacherName        sql.NullString `json:"teacher_name"`
 	TeacherDesignation sql.NullString `json:"teacher_designation"`
 	RoomCode           sql.NullString `json:"room_code"`
@@ -269,10 +321,16 @@ type GetSchedulesByRoomRow struct {
 	SubjectCode        sql.NullString `json:"subject_code"`
 	SubjectName        sql.NullString `json:"subject_name"`
 	GroupName          sql.NullString `json:"group_name"`
+	SubGroupName       sql.NullString `json:"sub_group_name"`
+	CombinedGroupNames []string       `json:"combined_group_names"`
 }
 
 func (q *Queries) GetSchedulesByRoom(ctx context.Context, arg GetSchedulesByRoomParams) ([]GetSchedulesByRoomRow, error) {
-	rows, err := q.db.QueryContext(ctx, getSchedulesByRoom, arg.RoomID, arg.Year)
+	rows, err := q.db.QueryContext(ctx, getSchedulesByRoom,
+		arg.RoomID,
+		arg.Year,
+		arg.Term,
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -288,6 +346,13 @@ func (q *Queries) GetSchedulesByRoom(ctx context.Context, arg GetSchedulesByRoom
 			&i.TeacherEmail,
 			&i.TimeSlot,
 			&i.Year,
+			&i.DayOfWeek,
+			&i.StartMinute,
+			&i.EndMinute,
+			&i.SessionType,
+			&i.SubGroupID,
+			pq.Array(&i.CombinedGroupIds),
+			&i.Term,
 			&i.TeacherName,
 			&i.TeacherDesignation,
 			&i.RoomCode,
@@ -295,6 +360,8 @@ func (q *Queries) GetSchedulesByRoom(ctx context.Context, arg GetSchedulesByRoom
 			&i.SubjectCode,
 			&i.SubjectName,
 			&i.GroupName,
+			&i.SubGroupName,
+			pq.Array(&i.CombinedGroupNames),
 		); err != nil {
 			return nil, err
 		}
@@ -310,26 +377,34 @@ func (q *Queries) GetSchedulesByRoom(ctx context.Context, arg GetSchedulesByRoom
 }
 
 const getSchedulesByTeacher = `-- name: GetSchedulesByTeacher :many
-SELECT s.id, s.group_id, s.room_id, s.subject_id, s.teacher_email, s.time_slot, s.year, 
+SELECT s.id, s.group_id, s.room_id, s.subject_id, s.teacher_email, s.time_slot, s.year, s.day_of_week, s.start_minute, s.end_minute, s.session_type, s.sub_group_id, s.combined_group_ids, s.term, 
   t.name AS teacher_name,
   t.designation AS teacher_designation,
   r.room_code,
   r.block_no,
   sub.subject_code,
   sub.name AS subject_name,
-  ss.name AS group_name
+  ss.name AS group_name,
+  sg.name AS sub_group_name,
+  ARRAY(
+    SELECT c.name FROM student_section c
+    WHERE c.id = ANY(s.combined_group_ids)
+    ORDER BY c.name
+  )::text[] AS combined_group_names
 FROM schedules s
 JOIN teacher t ON s.teacher_email = t.email
 JOIN room r ON s.room_id = r.id
 JOIN subject sub ON s.subject_id = sub.id
 JOIN student_section ss ON s.group_id = ss.id
-WHERE s.teacher_email = $1 AND s.year = $2
-ORDER BY s.time_slot
+LEFT JOIN sub_groups sg ON s.sub_group_id = sg.id
+WHERE s.teacher_email = $1 AND s.year = $2 AND s.term = $3
+ORDER BY s.day_of_week, s.start_minute
 `
 
 type GetSchedulesByTeacherParams struct {
 	TeacherEmail sql.NullString `json:"teacher_email"`
 	Year         int32          `json:"year"`
+	Term         int16          `json:"term"`
 }
 
 type GetSchedulesByTeacherRow struct {
@@ -340,6 +415,13 @@ type GetSchedulesByTeacherRow struct {
 	TeacherEmail       sql.NullString `json:"teacher_email"`
 	TimeSlot           sql.NullString `json:"time_slot"`
 	Year               int32          `json:"year"`
+	DayOfWeek          int16          `json:"day_of_week"`
+	StartMinute        int32          `json:"start_minute"`
+	EndMinute          int32          `json:"end_minute"`
+	SessionType        SessionType    `json:"session_type"`
+	SubGroupID         sql.NullInt64  `json:"sub_group_id"`
+	CombinedGroupIds   []int64        `json:"combined_group_ids"`
+	Term               int16          `json:"term"`
 	TeacherName        sql.NullString `json:"teacher_name"`
 	TeacherDesignation sql.NullString `json:"teacher_designation"`
 	RoomCode           sql.NullString `json:"room_code"`
@@ -347,10 +429,16 @@ type GetSchedulesByTeacherRow struct {
 	SubjectCode        sql.NullString `json:"subject_code"`
 	SubjectName        sql.NullString `json:"subject_name"`
 	GroupName          sql.NullString `json:"group_name"`
+	SubGroupName       sql.NullString `json:"sub_group_name"`
+	CombinedGroupNames []string       `json:"combined_group_names"`
 }
 
 func (q *Queries) GetSchedulesByTeacher(ctx context.Context, arg GetSchedulesByTeacherParams) ([]GetSchedulesByTeacherRow, error) {
-	rows, err := q.db.QueryContext(ctx, getSchedulesByTeacher, arg.TeacherEmail, arg.Year)
+	rows, err := q.db.QueryContext(ctx, getSchedulesByTeacher,
+		arg.TeacherEmail,
+		arg.Year,
+		arg.Term,
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -366,6 +454,13 @@ func (q *Queries) GetSchedulesByTeacher(ctx context.Context, arg GetSchedulesByT
 			&i.TeacherEmail,
 			&i.TimeSlot,
 			&i.Year,
+			&i.DayOfWeek,
+			&i.StartMinute,
+			&i.EndMinute,
+			&i.SessionType,
+			&i.SubGroupID,
+			pq.Array(&i.CombinedGroupIds),
+			&i.Term,
 			&i.TeacherName,
 			&i.TeacherDesignation,
 			&i.RoomCode,
@@ -373,6 +468,89 @@ func (q *Queries) GetSchedulesByTeacher(ctx context.Context, arg GetSchedulesByT
 			&i.SubjectCode,
 			&i.SubjectName,
 			&i.GroupName,
+			&i.SubGroupName,
+			pq.Array(&i.CombinedGroupNames),
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listScheduleConflicts = `-- name: ListScheduleConflicts :many
+SELECT id, time_slot, room_id, teacher_email, group_id, sub_group_id, combined_group_ids
+FROM schedules
+WHERE year = $1
+  AND term = $2
+  AND day_of_week = $3
+  AND start_minute < $4
+  AND end_minute > $5
+  AND id <> $6
+  AND (
+    room_id = $7 OR 
+    teacher_email = $8 OR 
+    group_id = ANY($9::bigint[]) OR
+    combined_group_ids && $9::bigint[]
+  )
+ORDER BY id
+`
+
+type ListScheduleConflictsParams struct {
+	Year         int32          `json:"year"`
+	Term         int16          `json:"term"`
+	DayOfWeek    int16          `json:"day_of_week"`
+	EndMinute    int32          `json:"end_minute"`
+	StartMinute  int32          `json:"start_minute"`
+	ExcludeID    int64          `json:"exclude_id"`
+	RoomID       sql.NullInt64  `json:"room_id"`
+	TeacherEmail sql.NullString `json:"teacher_email"`
+	GroupIds     []int64        `json:"group_ids"`
+}
+
+type ListScheduleConflictsRow struct {
+	ID               int64          `json:"id"`
+	TimeSlot         sql.NullString `json:"time_slot"`
+	RoomID           sql.NullInt64  `json:"room_id"`
+	TeacherEmail     sql.NullString `json:"teacher_email"`
+	GroupID          sql.NullInt64  `json:"group_id"`
+	SubGroupID       sql.NullInt64  `json:"sub_group_id"`
+	CombinedGroupIds []int64        `json:"combined_group_ids"`
+}
+
+func (q *Queries) ListScheduleConflicts(ctx context.Context, arg ListScheduleConflictsParams) ([]ListScheduleConflictsRow, error) {
+	rows, err := q.db.QueryContext(ctx, listScheduleConflicts,
+		arg.Year,
+		arg.Term,
+		arg.DayOfWeek,
+		arg.EndMinute,
+		arg.StartMinute,
+		arg.ExcludeID,
+		arg.RoomID,
+		arg.TeacherEmail,
+		pq.Array(arg.GroupIds),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListScheduleConflictsRow
+	for rows.Next() {
+		var i ListScheduleConflictsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.TimeSlot,
+			&i.RoomID,
+			&i.TeacherEmail,
+			&i.GroupID,
+			&i.SubGroupID,
+			pq.Array(&i.CombinedGroupIds),
 		); err != nil {
 			return nil, err
 		}
@@ -388,8 +566,8 @@ func (q *Queries) GetSchedulesByTeacher(ctx context.Context, arg GetSchedulesByT
 }
 
 const listSchedules = `-- name: ListSchedules :many
-SELECT id, group_id, room_id, subject_id, teacher_email, time_slot, year FROM schedules
-ORDER BY time_slot
+SELECT id, group_id, room_id, subject_id, teacher_email, time_slot, year, day_of_week, start_minute, end_minute, session_type, sub_group_id, combined_group_ids, term FROM schedules
+ORDER BY day_of_week, start_minute
 LIMIT $1
 OFFSET $2
 `
@@ -416,6 +594,62 @@ func (q *Queries) ListSchedules(ctx context.Context, arg ListSchedulesParams) ([
 			&i.TeacherEmail,
 			&i.TimeSlot,
 			&i.Year,
+			&i.DayOfWeek,
+			&i.StartMinute,
+			&i.EndMinute,
+			&i.SessionType,
+			&i.SubGroupID,
+			pq.Array(&i.CombinedGroupIds),
+			&i.Term,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listSchedulesByTerm = `-- name: ListSchedulesByTerm :many
+SELECT id, group_id, room_id, subject_id, teacher_email, time_slot, year, day_of_week, start_minute, end_minute, session_type, sub_group_id, combined_group_ids, term FROM schedules
+WHERE year = $1 AND term = $2
+ORDER BY day_of_week, start_minute
+`
+
+type ListSchedulesByTermParams struct {
+	Year int32 `json:"year"`
+	Term int16 `json:"term"`
+}
+
+func (q *Queries) ListSchedulesByTerm(ctx context.Context, arg ListSchedulesByTermParams) ([]Schedule, error) {
+	rows, err := q.db.QueryContext(ctx, listSchedulesByTerm, arg.Year, arg.Term)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Schedule
+	for rows.Next() {
+		var i Schedule
+		if err := rows.Scan(
+			&i.ID,
+			&i.GroupID,
+			&i.RoomID,
+			&i.SubjectID,
+			&i.TeacherEmail,
+			&i.TimeSlot,
+			&i.Year,
+			&i.DayOfWeek,
+			&i.StartMinute,
+			&i.EndMinute,
+			&i.SessionType,
+			&i.SubGroupID,
+			pq.Array(&i.CombinedGroupIds),
+			&i.Term,
 		); err != nil {
 			return nil, err
 		}
@@ -437,18 +671,34 @@ SET
   room_id = $3,
   subject_id = $4,
   teacher_email = $5,
-  time_slot = $6
+  time_slot = $6,
+  day_of_week = $7,
+  start_minute = $8,
+  end_minute = $9,
+  year = $10,
+  session_type = $11,
+  sub_group_id = $12,
+  combined_group_ids = $13,
+  term = $14
 WHERE id = $1
-RETURNING id, group_id, room_id, subject_id, teacher_email, time_slot, year
+RETURNING id, group_id, room_id, subject_id, teacher_email, time_slot, year, day_of_week, start_minute, end_minute, session_type, sub_group_id, combined_group_ids, term
 `
 
 type UpdateScheduleParams struct {
-	ID           int64          `json:"id"`
-	GroupID      sql.NullInt64  `json:"group_id"`
-	RoomID       sql.NullInt64  `json:"room_id"`
-	SubjectID    sql.NullInt64  `json:"subject_id"`
-	TeacherEmail sql.NullString `json:"teacher_email"`
-	TimeSlot     sql.NullString `json:"time_slot"`
+	ID               int64          `json:"id"`
+	GroupID          sql.NullInt64  `json:"group_id"`
+	RoomID           sql.NullInt64  `json:"room_id"`
+	SubjectID        sql.NullInt64  `json:"subject_id"`
+	TeacherEmail     sql.NullString `json:"teacher_email"`
+	TimeSlot         sql.NullString `json:"time_slot"`
+	DayOfWeek        int16          `json:"day_of_week"`
+	StartMinute      int32          `json:"start_minute"`
+	EndMinute        int32          `json:"end_minute"`
+	Year             int32          `json:"year"`
+	SessionType      SessionType    `json:"session_type"`
+	SubGroupID       sql.NullInt64  `json:"sub_group_id"`
+	CombinedGroupIds []int64        `json:"combined_group_ids"`
+	Term             int16          `json:"term"`
 }
 
 func (q *Queries) UpdateSchedule(ctx context.Context, arg UpdateScheduleParams) (Schedule, error) {
@@ -459,6 +709,14 @@ func (q *Queries) UpdateSchedule(ctx context.Context, arg UpdateScheduleParams)
 		arg.SubjectID,
 		arg.TeacherEmail,
 		arg.TimeSlot,
+		arg.DayOfWeek,
+		arg.StartMinute,
+		arg.EndMinute,
+		arg.Year,
+		arg.SessionType,
+		arg.SubGroupID,
+		pq.Array(arg.CombinedGroupIds),
+		arg.Term,
 	)
 	var i Schedule
 	err := row.Scan(
@@ -469,6 +727,13 @@ func (q *Queries) UpdateSchedule(ctx context.Context, arg UpdateScheduleParams)
 		&i.TeacherEmail,
 		&i.TimeSlot,
 		&i.Year,
+		&i.DayOfWeek,
+		&i.StartMinute,
+		&i.EndMinute,
+		&i.SessionType,
+		&i.SubGroupID,
+		pq.Array(&i.CombinedGroupIds),
+		&i.Term,
 	)
 	return i, err
 }
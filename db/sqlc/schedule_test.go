@@ -0,0 +1,39 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/lib/pq"
+)
+
+func TestCombinedClassBlocksJoiningSection(t *testing.T) {
+	q := testTx(t)
+	ctx := context.Background()
+	createTestTerms(t, q)
+
+	class := func(group int64, combined []int64, start int32) error {
+		_, err := q.CreateSchedule(ctx, CreateScheduleParams{
+			GroupID:          sql.NullInt64{Int64: group, Valid: true},
+			Year:             testYear,
+			Term:             1,
+			DayOfWeek:        0,
+			StartMinute:      start,
+			EndMinute:        start + 100,
+			SessionType:      SessionTypeLecture,
+			CombinedGroupIds: combined,
+		})
+		return err
+	}
+	// section 2 joins section 1's class in a hall
+	if err := class(1, []int64{2}, 975); err != nil {
+		t.Fatal(err)
+	}
+	err := class(2, nil, 1000)
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) || pqErr.Code != "23P01" {
+		t.Fatalf("booking the joining section in the same slot: %v, want an exclusion violation", err)
+	}
+}
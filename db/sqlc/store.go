@@ -3,6 +3,7 @@ package db
 import (
 	"context"
 	"database/sql"
+	"fmt"
 )
 
 // Store provides all functions to execute db queries
@@ -27,3 +28,23 @@ func (store *Store) GetUser(ctx context.Context, email string) (User, error) {
 func (store *Store) ListUsers(ctx context.Context, arg GetusersParams) ([]User, error) {
 	return store.Queries.Getusers(ctx, arg)
 }
+
+// ExecTx executes a function within a database transaction. The transaction
+// is rolled back if fn returns an error and committed otherwise.
+func (store *Store) ExecTx(ctx context.Context, fn func(*Queries) error) error {
+	tx, err := store.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	q := New(tx)
+	err = fn(q)
+	if err != nil {
+		if rbErr := tx.Rollback(); rbErr != nil {
+			return fmt.Errorf("tx err: %v, rb err: %v", err, rbErr)
+		}
+		return err
+	}
+
+	return tx.Commit()
+}
@@ -27,10 +27,11 @@ INSERT INTO student_section (
   program,
   year_enrolled,
   group_name,
-  department
+  department,
+  size
 ) VALUES (
-  $1, $2, $3, $4, $5
-) RETURNING id, name, program, year_enrolled, group_name, department
+  $1, $2, $3, $4, $5, $6
+) RETURNING id, name, program, year_enrolled, group_name, department, size
 `
 
 type CreateStudentSectionParams struct {
@@ -39,6 +40,7 @@ type CreateStudentSectionParams struct {
 	YearEnrolled sql.NullInt32  `json:"year_enrolled"`
 	GroupName    sql.NullString `json:"group_name"`
 	Department   sql.NullString `json:"department"`
+	Size         sql.NullInt32  `json:"size"`
 }
 
 func (q *Queries) CreateStudentSection(ctx context.Context, arg CreateStudentSectionParams) (StudentSection, error) {
@@ -48,6 +50,7 @@ func (q *Queries) CreateStudentSection(ctx context.Context, arg CreateStudentSec
 		arg.YearEnrolled,
 		arg.GroupName,
 		arg.Department,
+		arg.Size,
 	)
 	var i StudentSection
 	err := row.Scan(
@@ -57,6 +60,7 @@ func (q *Queries) CreateStudentSection(ctx context.Context, arg CreateStudentSec
 		&i.YearEnrolled,
 		&i.GroupName,
 		&i.Department,
+		&i.Size,
 	)
 	return i, err
 }
@@ -71,8 +75,57 @@ func (q *Queries) DeleteStudentSection(ctx context.Context, id int32) error {
 	return err
 }
 
+const findStudentSections = `-- name: FindStudentSections :many
+SELECT id, name, program, year_enrolled, group_name, department, size FROM student_section
+WHERE upper(program) = upper($1::text)
+  AND ($2::int IS NULL OR year_enrolled = $2::int)
+  AND ($3::text IS NULL OR upper(group_name) = upper($3::text))
+ORDER BY id
+`
+
+type FindStudentSectionsParams struct {
+	Program      string         `json:"program"`
+	YearEnrolled sql.NullInt32  `json:"year_enrolled"`
+	GroupName    sql.NullString `json:"group_name"`
+}
+
+func (q *Queries) FindStudentSections(ctx context.Context, arg FindStudentSectionsParams) ([]StudentSection, error) {
+	rows, err := q.db.QueryContext(ctx, findStudentSections,
+		arg.Program,
+		arg.YearEnrolled,
+		arg.GroupName,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []StudentSection
+	for rows.Next() {
+		var i StudentSection
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Program,
+			&i.YearEnrolled,
+			&i.GroupName,
+			&i.Department,
+			&i.Size,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getStudentSection = `-- name: GetStudentSection :one
-SELECT id, name, program, year_enrolled, group_name, department FROM student_section
+SELECT id, name, program, year_enrolled, group_name, department, size FROM student_section
 WHERE id = $1 LIMIT 1
 `
 
@@ -86,12 +139,27 @@ func (q *Queries) GetStudentSection(ctx context.Context, id int32) (StudentSecti
 		&i.YearEnrolled,
 		&i.GroupName,
 		&i.Department,
+		&i.Size,
 	)
 	return i, err
 }
 
+const getStudentSectionSize = `-- name: GetStudentSectionSize :one
+SELECT COALESCE(ss.size, (SELECT count(*) FROM student st WHERE st.group_id = ss.id))::int4 AS size
+FROM student_section ss
+WHERE ss.id = $1
+`
+
+// Headcount of a section: the size entered by hand, else its students.
+func (q *Queries) GetStudentSectionSize(ctx context.Context, id int32) (int32, error) {
+	row := q.db.QueryRowContext(ctx, getStudentSectionSize, id)
+	var size int32
+	err := row.Scan(&size)
+	return size, err
+}
+
 const getStudentSectionsByDepartment = `-- name: GetStudentSectionsByDepartment :many
-SELECT id, name, program, year_enrolled, group_name, department FROM student_section
+SELECT id, name, program, year_enrolled, group_name, department, size FROM student_section
 WHERE department = $1
 ORDER BY id
 `
@@ -112,6 +180,7 @@ func (q *Queries) GetStudentSectionsByDepartment(ctx context.Context, department
 			&i.YearEnrolled,
 			&i.GroupName,
 			&i.Department,
+			&i.Size,
 		); err != nil {
 			return nil, err
 		}
@@ -127,7 +196,7 @@ func (q *Queries) GetStudentSectionsByDepartment(ctx context.Context, department
 }
 
 const getStudentSectionsByProgram = `-- name: GetStudentSectionsByProgram :many
-SELECT id, name, program, year_enrolled, group_name, department FROM student_section
+SELECT id, name, program, year_enrolled, group_name, department, size FROM student_section
 WHERE program = $1
 ORDER BY id
 `
@@ -148,6 +217,7 @@ func (q *Queries) GetStudentSectionsByProgram(ctx context.Context, program sql.N
 			&i.YearEnrolled,
 			&i.GroupName,
 			&i.Department,
+			&i.Size,
 		); err != nil {
 			return nil, err
 		}
@@ -163,7 +233,7 @@ func (q *Queries) GetStudentSectionsByProgram(ctx context.Context, program sql.N
 }
 
 const getStudentSectionsByYear = `-- name: GetStudentSectionsByYear :many
-SELECT id, name, program, year_enrolled, group_name, department FROM student_section
+SELECT id, name, program, year_enrolled, group_name, department, size FROM student_section
 WHERE year_enrolled = $1
 ORDER BY id
 `
@@ -184,6 +254,7 @@ func (q *Queries) GetStudentSectionsByYear(ctx context.Context, yearEnrolled sql
 			&i.YearEnrolled,
 			&i.GroupName,
 			&i.Department,
+			&i.Size,
 		); err != nil {
 			return nil, err
 		}
@@ -233,8 +304,83 @@ func (q *Queries) GetStudentsInSection(ctx context.Context, id int32) ([]Student
 	return items, nil
 }
 
+const listAllStudentSections = `-- name: ListAllStudentSections :many
+SELECT id, name, program, year_enrolled, group_name, department, size FROM student_section
+ORDER BY id
+`
+
+func (q *Queries) ListAllStudentSections(ctx context.Context) ([]StudentSection, error) {
+	rows, err := q.db.QueryContext(ctx, listAllStudentSections)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []StudentSection
+	for rows.Next() {
+		var i StudentSection
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Program,
+			&i.YearEnrolled,
+			&i.GroupName,
+			&i.Department,
+			&i.Size,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listStudentSectionSizes = `-- name: ListStudentSectionSizes :many
+SELECT ss.id, COALESCE(ss.size, count(st.id))::int4 AS size
+FROM student_section ss
+LEFT JOIN student st ON st.group_id = ss.id
+GROUP BY ss.id
+ORDER BY ss.id
+`
+
+type ListStudentSectionSizesRow struct {
+	ID   int32 `json:"id"`
+	Size int32 `json:"size"`
+}
+
+func (q *Queries) ListStudentSectionSizes(ctx context.Context) ([]ListStudentSectionSizesRow, error) {
+	rows, err := q.db.QueryContext(ctx, listStudentSectionSizes)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListStudentSectionSizesRow
+	for rows.Next() {
+		var i ListStudentSectionSizesRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Size,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const listStudentSections = `-- name: ListStudentSections :many
-SELECT id, name, program, year_enrolled, group_name, department FROM student_section
+SELECT id, name, program, year_enrolled, group_name, department, size FROM student_section
 ORDER BY id
 LIMIT $1
 OFFSET $2
@@ -261,6 +407,44 @@ func (q *Queries) ListStudentSections(ctx context.Context, arg ListStudentSectio
 			&i.YearEnrolled,
 			&i.GroupName,
 			&i.Department,
+			&i.Size,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listStudentSectionsByName = `-- name: ListStudentSectionsByName :many
+SELECT id, name, program, year_enrolled, group_name, department, size FROM student_section
+WHERE upper(name) = upper($1::text)
+ORDER BY id
+`
+
+func (q *Queries) ListStudentSectionsByName(ctx context.Context, name string) ([]StudentSection, error) {
+	rows, err := q.db.QueryContext(ctx, listStudentSectionsByName, name)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []StudentSection
+	for rows.Next() {
+		var i StudentSection
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Program,
+			&i.YearEnrolled,
+			&i.GroupName,
+			&i.Department,
+			&i.Size,
 		); err != nil {
 			return nil, err
 		}
@@ -281,9 +465,10 @@ SET name = $2,
     program = $3,
     year_enrolled = $4,
     group_name = $5,
-    department = $6
+    department = $6,
+    size = $7
 WHERE id = $1
-RETURNING id, name, program, year_enrolled, group_name, department
+RETURNING id, name, program, year_enrolled, group_name, department, size
 `
 
 type UpdateStudentSectionParams struct {
@@ -293,6 +478,7 @@ type UpdateStudentSectionParams struct {
 	YearEnrolled sql.NullInt32  `json:"year_enrolled"`
 	GroupName    sql.NullString `json:"group_name"`
 	Department   sql.NullString `json:"department"`
+	Size         sql.NullInt32  `json:"size"`
 }
 
 func (q *Queries) UpdateStudentSection(ctx context.Context, arg UpdateStudentSectionParams) (StudentSection, error) {
@@ -303,6 +489,7 @@ func (q *Queries) UpdateStudentSection(ctx context.Context, arg UpdateStudentSec
 		arg.YearEnrolled,
 		arg.GroupName,
 		arg.Department,
+		arg.Size,
 	)
 	var i StudentSection
 	err := row.Scan(
@@ -312,6 +499,7 @@ func (q *Queries) UpdateStudentSection(ctx context.Context, arg UpdateStudentSec
 		&i.YearEnrolled,
 		&i.GroupName,
 		&i.Department,
+		&i.Size,
 	)
 	return i, err
 }
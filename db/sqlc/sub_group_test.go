@@ -0,0 +1,90 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/lib/pq"
+)
+
+func TestSubGroupMemberEmails(t *testing.T) {
+	q := testTx(t)
+	ctx := context.Background()
+
+	var sections []StudentSection
+	for _, name := range []string{"Test A", "Test B"} {
+		section, err := q.CreateStudentSection(ctx, CreateStudentSectionParams{Name: sql.NullString{String: name, Valid: true}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		sections = append(sections, section)
+	}
+	// there is no query to add students; negative ids stay clear of real ones
+	for i, s := range []struct {
+		email   string
+		section StudentSection
+	}{
+		{"ram@example.com", sections[0]},
+		{"sita@example.com", sections[0]},
+		{"hari@example.com", sections[1]},
+	} {
+		if _, err := q.db.ExecContext(ctx, `INSERT INTO student (id, name, email, group_id) VALUES ($1, $2, $2, $3)`,
+			-1-i, s.email, s.section.ID); err != nil {
+			t.Fatal(err)
+		}
+	}
+	subGroup, err := q.CreateSubGroup(ctx, CreateSubGroupParams{GroupID: sections[0].ID, Name: "A"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// hari is in the other section and is left out
+	added, err := q.AddSubGroupStudents(ctx, AddSubGroupStudentsParams{SubGroupID: subGroup.ID, StudentIds: []int64{-1, -3}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if added != 1 {
+		t.Errorf("added %d students, want only ram", added)
+	}
+	emails, err := q.ListSubGroupMemberEmails(ctx, []int64{subGroup.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(emails, ",") != "ram@example.com" {
+		t.Errorf("sub-group emails %v, want only ram", emails)
+	}
+}
+
+func TestStudentInOneSubGroupPerSection(t *testing.T) {
+	q := testTx(t)
+	ctx := context.Background()
+
+	section, err := q.CreateStudentSection(ctx, CreateStudentSectionParams{Name: sql.NullString{String: "Test A", Valid: true}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := q.db.ExecContext(ctx, `INSERT INTO student (id, name, email, group_id) VALUES (-1, 'Ram', 'ram@example.com', $1)`, section.ID); err != nil {
+		t.Fatal(err)
+	}
+	var subGroups []SubGroup
+	for _, name := range []string{"A", "B"} {
+		subGroup, err := q.CreateSubGroup(ctx, CreateSubGroupParams{GroupID: section.ID, Name: name})
+		if err != nil {
+			t.Fatal(err)
+		}
+		subGroups = append(subGroups, subGroup)
+	}
+
+	// A and B may have classes in parallel, so ram cannot attend both
+	if _, err := q.AddSubGroupStudents(ctx, AddSubGroupStudentsParams{SubGroupID: subGroups[0].ID, StudentIds: []int64{-1}}); err != nil {
+		t.Fatal(err)
+	}
+	_, err = q.AddSubGroupStudents(ctx, AddSubGroupStudentsParams{SubGroupID: subGroups[1].ID, StudentIds: []int64{-1}})
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) || pqErr.Code != "23505" {
+		t.Fatalf("adding ram to a second sub-group: %v, want a unique violation", err)
+	}
+}
@@ -8,6 +8,8 @@ package db
 import (
 	"context"
 	"database/sql"
+
+	"github.com/lib/pq"
 )
 
 const assignTeacherToSubject = `-- name: AssignTeacherToSubject :exec
@@ -79,6 +81,24 @@ func (q *Queries) DeleteSubject(ctx context.Context, id int64) error {
 	return err
 }
 
+const deleteSubjectRoomRequirement = `-- name: DeleteSubjectRoomRequirement :execrows
+DELETE FROM subject_room_requirements
+WHERE id = $1 AND subject_id = $2
+`
+
+type DeleteSubjectRoomRequirementParams struct {
+	ID        int64 `json:"id"`
+	SubjectID int64 `json:"subject_id"`
+}
+
+func (q *Queries) DeleteSubjectRoomRequirement(ctx context.Context, arg DeleteSubjectRoomRequirementParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteSubjectRoomRequirement, arg.ID, arg.SubjectID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
 const getAssignedTeachers = `-- name: GetAssignedTeachers :many
 SELECT st.subject_id, st.teacher_email, t.name AS teacher_name , t.designation , t.department 
 FROM subject_teachers st
@@ -158,6 +178,29 @@ func (q *Queries) GetSubjectByCode(ctx context.Context, subjectCode sql.NullStri
 	return i, err
 }
 
+const getSubjectRoomRequirement = `-- name: GetSubjectRoomRequirement :one
+SELECT id, subject_id, session_type, room_type, features FROM subject_room_requirements
+WHERE subject_id = $1 AND session_type = $2
+`
+
+type GetSubjectRoomRequirementParams struct {
+	SubjectID   int64       `json:"subject_id"`
+	SessionType SessionType `json:"session_type"`
+}
+
+func (q *Queries) GetSubjectRoomRequirement(ctx context.Context, arg GetSubjectRoomRequirementParams) (SubjectRoomRequirement, error) {
+	row := q.db.QueryRowContext(ctx, getSubjectRoomRequirement, arg.SubjectID, arg.SessionType)
+	var i SubjectRoomRequirement
+	err := row.Scan(
+		&i.ID,
+		&i.SubjectID,
+		&i.SessionType,
+		&i.RoomType,
+		pq.Array(&i.Features),
+	)
+	return i, err
+}
+
 const getSubjectTeachers = `-- name: GetSubjectTeachers :many
 SELECT t.name, t.email, t.department, t.designation FROM teacher t
 JOIN subject_teachers st ON t.email = st.teacher_email
@@ -226,6 +269,148 @@ func (q *Queries) GetSubjectsByDepartment(ctx context.Context, department sql.Nu
 	return items, nil
 }
 
+const listAllSubjectRoomRequirements = `-- name: ListAllSubjectRoomRequirements :many
+SELECT id, subject_id, session_type, room_type, features FROM subject_room_requirements
+ORDER BY subject_id, session_type
+`
+
+func (q *Queries) ListAllSubjectRoomRequirements(ctx context.Context) ([]SubjectRoomRequirement, error) {
+	rows, err := q.db.QueryContext(ctx, listAllSubjectRoomRequirements)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []SubjectRoomRequirement
+	for rows.Next() {
+		var i SubjectRoomRequirement
+		if err := rows.Scan(
+			&i.ID,
+			&i.SubjectID,
+			&i.SessionType,
+			&i.RoomType,
+			pq.Array(&i.Features),
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listAllSubjects = `-- name: ListAllSubjects :many
+SELECT id, subject_code, name, department FROM subject
+ORDER BY id
+`
+
+func (q *Queries) ListAllSubjects(ctx context.Context) ([]Subject, error) {
+	rows, err := q.db.QueryContext(ctx, listAllSubjects)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Subject
+	for rows.Next() {
+		var i Subject
+		if err := rows.Scan(
+			&i.ID,
+			&i.SubjectCode,
+			&i.Name,
+			&i.Department,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listSubjectRoomRequirements = `-- name: ListSubjectRoomRequirements :many
+SELECT id, subject_id, session_type, room_type, features FROM subject_room_requirements
+WHERE subject_id = $1
+ORDER BY session_type
+`
+
+func (q *Queries) ListSubjectRoomRequirements(ctx context.Context, subjectID int64) ([]SubjectRoomRequirement, error) {
+	rows, err := q.db.QueryContext(ctx, listSubjectRoomRequirements, subjectID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []SubjectRoomRequirement
+	for rows.Next() {
+		var i SubjectRoomRequirement
+		if err := rows.Scan(
+			&i.ID,
+			&i.SubjectID,
+			&i.SessionType,
+			&i.RoomType,
+			pq.Array(&i.Features),
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listSubjectTeacherAssignments = `-- name: ListSubjectTeacherAssignments :many
+SELECT st.subject_id, st.teacher_email, sub.department
+FROM subject_teachers st
+JOIN subject sub ON st.subject_id = sub.id
+ORDER BY st.subject_id, st.teacher_email
+`
+
+type ListSubjectTeacherAssignmentsRow struct {
+	SubjectID    int64          `json:"subject_id"`
+	TeacherEmail string         `json:"teacher_email"`
+	Department   sql.NullString `json:"department"`
+}
+
+func (q *Queries) ListSubjectTeacherAssignments(ctx context.Context) ([]ListSubjectTeacherAssignmentsRow, error) {
+	rows, err := q.db.QueryContext(ctx, listSubjectTeacherAssignments)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListSubjectTeacherAssignmentsRow
+	for rows.Next() {
+		var i ListSubjectTeacherAssignmentsRow
+		if err := rows.Scan(
+			&i.SubjectID,
+			&i.TeacherEmail,
+			&i.Department,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const listSubjects = `-- name: ListSubjects :many
 SELECT id, subject_code, name, department FROM subject
 ORDER BY id
@@ -313,3 +498,42 @@ func (q *Queries) UpdateSubject(ctx context.Context, arg UpdateSubjectParams) (S
 	)
 	return i, err
 }
+
+const upsertSubjectRoomRequirement = `-- name: UpsertSubjectRoomRequirement :one
+INSERT INTO subject_room_requirements (
+  subject_id,
+  session_type,
+  room_type,
+  features
+) VALUES (
+  $1, $2, $3, $4
+)
+ON CONFLICT (subject_id, session_type)
+DO UPDATE SET room_type = EXCLUDED.room_type, features = EXCLUDED.features
+RETURNING id, subject_id, session_type, room_type, features
+`
+
+type UpsertSubjectRoomRequirementParams struct {
+	SubjectID   int64        `json:"subject_id"`
+	SessionType SessionType  `json:"session_type"`
+	RoomType    NullRoomType `json:"room_type"`
+	Features    []string     `json:"features"`
+}
+
+func (q *Queries) UpsertSubjectRoomRequirement(ctx context.Context, arg UpsertSubjectRoomRequirementParams) (SubjectRoomRequirement, error) {
+	row := q.db.QueryRowContext(ctx, upsertSubjectRoomRequirement,
+		arg.SubjectID,
+		arg.SessionType,
+		arg.RoomType,
+		pq.Array(arg.Features),
+	)
+	var i SubjectRoomRequirement
+	err := row.Scan(
+		&i.ID,
+		&i.SubjectID,
+		&i.SessionType,
+		&i.RoomType,
+		pq.Array(&i.Features),
+	)
+	return i, err
+}
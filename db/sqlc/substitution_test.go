@@ -0,0 +1,53 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+)
+
+func TestSubstituteCandidatesSkipUnavailable(t *testing.T) {
+	q := testTx(t)
+	ctx := context.Background()
+
+	subject, err := q.CreateSubject(ctx, CreateSubjectParams{SubjectCode: sql.NullString{String: "TEST 101", Valid: true}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, email := range []string{"absent-test@example.com", "free-test@example.com", "busy-test@example.com"} {
+		if _, err := q.CreateTeacher(ctx, CreateTeacherParams{Email: email}); err != nil {
+			t.Fatal(err)
+		}
+		if err := q.AssignTeacherToSubject(ctx, AssignTeacherToSubjectParams{SubjectID: subject.ID, TeacherEmail: email}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// busy cannot come in on Sunday afternoons
+	if _, err := q.CreateTeacherAvailability(ctx, CreateTeacherAvailabilityParams{
+		TeacherEmail: "busy-test@example.com",
+		Kind:         AvailabilityKindUnavailable,
+		DayOfWeek:    0,
+		StartMinute:  13 * 60,
+		EndMinute:    17 * 60,
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	candidates, err := q.ListSubstituteCandidates(ctx, ListSubstituteCandidatesParams{
+		SubjectID:   sql.NullInt64{Int64: subject.ID, Valid: true},
+		AbsentEmail: "absent-test@example.com",
+		Date:        time.Date(testYear-57, time.May, 5, 0, 0, 0, 0, time.UTC),
+		Year:        testYear,
+		Term:        1,
+		DayOfWeek:   0,
+		StartMinute: 16*60 + 15,
+		EndMinute:   17*60 + 55,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(candidates) != 1 || candidates[0].Email != "free-test@example.com" {
+		t.Errorf("candidates %v, want only the teacher who is free", candidates)
+	}
+}
@@ -103,6 +103,40 @@ func (q *Queries) GetTeachers(ctx context.Context, arg GetTeachersParams) ([]Tea
 	return items, nil
 }
 
+const searchTeachersByName = `-- name: SearchTeachersByName :many
+SELECT name, email, department, designation FROM teacher
+WHERE name ILIKE '%' || $1::text || '%'
+ORDER BY name
+`
+
+func (q *Queries) SearchTeachersByName(ctx context.Context, name string) ([]Teacher, error) {
+	rows, err := q.db.QueryContext(ctx, searchTeachersByName, name)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Teacher
+	for rows.Next() {
+		var i Teacher
+		if err := rows.Scan(
+			&i.Name,
+			&i.Email,
+			&i.Department,
+			&i.Designation,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const updateTeacherByEmail = `-- name: UpdateTeacherByEmail :exec
 UPDATE teacher
 SET name = $2, department = $3, designation = $4
@@ -0,0 +1,199 @@
+// Package ical writes weekly routines as iCalendar (RFC 5545) feeds that
+// calendar apps can subscribe to.
+//
+// Every routine entry becomes one recurring VEVENT: a weekly RRULE anchored
+// at the first occurrence on or after the start of the academic year and
+// running until its end. Dated one-off entries, such as room bookings, are
+// written as single events. Times are local to Asia/Kathmandu.
+package ical
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/nirajan1111/routiney/timeslot"
+)
+
+// Kathmandu is Nepal Standard Time. Nepal has not observed daylight saving
+// since 1986, so a fixed zone matches the tz database and does not depend on
+// tzdata being installed.
+var Kathmandu = time.FixedZone("Asia/Kathmandu", 5*60*60+45*60)
+
+const (
+	tzid      = "Asia/Kathmandu"
+	prodID    = "-//routiney//Routine Feed//EN"
+	localTime = "20060102T150405"
+	utcTime   = "20060102T150405Z"
+)
+
+var byDay = [...]string{"SU", "MO", "TU", "WE", "TH", "FR", "SA"}
+
+// Event is one weekly routine entry.
+type Event struct {
+	// UID must be stable across refreshes so apps update rather than
+	// duplicate the event.
+	UID         string
+	Summary     string
+	Location    string
+	Description string
+	Slot        timeslot.TimeSlot
+	// Date, when set, makes the event a single occurrence on that date
+	// instead of a weekly one; Slot.Day is then ignored.
+	Date time.Time
+}
+
+// DateRange is an inclusive range of dates.
+type DateRange struct {
+	Start time.Time
+	End   time.Time
+}
+
+// contains reports whether the calendar date of t falls in the range.
+func (r DateRange) contains(t time.Time) bool {
+	d := dateOf(t)
+	return !d.Before(dateOf(r.Start)) && !d.After(dateOf(r.End))
+}
+
+func dateOf(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// Calendar is a feed bounded by an academic year. Start and End are dates;
+// only their year, month and day are used.
+type Calendar struct {
+	Name   string
+	Start  time.Time
+	End    time.Time
+	Events []Event
+	// Skip lists dates without classes, such as holidays. Occurrences on
+	// them are excluded with EXDATE.
+	Skip []DateRange
+	// Stamp is written as DTSTAMP; zero means now.
+	Stamp time.Time
+}
+
+// Write encodes cal to w.
+func Write(w io.Writer, cal Calendar) error {
+	stamp := cal.Stamp
+	if stamp.IsZero() {
+		stamp = time.Now()
+	}
+	start := time.Date(cal.Start.Year(), cal.Start.Month(), cal.Start.Day(), 0, 0, 0, 0, Kathmandu)
+	// UNTIL is inclusive, so stop at the end of the last day
+	until := time.Date(cal.End.Year(), cal.End.Month(), cal.End.Day(), 23, 59, 59, 0, Kathmandu)
+
+	bw := bufio.NewWriter(w)
+	line := func(name, value string) {
+		writeLine(bw, name+":"+value)
+	}
+
+	line("BEGIN", "VCALENDAR")
+	line("VERSION", "2.0")
+	line("PRODID", prodID)
+	line("CALSCALE", "GREGORIAN")
+	line("METHOD", "PUBLISH")
+	if cal.Name != "" {
+		line("X-WR-CALNAME", escape(cal.Name))
+	}
+	line("X-WR-TIMEZONE", tzid)
+
+	line("BEGIN", "VTIMEZONE")
+	line("TZID", tzid)
+	line("BEGIN", "STANDARD")
+	line("DTSTART", "19860101T000000")
+	line("TZOFFSETFROM", "+0545")
+	line("TZOFFSETTO", "+0545")
+	line("TZNAME", "NPT")
+	line("END", "STANDARD")
+	line("END", "VTIMEZONE")
+
+	for _, ev := range cal.Events {
+		weekly := ev.Date.IsZero()
+		first := firstOccurrence(start, ev.Slot.Day)
+		if !weekly {
+			first = time.Date(ev.Date.Year(), ev.Date.Month(), ev.Date.Day(), 0, 0, 0, 0, Kathmandu)
+		}
+		if first.Before(start) || first.After(until) {
+			continue
+		}
+		line("BEGIN", "VEVENT")
+		line("UID", escape(ev.UID))
+		line("DTSTAMP", stamp.UTC().Format(utcTime))
+		line("DTSTART;TZID="+tzid, at(first, ev.Slot.Start).Format(localTime))
+		line("DTEND;TZID="+tzid, at(first, ev.Slot.End).Format(localTime))
+		if weekly {
+			line("RRULE", fmt.Sprintf("FREQ=WEEKLY;BYDAY=%s;UNTIL=%s", byDay[ev.Slot.Day], until.UTC().Format(utcTime)))
+			if exdates := skipped(first, until, ev.Slot.Start, cal.Skip); len(exdates) > 0 {
+				line("EXDATE;TZID="+tzid, strings.Join(exdates, ","))
+			}
+		}
+		line("SUMMARY", escape(ev.Summary))
+		if ev.Location != "" {
+			line("LOCATION", escape(ev.Location))
+		}
+		if ev.Description != "" {
+			line("DESCRIPTION", escape(ev.Description))
+		}
+		line("END", "VEVENT")
+	}
+
+	line("END", "VCALENDAR")
+	return bw.Flush()
+}
+
+// skipped lists the weekly occurrences from first to until that fall on a
+// skipped date, formatted for EXDATE.
+func skipped(first, until time.Time, minute int32, skip []DateRange) []string {
+	if len(skip) == 0 {
+		return nil
+	}
+	var exdates []string
+	for day := first; !day.After(until); day = day.AddDate(0, 0, 7) {
+		for _, r := range skip {
+			if r.contains(day) {
+				exdates = append(exdates, at(day, minute).Format(localTime))
+				break
+			}
+		}
+	}
+	return exdates
+}
+
+// firstOccurrence is the first date on or after start falling on day.
+func firstOccurrence(start time.Time, day timeslot.Day) time.Time {
+	offset := (int(day.Weekday()) - int(start.Weekday()) + 7) % 7
+	return start.AddDate(0, 0, offset)
+}
+
+// at is date at the given minute of the day.
+func at(date time.Time, minute int32) time.Time {
+	return time.Date(date.Year(), date.Month(), date.Day(), int(minute/60), int(minute%60), 0, 0, date.Location())
+}
+
+var escaper = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`)
+
+func escape(s string) string {
+	return escaper.Replace(s)
+}
+
+// writeLine writes a content line folded at 75 octets, without splitting a
+// UTF-8 sequence, and terminated by CRLF.
+func writeLine(w *bufio.Writer, s string) {
+	// continuation lines start with a space, which counts towards the limit
+	limit := 75
+	for len(s) > limit {
+		cut := limit
+		for cut > 0 && s[cut]&0xC0 == 0x80 {
+			cut--
+		}
+		w.WriteString(s[:cut])
+		w.WriteString("\r\n ")
+		s = s[cut:]
+		limit = 74
+	}
+	w.WriteString(s)
+	w.WriteString("\r\n")
+}
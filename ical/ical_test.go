@@ -0,0 +1,128 @@
+package ical
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nirajan1111/routiney/timeslot"
+)
+
+func TestWrite(t *testing.T) {
+	slot, _ := timeslot.Parse("TUE-16:15-17:55")
+	cal := Calendar{
+		Name:  "Routine, BCT 2078 A",
+		Start: time.Date(2024, time.April, 13, 0, 0, 0, 0, time.UTC), // a Saturday
+		End:   time.Date(2025, time.April, 13, 0, 0, 0, 0, time.UTC),
+		Stamp: time.Date(2024, time.May, 1, 0, 0, 0, 0, time.UTC),
+		Events: []Event{{
+			UID:     "schedule-1@routiney",
+			Summary: "CT401 Computer Graphics; Lab",
+			Slot:    slot,
+		}},
+	}
+
+	var buf bytes.Buffer
+	if err := Write(&buf, cal); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+
+	for _, want := range []string{
+		"X-WR-CALNAME:Routine\\, BCT 2078 A\r\n",
+		"DTSTART;TZID=Asia/Kathmandu:20240416T161500\r\n",
+		"DTEND;TZID=Asia/Kathmandu:20240416T175500\r\n",
+		// 2025-04-13 23:59:59 +0545
+		"RRULE:FREQ=WEEKLY;BYDAY=TU;UNTIL=20250413T181459Z\r\n",
+		"SUMMARY:CT401 Computer Graphics\\; Lab\r\n",
+		"DTSTAMP:20240501T000000Z\r\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("missing %q in\n%s", want, out)
+		}
+	}
+}
+
+func TestWriteLineFolds(t *testing.T) {
+	var buf bytes.Buffer
+	cal := Calendar{Name: strings.Repeat("क", 60)}
+	if err := Write(&buf, cal); err != nil {
+		t.Fatal(err)
+	}
+	for _, line := range strings.Split(buf.String(), "\r\n") {
+		if len(line) > 75 {
+			t.Errorf("line longer than 75 octets: %q", line)
+		}
+	}
+}
+
+func TestWriteSkipsHolidays(t *testing.T) {
+	slot, _ := timeslot.Parse("SUN-16:15-17:55")
+	cal := Calendar{
+		Start: time.Date(2024, time.April, 13, 0, 0, 0, 0, time.UTC),
+		End:   time.Date(2025, time.April, 13, 0, 0, 0, 0, time.UTC),
+		Skip: []DateRange{{
+			Start: time.Date(2024, time.October, 10, 0, 0, 0, 0, time.UTC),
+			End:   time.Date(2024, time.October, 20, 0, 0, 0, 0, time.UTC),
+		}},
+		Events: []Event{{UID: "schedule-1@routiney", Summary: "CT401", Slot: slot}},
+	}
+	var buf bytes.Buffer
+	if err := Write(&buf, cal); err != nil {
+		t.Fatal(err)
+	}
+	// Sundays 13 and 20 October 2024
+	want := "EXDATE;TZID=Asia/Kathmandu:20241013T161500,20241020T161500\r\n"
+	if !strings.Contains(buf.String(), want) {
+		t.Errorf("missing %q in\n%s", want, buf.String())
+	}
+}
+
+func TestWriteDatedEvent(t *testing.T) {
+	slot, _ := timeslot.Parse("SUN-10:00-12:00")
+	cal := Calendar{
+		Start: time.Date(2024, time.April, 13, 0, 0, 0, 0, time.UTC),
+		End:   time.Date(2025, time.April, 13, 0, 0, 0, 0, time.UTC),
+		Events: []Event{
+			{UID: "booking-1@routiney", Summary: "Thesis defense", Slot: slot, Date: time.Date(2024, time.May, 2, 0, 0, 0, 0, time.UTC)},
+			{UID: "booking-2@routiney", Summary: "Too early", Slot: slot, Date: time.Date(2024, time.April, 1, 0, 0, 0, 0, time.UTC)},
+		},
+	}
+	var buf bytes.Buffer
+	if err := Write(&buf, cal); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	// a Thursday, even though the slot says Sunday
+	if !strings.Contains(out, "DTSTART;TZID=Asia/Kathmandu:20240502T100000\r\n") {
+		t.Errorf("missing dated start in\n%s", out)
+	}
+	if strings.Contains(out, "RRULE") || strings.Contains(out, "booking-2") {
+		t.Errorf("dated events must not repeat or fall outside the year:\n%s", out)
+	}
+}
+
+func TestParse(t *testing.T) {
+	in := "BEGIN:VCALENDAR\r\n" +
+		"BEGIN:VEVENT\r\nUID:dashain-2081\r\nSUMMARY:Dashain\\, main days\r\n" +
+		"DTSTART;VALUE=DATE:20241010\r\nDTEND;VALUE=DATE:20241016\r\nEND:VEVENT\r\n" +
+		"BEGIN:VEVENT\r\nUID:tihar\r\nSUMMARY:Laxmi Pu\r\n ja\r\n" +
+		"DTSTART:20241031T200000Z\r\nEND:VEVENT\r\n" +
+		"END:VCALENDAR\r\n"
+	events, err := Parse(strings.NewReader(in))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 2 {
+		t.Fatalf("got %d events", len(events))
+	}
+	day := func(y int, m time.Month, d int) time.Time { return time.Date(y, m, d, 0, 0, 0, 0, time.UTC) }
+	if e := events[0]; e.Summary != "Dashain, main days" || !e.Start.Equal(day(2024, 10, 10)) || !e.End.Equal(day(2024, 10, 15)) {
+		t.Errorf("event 0 = %+v", e)
+	}
+	// 20:00 UTC is already the next day in Kathmandu
+	if e := events[1]; e.Summary != "Laxmi Puja" || !e.Start.Equal(day(2024, 11, 1)) || !e.End.Equal(e.Start) {
+		t.Errorf("event 1 = %+v", e)
+	}
+}
@@ -0,0 +1,141 @@
+package ical
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// Imported is an event read from an iCalendar file, reduced to what the
+// academic calendar keeps: whole days. End is the last day, inclusive.
+type Imported struct {
+	UID         string
+	Summary     string
+	Description string
+	Start       time.Time
+	End         time.Time
+}
+
+// Parse reads the VEVENTs of an iCalendar stream, such as a published
+// holiday list. Timed events are reduced to the Kathmandu dates they cover.
+// Recurrence rules are not expanded.
+func Parse(r io.Reader) ([]Imported, error) {
+	lines, err := unfold(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var events []Imported
+	var cur *Imported
+	var endExclusive bool
+	for n, l := range lines {
+		name, params, value := splitLine(l)
+		switch {
+		case name == "BEGIN" && value == "VEVENT":
+			cur = &Imported{}
+			endExclusive = false
+		case name == "END" && value == "VEVENT":
+			if cur == nil || cur.Start.IsZero() {
+				return nil, fmt.Errorf("line %d: event without DTSTART", n+1)
+			}
+			if cur.End.IsZero() {
+				cur.End = cur.Start
+			} else if endExclusive && cur.End.After(cur.Start) {
+				cur.End = cur.End.AddDate(0, 0, -1)
+			}
+			events = append(events, *cur)
+			cur = nil
+		case cur == nil:
+			// calendar level property or another component
+		case name == "UID":
+			cur.UID = unescape(value)
+		case name == "SUMMARY":
+			cur.Summary = unescape(value)
+		case name == "DESCRIPTION":
+			cur.Description = unescape(value)
+		case name == "DTSTART", name == "DTEND":
+			date, allDay, err := parseDate(params, value)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %s: %w", n+1, name, err)
+			}
+			if name == "DTSTART" {
+				cur.Start = date
+			} else {
+				cur.End = date
+				// an all-day DTEND is the day after the event
+				endExclusive = allDay
+			}
+		}
+	}
+	return events, nil
+}
+
+// unfold joins continuation lines (those starting with a space or tab).
+func unfold(r io.Reader) ([]string, error) {
+	var lines []string
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 64*1024), 1024*1024)
+	for sc.Scan() {
+		l := strings.TrimRight(sc.Text(), "\r")
+		if (strings.HasPrefix(l, " ") || strings.HasPrefix(l, "\t")) && len(lines) > 0 {
+			lines[len(lines)-1] += l[1:]
+			continue
+		}
+		if l != "" {
+			lines = append(lines, l)
+		}
+	}
+	return lines, sc.Err()
+}
+
+// splitLine splits "DTSTART;VALUE=DATE:20240101" into its name, parameters
+// and value.
+func splitLine(l string) (string, map[string]string, string) {
+	colon := strings.IndexByte(l, ':')
+	if colon < 0 {
+		return strings.ToUpper(l), nil, ""
+	}
+	head, value := l[:colon], l[colon+1:]
+	parts := strings.Split(head, ";")
+	params := make(map[string]string, len(parts)-1)
+	for _, p := range parts[1:] {
+		if k, v, ok := strings.Cut(p, "="); ok {
+			params[strings.ToUpper(k)] = strings.Trim(v, `"`)
+		}
+	}
+	return strings.ToUpper(parts[0]), params, value
+}
+
+func parseDate(params map[string]string, value string) (time.Time, bool, error) {
+	if params["VALUE"] == "DATE" || len(value) == 8 {
+		t, err := time.Parse("20060102", value)
+		return t, true, err
+	}
+
+	loc := Kathmandu
+	if tz, ok := params["TZID"]; ok && tz != tzid {
+		if l, err := time.LoadLocation(tz); err == nil {
+			loc = l
+		}
+	}
+	var t time.Time
+	var err error
+	if strings.HasSuffix(value, "Z") {
+		t, err = time.Parse(utcTime, value)
+	} else {
+		t, err = time.ParseInLocation(localTime, value, loc)
+	}
+	if err != nil {
+		return t, false, err
+	}
+	k := t.In(Kathmandu)
+	return time.Date(k.Year(), k.Month(), k.Day(), 0, 0, 0, 0, time.UTC), false, nil
+}
+
+var unescaper = strings.NewReplacer(`\\`, `\`, `\;`, ";", `\,`, ",", `\n`, "\n", `\N`, "\n")
+
+func unescape(s string) string {
+	return unescaper.Replace(s)
+}
@@ -0,0 +1,537 @@
+// Package importer loads rooms, teachers, subjects, student sections and
+// schedules from CSV or XLSX sheets.
+//
+// An import is all or nothing: every row is written inside one transaction,
+// which is committed only when no row failed. Schedule rows refer to other
+// records by their natural keys (room code, subject code, teacher email and
+// section name) and go through the same conflict validation as the API. In
+// dry-run mode the transaction is always rolled back, so the report shows
+// what a real import would do, including conflicts between rows of the same
+// file.
+package importer
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/lib/pq"
+	db "github.com/nirajan1111/routiney/db/sqlc"
+	"github.com/nirajan1111/routiney/timeslot"
+	"github.com/nirajan1111/routiney/validation"
+)
+
+// Entity is the kind of record a sheet holds.
+type Entity string
+
+const (
+	Rooms           Entity = "room"
+	Teachers        Entity = "teacher"
+	Subjects        Entity = "subject"
+	StudentSections Entity = "student_section"
+	Schedules       Entity = "schedule"
+)
+
+// ParseEntity accepts the entity names with or without a trailing "s".
+func ParseEntity(s string) (Entity, error) {
+	e := Entity(strings.TrimSuffix(strings.ToLower(strings.TrimSpace(s)), "s"))
+	if _, ok := entities[e]; !ok {
+		return "", fmt.Errorf("unknown entity %q, expected one of room, teacher, subject, student_section, schedules", s)
+	}
+	return e, nil
+}
+
+type entity struct {
+	required []string
+	insert   func(ctx context.Context, q *db.Queries, r record, opts Options) error
+}
+
+var entities = map[Entity]entity{
+	Rooms: {
+		required: []string{"room_code"},
+		insert:   insertRoom,
+	},
+	Teachers: {
+		required: []string{"email"},
+		insert:   insertTeacher,
+	},
+	Subjects: {
+		required: []string{"subject_code"},
+		insert:   insertSubject,
+	},
+	StudentSections: {
+		required: []string{"name"},
+		insert:   insertStudentSection,
+	},
+	Schedules: {
+		required: []string{"section", "subject_code", "teacher_email", "room_code", "time_slot"},
+		insert:   insertSchedule,
+	},
+}
+
+// Options controls an import.
+type Options struct {
+	// DryRun validates and reports without committing anything.
+	DryRun bool
+	// Year is used for schedule rows without a year column.
+	Year int32
+	// Term is used for schedule rows without a term column; zero means
+	// the first term.
+	Term int16
+}
+
+// RowError describes why one row was rejected. Row is the line number in the
+// sheet, counting the header as line 1.
+type RowError struct {
+	Row       int                   `json:"row"`
+	Error     string                `json:"error"`
+	Conflicts []validation.Conflict `json:"conflicts,omitempty"`
+}
+
+// Report is the outcome of an import.
+type Report struct {
+	Entity    Entity     `json:"entity"`
+	DryRun    bool       `json:"dry_run"`
+	Rows      int        `json:"rows"`
+	Valid     int        `json:"valid"`
+	Committed bool       `json:"committed"`
+	Errors    []RowError `json:"errors"`
+	// Stopped is set when a database error aborted the transaction, in
+	// which case the rows after it were not checked.
+	Stopped bool `json:"stopped,omitempty"`
+}
+
+// rowError is a problem with the data of a row, as opposed to a failure of
+// the database.
+type rowError struct {
+	msg       string
+	conflicts []validation.Conflict
+}
+
+func (e *rowError) Error() string { return e.msg }
+
+func rowErrorf(format string, args ...interface{}) error {
+	return &rowError{msg: fmt.Sprintf(format, args...)}
+}
+
+// ErrInvalidSheet is returned when a sheet cannot be imported at all, e.g.
+// because a required column is missing.
+var ErrInvalidSheet = errors.New("invalid sheet")
+
+var errRollback = errors.New("rollback")
+
+// Run imports table as entity e inside a single transaction on store.
+func Run(ctx context.Context, store *db.Store, e Entity, table Table, opts Options) (Report, error) {
+	report := Report{Entity: e, DryRun: opts.DryRun, Rows: len(table.Rows), Errors: []RowError{}}
+	spec, ok := entities[e]
+	if !ok {
+		return report, fmt.Errorf("%w: unknown entity %q", ErrInvalidSheet, e)
+	}
+
+	columns := make(map[string]int, len(table.Header))
+	for i, name := range table.Header {
+		if _, dup := columns[name]; !dup {
+			columns[name] = i
+		}
+	}
+	var missing []string
+	for _, name := range spec.required {
+		if _, ok := columns[name]; !ok {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		return report, fmt.Errorf("%w: missing column(s) %s", ErrInvalidSheet, strings.Join(missing, ", "))
+	}
+
+	err := store.ExecTx(ctx, func(q *db.Queries) error {
+		for i, cells := range table.Rows {
+			line := i + 2
+			if blank(cells) {
+				report.Rows--
+				continue
+			}
+			err := spec.insert(ctx, q, record{columns: columns, cells: cells}, opts)
+			if err == nil {
+				report.Valid++
+				continue
+			}
+
+			var rowErr *rowError
+			switch {
+			case errors.As(err, &rowErr):
+				report.Errors = append(report.Errors, RowError{Row: line, Error: rowErr.msg, Conflicts: rowErr.conflicts})
+			case isDataError(err):
+				// the transaction is aborted, nothing after this row can run
+				report.Errors = append(report.Errors, RowError{Row: line, Error: err.Error()})
+				report.Stopped = true
+				return errRollback
+			default:
+				return fmt.Errorf("row %d: %w", line, err)
+			}
+		}
+		if opts.DryRun || len(report.Errors) > 0 {
+			return errRollback
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, errRollback) {
+		return report, err
+	}
+	report.Committed = err == nil
+	return report, nil
+}
+
+// isDataError reports whether Postgres rejected a row for its content
+// (class 22, data exception, or 23, integrity constraint violation).
+func isDataError(err error) bool {
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) {
+		return false
+	}
+	class := pqErr.Code.Class()
+	return class == "22" || class == "23"
+}
+
+func nullString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
+
+func nullInt32(r record, column string) (sql.NullInt32, error) {
+	s := r.get(column)
+	if s == "" {
+		return sql.NullInt32{}, nil
+	}
+	n, err := strconv.ParseInt(strings.TrimSuffix(s, ".0"), 10, 32)
+	if err != nil {
+		return sql.NullInt32{}, rowErrorf("%s: %q is not a whole number", column, s)
+	}
+	return sql.NullInt32{Int32: int32(n), Valid: true}, nil
+}
+
+func nullBool(r record, column string) (sql.NullBool, error) {
+	switch strings.ToLower(r.get(column)) {
+	case "":
+		return sql.NullBool{}, nil
+	case "true", "yes", "y", "1":
+		return sql.NullBool{Bool: true, Valid: true}, nil
+	case "false", "no", "n", "0":
+		return sql.NullBool{Bool: false, Valid: true}, nil
+	}
+	return sql.NullBool{}, rowErrorf("%s: %q is not yes or no", column, r.get(column))
+}
+
+func required(r record, columns ...string) error {
+	var empty []string
+	for _, column := range columns {
+		if r.get(column) == "" {
+			empty = append(empty, column)
+		}
+	}
+	if len(empty) > 0 {
+		return rowErrorf("%s is required", strings.Join(empty, ", "))
+	}
+	return nil
+}
+
+func insertRoom(ctx context.Context, q *db.Queries, r record, opts Options) error {
+	if err := required(r, "room_code"); err != nil {
+		return err
+	}
+	code := r.get("room_code")
+	if _, err := q.GetRoomByCode(ctx, code); err == nil {
+		return rowErrorf("room %s already exists", code)
+	} else if !errors.Is(err, sql.ErrNoRows) {
+		return err
+	}
+
+	floor, err := nullInt32(r, "floor_no")
+	if err != nil {
+		return err
+	}
+	screen, err := nullBool(r, "screen_available")
+	if err != nil {
+		return err
+	}
+	capacity, err := nullInt32(r, "capacity")
+	if err != nil {
+		return err
+	}
+	roomType := db.RoomTypeLectureHall
+	switch t := db.RoomType(strings.ToLower(r.get("room_type"))); t {
+	case "":
+	case db.RoomTypeLectureHall, db.RoomTypeComputerLab, db.RoomTypeLaboratory, db.RoomTypeWorkshop:
+		roomType = t
+	default:
+		return rowErrorf("unknown room_type %q", r.get("room_type"))
+	}
+	room, err := q.CreateRoom(ctx, db.CreateRoomParams{
+		RoomCode:        nullString(code),
+		BlockNo:         nullString(r.get("block_no")),
+		FloorNo:         floor,
+		ScreenAvailable: screen,
+		Department:      nullString(r.get("department")),
+		Capacity:        capacity,
+		RoomType:        roomType,
+	})
+	if err != nil {
+		return err
+	}
+	// features is a semicolon separated list of tags such as
+	// "projector; computers".
+	var features []string
+	for _, f := range strings.Split(r.get("features"), ";") {
+		if f = strings.ToLower(strings.TrimSpace(f)); f != "" {
+			features = append(features, f)
+		}
+	}
+	if len(features) == 0 {
+		return nil
+	}
+	return q.AddRoomFeatures(ctx, db.AddRoomFeaturesParams{RoomID: room.ID, Features: features})
+}
+
+func insertTeacher(ctx context.Context, q *db.Queries, r record, opts Options) error {
+	if err := required(r, "email"); err != nil {
+		return err
+	}
+	email := strings.ToLower(r.get("email"))
+	if !strings.Contains(email, "@") {
+		return rowErrorf("email: %q is not an email address", email)
+	}
+	if _, err := q.GetTeacherByEmail(ctx, email); err == nil {
+		return rowErrorf("teacher %s already exists", email)
+	} else if !errors.Is(err, sql.ErrNoRows) {
+		return err
+	}
+
+	_, err := q.CreateTeacher(ctx, db.CreateTeacherParams{
+		Name:        nullString(r.get("name")),
+		Email:       email,
+		Department:  nullString(r.get("department")),
+		Designation: nullString(r.get("designation")),
+	})
+	return err
+}
+
+func insertSubject(ctx context.Context, q *db.Queries, r record, opts Options) error {
+	if err := required(r, "subject_code"); err != nil {
+		return err
+	}
+	code := r.get("subject_code")
+	if _, err := q.GetSubjectByCode(ctx, nullString(code)); err == nil {
+		return rowErrorf("subject %s already exists", code)
+	} else if !errors.Is(err, sql.ErrNoRows) {
+		return err
+	}
+
+	_, err := q.CreateSubject(ctx, db.CreateSubjectParams{
+		SubjectCode: nullString(code),
+		Name:        nullString(r.get("name")),
+		Department:  nullString(r.get("department")),
+	})
+	return err
+}
+
+func insertStudentSection(ctx context.Context, q *db.Queries, r record, opts Options) error {
+	if err := required(r, "name"); err != nil {
+		return err
+	}
+	name := r.get("name")
+	existing, err := q.ListStudentSectionsByName(ctx, name)
+	if err != nil {
+		return err
+	}
+	if len(existing) > 0 {
+		return rowErrorf("student section %s already exists", name)
+	}
+
+	yearEnrolled, err := nullInt32(r, "year_enrolled")
+	if err != nil {
+		return err
+	}
+	size, err := nullInt32(r, "size")
+	if err != nil {
+		return err
+	}
+	_, err = q.CreateStudentSection(ctx, db.CreateStudentSectionParams{
+		Name:         nullString(name),
+		Program:      nullString(r.get("program")),
+		YearEnrolled: yearEnrolled,
+		GroupName:    nullString(r.get("group_name")),
+		Department:   nullString(r.get("department")),
+		Size:         size,
+	})
+	return err
+}
+
+func insertSchedule(ctx context.Context, q *db.Queries, r record, opts Options) error {
+	if err := required(r, "section", "subject_code", "teacher_email", "room_code", "time_slot"); err != nil {
+		return err
+	}
+
+	var problems []string
+	slot, slotErr := timeslot.Parse(r.get("time_slot"))
+	if slotErr != nil {
+		problems = append(problems, slotErr.Error())
+	}
+	year := opts.Year
+	if y, err := nullInt32(r, "year"); err != nil {
+		problems = append(problems, err.Error())
+	} else if y.Valid {
+		year = y.Int32
+	}
+	if year == 0 {
+		problems = append(problems, "year is required")
+	}
+	term := opts.Term
+	if t, err := nullInt32(r, "term"); err != nil {
+		problems = append(problems, err.Error())
+	} else if t.Valid {
+		term = int16(t.Int32)
+	}
+	if term == 0 {
+		term = 1
+	}
+	if year != 0 {
+		if _, err := q.GetAcademicTerm(ctx, db.GetAcademicTermParams{Year: year, Term: term}); errors.Is(err, sql.ErrNoRows) {
+			problems = append(problems, fmt.Sprintf("term %d of %d has not been set up", term, year))
+		} else if err != nil {
+			return err
+		}
+	}
+	sessionType := db.SessionTypeLecture
+	switch t := db.SessionType(strings.ToLower(r.get("session_type"))); t {
+	case "":
+	case db.SessionTypeLecture, db.SessionTypeLab, db.SessionTypeTutorial:
+		sessionType = t
+	default:
+		problems = append(problems, fmt.Sprintf("unknown session_type %q", r.get("session_type")))
+	}
+
+	var groupID int64
+	sectionName := r.get("section")
+	sections, err := q.ListStudentSectionsByName(ctx, sectionName)
+	switch {
+	case err != nil:
+		return err
+	case len(sections) == 0:
+		problems = append(problems, fmt.Sprintf("student section %s not found", sectionName))
+	case len(sections) > 1:
+		problems = append(problems, fmt.Sprintf("student section name %s is ambiguous", sectionName))
+	default:
+		groupID = int64(sections[0].ID)
+	}
+	// the slot must exist in the grid of the section's program
+	if slotErr == nil && groupID != 0 {
+		week, err := validation.GridFor(ctx, q, groupID)
+		if err != nil {
+			return err
+		}
+		if periods, err := nullInt32(r, "periods"); err != nil {
+			problems = append(problems, err.Error())
+		} else if periods.Valid {
+			// a multi-period session starting at time_slot
+			if slot, err = week.Span(slot.Day, slot.Start, int(periods.Int32)); err != nil {
+				problems = append(problems, err.Error())
+			}
+		} else if err := week.Check(slot); err != nil {
+			problems = append(problems, err.Error())
+		}
+	}
+	var subGroupID int64
+	if name := r.get("sub_group"); name != "" && groupID != 0 {
+		if subGroup, err := q.GetSubGroupByName(ctx, db.GetSubGroupByNameParams{GroupID: int32(groupID), Name: name}); err == nil {
+			subGroupID = subGroup.ID
+		} else if errors.Is(err, sql.ErrNoRows) {
+			problems = append(problems, fmt.Sprintf("section %s has no sub-group %s", sectionName, name))
+		} else {
+			return err
+		}
+	}
+
+	// combined_with is a semicolon separated list of the other sections
+	// attending a combined class, e.g. "BCT-A; BCT-B".
+	var combined []int64
+	for _, name := range strings.Split(r.get("combined_with"), ";") {
+		if name = strings.TrimSpace(name); name == "" {
+			continue
+		}
+		others, err := q.ListStudentSectionsByName(ctx, name)
+		switch {
+		case err != nil:
+			return err
+		case len(others) == 0:
+			problems = append(problems, fmt.Sprintf("student section %s not found", name))
+		case len(others) > 1:
+			problems = append(problems, fmt.Sprintf("student section name %s is ambiguous", name))
+		case int64(others[0].ID) == groupID:
+			problems = append(problems, fmt.Sprintf("section %s cannot be combined with itself", name))
+		default:
+			combined = append(combined, int64(others[0].ID))
+		}
+	}
+	if len(combined) > 0 && subGroupID != 0 {
+		problems = append(problems, "a sub-group class cannot be combined with other sections")
+	}
+
+	var subjectID int64
+	subjectCode := r.get("subject_code")
+	if subject, err := q.GetSubjectByCode(ctx, nullString(subjectCode)); err == nil {
+		subjectID = subject.ID
+	} else if errors.Is(err, sql.ErrNoRows) {
+		problems = append(problems, fmt.Sprintf("subject %s not found", subjectCode))
+	} else {
+		return err
+	}
+
+	email := strings.ToLower(r.get("teacher_email"))
+	if _, err := q.GetTeacherByEmail(ctx, email); errors.Is(err, sql.ErrNoRows) {
+		problems = append(problems, fmt.Sprintf("teacher %s not found", email))
+	} else if err != nil {
+		return err
+	}
+
+	var roomID int64
+	roomCode := r.get("room_code")
+	if room, err := q.GetRoomByCode(ctx, roomCode); err == nil {
+		roomID = int64(room.ID)
+	} else if errors.Is(err, sql.ErrNoRows) {
+		problems = append(problems, fmt.Sprintf("room %s not found", roomCode))
+	} else {
+		return err
+	}
+
+	if len(problems) > 0 {
+		return rowErrorf("%s", strings.Join(problems, "; "))
+	}
+
+	arg := db.CreateScheduleParams{
+		GroupID:          sql.NullInt64{Int64: groupID, Valid: true},
+		RoomID:           sql.NullInt64{Int64: roomID, Valid: true},
+		SubjectID:        sql.NullInt64{Int64: subjectID, Valid: true},
+		TeacherEmail:     nullString(email),
+		TimeSlot:         nullString(slot.String()),
+		Year:             year,
+		Term:             term,
+		DayOfWeek:        int16(slot.Day),
+		StartMinute:      slot.Start,
+		EndMinute:        slot.End,
+		SessionType:      sessionType,
+		SubGroupID:       sql.NullInt64{Int64: subGroupID, Valid: subGroupID != 0},
+		CombinedGroupIds: combined,
+	}
+	result, err := validation.New(q).Validate(ctx, validation.FromCreateParams(arg))
+	if err != nil {
+		return err
+	}
+	if conflictErr := result.ConflictErr(); conflictErr != nil {
+		return &rowError{msg: conflictErr.Error(), conflicts: conflictErr.Conflicts}
+	}
+
+	_, err = q.CreateSchedule(ctx, arg)
+	return err
+}
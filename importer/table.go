@@ -0,0 +1,98 @@
+package importer
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+)
+
+// Format is a spreadsheet file format.
+type Format string
+
+const (
+	CSV  Format = "csv"
+	XLSX Format = "xlsx"
+)
+
+// FormatFromName guesses the format from a file name's extension.
+func FormatFromName(name string) (Format, error) {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".csv":
+		return CSV, nil
+	case ".xlsx":
+		return XLSX, nil
+	}
+	return "", fmt.Errorf("unsupported file type %q, expected .csv or .xlsx", filepath.Ext(name))
+}
+
+// Table is a sheet of text cells. The first line of the file is the header;
+// Rows holds the lines after it.
+type Table struct {
+	Header []string
+	Rows   [][]string
+}
+
+// Read reads a table in the given format.
+func Read(r io.Reader, format Format) (Table, error) {
+	var records [][]string
+	var err error
+	switch format {
+	case CSV:
+		cr := csv.NewReader(r)
+		cr.FieldsPerRecord = -1
+		cr.TrimLeadingSpace = true
+		records, err = cr.ReadAll()
+	case XLSX:
+		records, err = readXLSX(r)
+	default:
+		return Table{}, fmt.Errorf("unsupported format %q", format)
+	}
+	if err != nil {
+		return Table{}, err
+	}
+
+	// spreadsheets often carry blank lines at the end
+	for len(records) > 0 && blank(records[len(records)-1]) {
+		records = records[:len(records)-1]
+	}
+	if len(records) == 0 {
+		return Table{}, fmt.Errorf("file is empty")
+	}
+
+	header := make([]string, len(records[0]))
+	for i, name := range records[0] {
+		header[i] = normalizeColumn(name)
+	}
+	return Table{Header: header, Rows: records[1:]}, nil
+}
+
+// normalizeColumn maps "Room Code" and "room-code" to "room_code".
+func normalizeColumn(name string) string {
+	name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+	return strings.NewReplacer(" ", "_", "-", "_").Replace(name)
+}
+
+func blank(record []string) bool {
+	for _, cell := range record {
+		if strings.TrimSpace(cell) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// record gives access to one row by column name.
+type record struct {
+	columns map[string]int
+	cells   []string
+}
+
+func (r record) get(name string) string {
+	i, ok := r.columns[name]
+	if !ok || i >= len(r.cells) {
+		return ""
+	}
+	return strings.TrimSpace(r.cells[i])
+}
@@ -0,0 +1,56 @@
+package importer
+
+import (
+	"archive/zip"
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestReadCSV(t *testing.T) {
+	in := "\ufeffRoom Code,Block-No,floor_no\nA101, A,1\n\n,,\n"
+	table, err := Read(strings.NewReader(in), CSV)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"room_code", "block_no", "floor_no"}; !reflect.DeepEqual(table.Header, want) {
+		t.Errorf("header = %q, want %q", table.Header, want)
+	}
+	if want := [][]string{{"A101", "A", "1"}}; !reflect.DeepEqual(table.Rows, want) {
+		t.Errorf("rows = %q, want %q", table.Rows, want)
+	}
+}
+
+func TestReadXLSX(t *testing.T) {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	parts := map[string]string{
+		"xl/sharedStrings.xml": `<sst xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">` +
+			`<si><t>section</t></si><si><t>time_slot</t></si><si><r><t>BCT </t></r><r><t>2078 A</t></r></si></sst>`,
+		"xl/worksheets/sheet1.xml": `<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>` +
+			`<row r="1"><c r="A1" t="s"><v>0</v></c><c r="B1" t="s"><v>1</v></c><c r="C1" t="inlineStr"><is><t>year</t></is></c></row>` +
+			`<row r="3"><c r="A3" t="s"><v>2</v></c><c r="C3"><v>2081</v></c></row>` +
+			`</sheetData></worksheet>`,
+	}
+	for name, body := range parts {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Write([]byte(body))
+	}
+	zw.Close()
+
+	table, err := Read(&buf, XLSX)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"section", "time_slot", "year"}; !reflect.DeepEqual(table.Header, want) {
+		t.Errorf("header = %q, want %q", table.Header, want)
+	}
+	// the empty second line keeps the third at index 1
+	if len(table.Rows) != 2 || !reflect.DeepEqual(table.Rows[1], []string{"BCT 2078 A", "", "2081"}) {
+		t.Errorf("rows = %q", table.Rows)
+	}
+}
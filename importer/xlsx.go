@@ -0,0 +1,198 @@
+package importer
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// readXLSX returns the cells of the first worksheet of an Office Open XML
+// workbook. Only what spreadsheet exports need is supported: shared and
+// inline strings, numbers and booleans. Formulas yield their cached value.
+func readXLSX(r io.Reader) ([][]string, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("not an xlsx file: %w", err)
+	}
+	files := make(map[string]*zip.File, len(zr.File))
+	for _, f := range zr.File {
+		files[f.Name] = f
+	}
+
+	var shared []string
+	if f, ok := files["xl/sharedStrings.xml"]; ok {
+		if shared, err = readSharedStrings(f); err != nil {
+			return nil, err
+		}
+	}
+
+	sheet, err := firstSheet(files)
+	if err != nil {
+		return nil, err
+	}
+	return readSheet(sheet, shared)
+}
+
+type xlsxText struct {
+	T    string `xml:"t"`
+	Runs []struct {
+		T string `xml:"t"`
+	} `xml:"r"`
+}
+
+func (t xlsxText) String() string {
+	if len(t.Runs) == 0 {
+		return t.T
+	}
+	var b strings.Builder
+	for _, run := range t.Runs {
+		b.WriteString(run.T)
+	}
+	return b.String()
+}
+
+func readSharedStrings(f *zip.File) ([]string, error) {
+	var sst struct {
+		Items []xlsxText `xml:"si"`
+	}
+	if err := decodeXML(f, &sst); err != nil {
+		return nil, fmt.Errorf("shared strings: %w", err)
+	}
+	shared := make([]string, len(sst.Items))
+	for i, item := range sst.Items {
+		shared[i] = item.String()
+	}
+	return shared, nil
+}
+
+// firstSheet finds the first sheet listed in the workbook, falling back to
+// the lowest numbered worksheet part.
+func firstSheet(files map[string]*zip.File) (*zip.File, error) {
+	if wb, ok := files["xl/workbook.xml"]; ok {
+		var workbook struct {
+			Sheets []struct {
+				RID string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+			} `xml:"sheets>sheet"`
+		}
+		var rels struct {
+			Rels []struct {
+				ID     string `xml:"Id,attr"`
+				Target string `xml:"Target,attr"`
+			} `xml:"Relationship"`
+		}
+		relsFile, ok := files["xl/_rels/workbook.xml.rels"]
+		if ok && decodeXML(wb, &workbook) == nil && decodeXML(relsFile, &rels) == nil && len(workbook.Sheets) > 0 {
+			for _, rel := range rels.Rels {
+				if rel.ID != workbook.Sheets[0].RID {
+					continue
+				}
+				target := strings.TrimPrefix(rel.Target, "/")
+				if !strings.HasPrefix(target, "xl/") {
+					target = path.Join("xl", target)
+				}
+				if f, ok := files[target]; ok {
+					return f, nil
+				}
+			}
+		}
+	}
+
+	var names []string
+	for name := range files {
+		if strings.HasPrefix(name, "xl/worksheets/sheet") && strings.HasSuffix(name, ".xml") {
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 {
+		return nil, fmt.Errorf("xlsx file has no worksheet")
+	}
+	sort.Strings(names)
+	return files[names[0]], nil
+}
+
+func readSheet(f *zip.File, shared []string) ([][]string, error) {
+	var sheet struct {
+		Rows []struct {
+			R     int `xml:"r,attr"`
+			Cells []struct {
+				Ref    string   `xml:"r,attr"`
+				Type   string   `xml:"t,attr"`
+				Value  string   `xml:"v"`
+				Inline xlsxText `xml:"is"`
+			} `xml:"c"`
+		} `xml:"sheetData>row"`
+	}
+	if err := decodeXML(f, &sheet); err != nil {
+		return nil, fmt.Errorf("worksheet: %w", err)
+	}
+
+	var records [][]string
+	for i, row := range sheet.Rows {
+		// rows may be sparse; keep line numbers aligned with the sheet
+		n := row.R
+		if n == 0 {
+			n = i + 1
+		}
+		for len(records) < n {
+			records = append(records, nil)
+		}
+		var cells []string
+		for j, c := range row.Cells {
+			col := j
+			if c.Ref != "" {
+				col = columnIndex(c.Ref)
+			}
+			for len(cells) <= col {
+				cells = append(cells, "")
+			}
+			switch c.Type {
+			case "s":
+				idx, err := strconv.Atoi(c.Value)
+				if err != nil || idx < 0 || idx >= len(shared) {
+					return nil, fmt.Errorf("cell %s: bad shared string index %q", c.Ref, c.Value)
+				}
+				cells[col] = shared[idx]
+			case "inlineStr":
+				cells[col] = c.Inline.String()
+			case "b":
+				cells[col] = map[string]string{"1": "true", "0": "false"}[c.Value]
+			default:
+				cells[col] = c.Value
+			}
+		}
+		records[n-1] = cells
+	}
+	return records, nil
+}
+
+// columnIndex converts the letters of a cell reference such as "AB12" to a
+// zero based column index.
+func columnIndex(ref string) int {
+	col := 0
+	for _, ch := range ref {
+		if ch < 'A' || ch > 'Z' {
+			break
+		}
+		col = col*26 + int(ch-'A'+1)
+	}
+	return col - 1
+}
+
+func decodeXML(f *zip.File, v interface{}) error {
+	rc, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	return xml.NewDecoder(rc).Decode(v)
+}
@@ -1,16 +1,19 @@
 package main
 
 import (
+	"context"
 	"database/sql"
 	"log"
 	"net/url"
 	"os"
+	"strconv"
 	"time"
 
 	"github.com/joho/godotenv" // Add this import
 	_ "github.com/lib/pq"
 	api "github.com/nirajan1111/routiney/apis"
 	db "github.com/nirajan1111/routiney/db/sqlc"
+	"github.com/nirajan1111/routiney/notify"
 )
 
 func main() {
@@ -57,6 +60,30 @@ func main() {
 		log.Fatal("cannot create server:", err)
 	}
 
+	// Emails about routine changes are only sent when an SMTP server is
+	// configured
+	if smtpHost := os.Getenv("SMTP_HOST"); smtpHost != "" {
+		sender := notify.SMTPSender{
+			Host:     smtpHost,
+			Port:     587,
+			Username: os.Getenv("SMTP_USERNAME"),
+			Password: os.Getenv("SMTP_PASSWORD"),
+			From:     os.Getenv("SMTP_FROM"),
+		}
+		if port := os.Getenv("SMTP_PORT"); port != "" {
+			sender.Port, err = strconv.Atoi(port)
+			if err != nil {
+				log.Fatal("invalid SMTP_PORT:", err)
+			}
+		}
+		if sender.From == "" {
+			log.Fatal("SMTP_FROM is required when SMTP_HOST is set")
+		}
+		server.EnableNotifications()
+		go notify.NewWorker(store, sender).Run(context.Background())
+		log.Printf("Sending notifications through %s:%d", sender.Host, sender.Port)
+	}
+
 	log.Printf("Starting server on %s", serverAddress)
 	err = server.Start(serverAddress)
 	if err != nil {
@@ -0,0 +1,242 @@
+// Package nlquery turns short English questions about the routine into
+// structured queries. Parsing is rule based and deterministic, so the same
+// text always yields the same query.
+//
+// Supported shapes include:
+//
+//	classes for BCT 2078 A on Monday
+//	where is Ram Sharma at 17:00 on TUE
+//	routine of room A101 on sunday
+//	free rooms in block B on WED at 5:30 pm
+package nlquery
+
+import (
+	"errors"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/nirajan1111/routiney/timeslot"
+)
+
+// Kind is what a query asks for.
+type Kind int
+
+const (
+	// KindGroup lists the classes of a student section.
+	KindGroup Kind = iota + 1
+	// KindTeacher lists the classes of a teacher.
+	KindTeacher
+	// KindRoom lists the classes held in a room.
+	KindRoom
+	// KindFreeRooms lists rooms with nothing scheduled.
+	KindFreeRooms
+)
+
+func (k Kind) String() string {
+	switch k {
+	case KindGroup:
+		return "group"
+	case KindTeacher:
+		return "teacher"
+	case KindRoom:
+		return "room"
+	case KindFreeRooms:
+		return "free_rooms"
+	}
+	return "unknown"
+}
+
+// Query is the structured form of a question.
+type Query struct {
+	Kind Kind
+
+	// Section, for KindGroup: program code, batch (year enrolled) and
+	// optional group name, e.g. BCT, 2078, A.
+	Program   string
+	Batch     int32
+	GroupName string
+
+	// Teacher is the (partial) teacher name for KindTeacher.
+	Teacher string
+
+	// RoomCode is the room for KindRoom.
+	RoomCode string
+
+	// Block and Floor filter KindFreeRooms.
+	Block    string
+	Floor    int32
+	HasFloor bool
+
+	Day    timeslot.Day
+	HasDay bool
+
+	// Minute is a wall clock time in minutes since midnight.
+	Minute  int32
+	HasTime bool
+
+	// Year is the routine year, when the question names one ("year 2081").
+	Year int32
+}
+
+// ErrNotUnderstood is returned when no supported shape matches.
+var ErrNotUnderstood = errors.New("could not understand the query; try e.g. \"classes for BCT 2078 A on Monday\", \"where is Ram Sharma at 17:00 on TUE\" or \"free rooms in block B on WED\"")
+
+var (
+	timeRe       = regexp.MustCompile(`(?i)\b(\d{1,2})(?::(\d{2}))?\s*(am|pm)?\b`)
+	yearRe       = regexp.MustCompile(`(?i)\byear\s+(\d{4})\b`)
+	sectionRe    = regexp.MustCompile(`(?i)\b([a-z]{2,5})\s*[- ]?\s*(\d{4})(?:\s*[- ]?\s*([a-z])\b)?`)
+	blockRe      = regexp.MustCompile(`(?i)\bblock\s+([a-z0-9]+)\b`)
+	floorRe      = regexp.MustCompile(`(?i)\bfloor\s+(\d+)\b|\b(\d+)(?:st|nd|rd|th)\s+floor\b`)
+	roomRe       = regexp.MustCompile(`(?i)\broom\s+([a-z0-9-]+)\b`)
+	teacherRe    = regexp.MustCompile(`(?i)^(?:where\s+is|where's|find|locate|teacher|classes\s+of|schedule\s+of|routine\s+of)\s+(?:teacher\s+|mr\.?\s+|mrs\.?\s+|ms\.?\s+|dr\.?\s+|prof\.?\s+)?(.+)$`)
+	possessiveRe = regexp.MustCompile(`(?i)^(.+?)'s\s+(?:classes|routine|schedule|lectures)\b`)
+	freeRe       = regexp.MustCompile(`(?i)\b(free|empty|available|vacant)\b.*\brooms?\b|\brooms?\b.*\b(free|empty|available|vacant)\b`)
+)
+
+// stop words end a teacher name
+var stopWords = map[string]bool{
+	"at": true, "on": true, "in": true, "during": true, "for": true, "today": true,
+	"now": true, "teaching": true, "this": true, "year": true,
+}
+
+// Parse parses a question into a Query.
+func Parse(text string) (Query, error) {
+	var q Query
+	s := strings.TrimSpace(strings.TrimRight(strings.TrimSpace(text), "?.!"))
+	if s == "" {
+		return q, ErrNotUnderstood
+	}
+
+	if m := yearRe.FindStringSubmatch(s); m != nil {
+		year, _ := strconv.Atoi(m[1])
+		q.Year = int32(year)
+		s = strings.TrimSpace(strings.Replace(s, m[0], " ", 1))
+	}
+
+	s = q.takeDay(s)
+	s = q.takeTime(s)
+
+	switch {
+	case freeRe.MatchString(s):
+		q.Kind = KindFreeRooms
+		if m := blockRe.FindStringSubmatch(s); m != nil {
+			q.Block = strings.ToUpper(m[1])
+		}
+		if m := floorRe.FindStringSubmatch(s); m != nil {
+			digits := m[1]
+			if digits == "" {
+				digits = m[2]
+			}
+			floor, _ := strconv.Atoi(digits)
+			q.Floor, q.HasFloor = int32(floor), true
+		}
+		return q, nil
+
+	case roomRe.MatchString(s):
+		q.Kind = KindRoom
+		q.RoomCode = strings.ToUpper(roomRe.FindStringSubmatch(s)[1])
+		return q, nil
+	}
+
+	m := teacherRe.FindStringSubmatch(s)
+	if m == nil {
+		m = possessiveRe.FindStringSubmatch(s)
+	}
+	if m != nil {
+		if name := takeName(m[1]); name != "" && !sectionRe.MatchString(name) {
+			q.Kind = KindTeacher
+			q.Teacher = name
+			return q, nil
+		}
+	}
+
+	if m := sectionRe.FindStringSubmatch(s); m != nil && !isFiller(m[1]) {
+		batch, _ := strconv.Atoi(m[2])
+		q.Kind = KindGroup
+		q.Program = strings.ToUpper(m[1])
+		q.Batch = int32(batch)
+		q.GroupName = strings.ToUpper(m[3])
+		return q, nil
+	}
+
+	return q, ErrNotUnderstood
+}
+
+// takeDay removes the first day name from s and records it.
+func (q *Query) takeDay(s string) string {
+	words := strings.Fields(s)
+	for i, w := range words {
+		day, err := timeslot.ParseDay(strings.TrimSuffix(strings.Trim(w, ",.;:"), "'s"))
+		if err != nil {
+			continue
+		}
+		q.Day, q.HasDay = day, true
+		// drop a dangling "on" before the day
+		if i > 0 && strings.EqualFold(words[i-1], "on") {
+			return strings.Join(append(words[:i-1:i-1], words[i+1:]...), " ")
+		}
+		return strings.Join(append(words[:i:i], words[i+1:]...), " ")
+	}
+	return s
+}
+
+// takeTime removes a clock time introduced by "at" (or written with a
+// colon or am/pm) and records it.
+func (q *Query) takeTime(s string) string {
+	for _, loc := range timeRe.FindAllStringSubmatchIndex(s, -1) {
+		hasColon := loc[4] >= 0
+		hasMeridiem := loc[6] >= 0
+		before := strings.TrimSpace(s[:loc[0]])
+		afterAt := strings.HasSuffix(strings.ToLower(before), " at") || strings.EqualFold(before, "at")
+		if !hasColon && !hasMeridiem && !afterAt {
+			continue
+		}
+		hour, _ := strconv.Atoi(s[loc[2]:loc[3]])
+		minute := 0
+		if hasColon {
+			minute, _ = strconv.Atoi(s[loc[4]:loc[5]])
+		}
+		if hasMeridiem {
+			meridiem := strings.ToLower(s[loc[6]:loc[7]])
+			if hour == 12 {
+				hour = 0
+			}
+			if meridiem == "pm" {
+				hour += 12
+			}
+		}
+		if hour > 23 || minute > 59 {
+			continue
+		}
+		q.Minute, q.HasTime = int32(hour*60+minute), true
+
+		start := loc[0]
+		if afterAt {
+			start = len(before) - 2
+		}
+		return strings.TrimSpace(s[:start] + " " + s[loc[1]:])
+	}
+	return s
+}
+
+func takeName(s string) string {
+	var name []string
+	for _, w := range strings.Fields(s) {
+		if stopWords[strings.ToLower(w)] {
+			break
+		}
+		name = append(name, strings.TrimSuffix(strings.TrimSuffix(w, "'s"), ","))
+	}
+	return strings.Join(name, " ")
+}
+
+// isFiller rejects words the section pattern could pick up by accident,
+// such as "year 2081" or "in 2081".
+func isFiller(word string) bool {
+	switch strings.ToLower(word) {
+	case "year", "in", "for", "batch", "of", "the":
+		return true
+	}
+	return false
+}
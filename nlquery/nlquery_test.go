@@ -0,0 +1,62 @@
+package nlquery
+
+import (
+	"testing"
+
+	"github.com/nirajan1111/routiney/timeslot"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		text string
+		want Query
+	}{
+		{
+			text: "classes for BCT 2078 A on Monday",
+			want: Query{Kind: KindGroup, Program: "BCT", Batch: 2078, GroupName: "A", Day: timeslot.Monday, HasDay: true},
+		},
+		{
+			text: "where is Ram Sharma at 17:00 on TUE?",
+			want: Query{Kind: KindTeacher, Teacher: "Ram Sharma", Day: timeslot.Tuesday, HasDay: true, Minute: 17 * 60, HasTime: true},
+		},
+		{
+			text: "Sita Karki's classes on sunday",
+			want: Query{Kind: KindTeacher, Teacher: "Sita Karki", Day: timeslot.Sunday, HasDay: true},
+		},
+		{
+			text: "free rooms in block B on WED",
+			want: Query{Kind: KindFreeRooms, Block: "B", Day: timeslot.Wednesday, HasDay: true},
+		},
+		{
+			text: "empty rooms on 2nd floor at 5:30 pm on thursday",
+			want: Query{Kind: KindFreeRooms, Floor: 2, HasFloor: true, Day: timeslot.Thursday, HasDay: true, Minute: 17*60 + 30, HasTime: true},
+		},
+		{
+			text: "routine of room a101 year 2081",
+			want: Query{Kind: KindRoom, RoomCode: "A101", Year: 2081},
+		},
+		{
+			text: "BEI-2079 classes",
+			want: Query{Kind: KindGroup, Program: "BEI", Batch: 2079},
+		},
+	}
+
+	for _, tc := range tests {
+		got, err := Parse(tc.text)
+		if err != nil {
+			t.Errorf("Parse(%q): unexpected error: %v", tc.text, err)
+			continue
+		}
+		if got != tc.want {
+			t.Errorf("Parse(%q) = %+v, want %+v", tc.text, got, tc.want)
+		}
+	}
+}
+
+func TestParseNotUnderstood(t *testing.T) {
+	for _, text := range []string{"", "hello there", "what is the meaning of life"} {
+		if _, err := Parse(text); err != ErrNotUnderstood {
+			t.Errorf("Parse(%q): expected ErrNotUnderstood, got %v", text, err)
+		}
+	}
+}
@@ -0,0 +1,124 @@
+// Package notify emails teachers and students when a class they teach or
+// attend is moved or cancelled by a newly published routine.
+//
+// Emails are not sent while handling the request that caused them. They are
+// written to the email_outbox table instead, and a Worker sends them in the
+// background over SMTP, retrying failures with a growing delay. Nothing is
+// lost when the SMTP server is down or the process restarts.
+//
+// For local development any SMTP catcher will do, e.g. Mailpit or MailHog
+// listening on localhost:1025:
+//
+//	SMTP_HOST=localhost SMTP_PORT=1025 SMTP_FROM=routine@example.com go run .
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"mime"
+	"mime/quotedprintable"
+	"net"
+	"net/smtp"
+	"strconv"
+	"time"
+)
+
+// Message is one plain text email.
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Sender delivers a message.
+type Sender interface {
+	Send(ctx context.Context, m Message) error
+}
+
+// SMTPSender sends through an SMTP server, upgrading to TLS when the server
+// offers STARTTLS. Without a Username it does not authenticate, which is
+// what local SMTP catchers expect.
+type SMTPSender struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	From     string
+	// Timeout bounds a whole send; it defaults to 30 seconds.
+	Timeout time.Duration
+}
+
+// Send implements Sender.
+func (s SMTPSender) Send(ctx context.Context, m Message) error {
+	timeout := s.Timeout
+	if timeout <= 0 {
+		timeout = 30 * time.Second
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", net.JoinHostPort(s.Host, strconv.Itoa(s.Port)))
+	if err != nil {
+		return err
+	}
+	deadline, _ := ctx.Deadline()
+	if err := conn.SetDeadline(deadline); err != nil {
+		conn.Close()
+		return err
+	}
+	c, err := smtp.NewClient(conn, s.Host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer c.Close()
+
+	if ok, _ := c.Extension("STARTTLS"); ok {
+		if err := c.StartTLS(&tls.Config{ServerName: s.Host}); err != nil {
+			return err
+		}
+	}
+	if s.Username != "" {
+		if err := c.Auth(smtp.PlainAuth("", s.Username, s.Password, s.Host)); err != nil {
+			return err
+		}
+	}
+	if err := c.Mail(s.From); err != nil {
+		return err
+	}
+	if err := c.Rcpt(m.To); err != nil {
+		return err
+	}
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(m.bytes(s.From, time.Now())); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return c.Quit()
+}
+
+// bytes renders m with its headers. The body is quoted-printable so that
+// non-ASCII text, such as Devanagari dates, survives servers without
+// 8BITMIME.
+func (m Message) bytes(from string, date time.Time) []byte {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "From: %s\r\n", from)
+	fmt.Fprintf(&b, "To: %s\r\n", m.To)
+	fmt.Fprintf(&b, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", m.Subject))
+	fmt.Fprintf(&b, "Date: %s\r\n", date.Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	b.WriteString("Content-Transfer-Encoding: quoted-printable\r\n\r\n")
+	qp := quotedprintable.NewWriter(&b)
+	qp.Write(bytes.ReplaceAll([]byte(m.Body), []byte("\n"), []byte("\r\n")))
+	qp.Close()
+	return b.Bytes()
+}
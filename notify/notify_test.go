@@ -0,0 +1,302 @@
+package notify
+
+import (
+	"bufio"
+	"context"
+	"database/sql"
+	"errors"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	db "github.com/nirajan1111/routiney/db/sqlc"
+)
+
+// catchSMTP accepts one SMTP session on a local port and returns what the
+// client sent as DATA.
+func catchSMTP(t *testing.T) (int, <-chan string) {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { l.Close() })
+	data := make(chan string, 1)
+	go func() {
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		r := bufio.NewReader(conn)
+		reply := func(s string) { conn.Write([]byte(s + "\r\n")) }
+		reply("220 localhost ready")
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				return
+			}
+			switch cmd := strings.ToUpper(strings.Fields(line + " x")[0]); cmd {
+			case "EHLO", "HELO":
+				reply("250-localhost")
+				reply("250 8BITMIME")
+			case "DATA":
+				reply("354 go ahead")
+				var b strings.Builder
+				for {
+					l, err := r.ReadString('\n')
+					if err != nil || l == ".\r\n" {
+						break
+					}
+					b.WriteString(l)
+				}
+				data <- b.String()
+				reply("250 queued")
+			case "QUIT":
+				reply("221 bye")
+				return
+			default:
+				reply("250 ok")
+			}
+		}
+	}()
+	return l.Addr().(*net.TCPAddr).Port, data
+}
+
+func TestSMTPSender(t *testing.T) {
+	port, data := catchSMTP(t)
+	s := SMTPSender{Host: "127.0.0.1", Port: port, From: "routine@example.com", Timeout: 5 * time.Second}
+	err := s.Send(context.Background(), Message{
+		To:      "ram@example.com",
+		Subject: "Class moved: CT 401 DBMS",
+		Body:    "Now: Sunday, २०८१ बैशाख १५\n",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := <-data
+	for _, want := range []string{
+		"From: routine@example.com\r\n",
+		"To: ram@example.com\r\n",
+		"Subject: Class moved: CT 401 DBMS\r\n",
+		"Content-Transfer-Encoding: quoted-printable\r\n",
+		"Now: Sunday, =E0=A5=A8",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("message missing %q:\n%s", want, got)
+		}
+	}
+}
+
+type fakeOutbox struct {
+	due    []db.EmailOutbox
+	sent   []int64
+	failed []db.MarkEmailFailedParams
+}
+
+func (f *fakeOutbox) ClaimDueEmails(ctx context.Context, maxEmails int32) ([]db.EmailOutbox, error) {
+	n := min(int(maxEmails), len(f.due))
+	claimed := f.due[:n]
+	f.due = f.due[n:]
+	return claimed, nil
+}
+
+func (f *fakeOutbox) MarkEmailSent(ctx context.Context, id int64) error {
+	f.sent = append(f.sent, id)
+	return nil
+}
+
+func (f *fakeOutbox) MarkEmailFailed(ctx context.Context, arg db.MarkEmailFailedParams) error {
+	f.failed = append(f.failed, arg)
+	return nil
+}
+
+type fakeSender map[string]error
+
+func (f fakeSender) Send(ctx context.Context, m Message) error {
+	return f[m.To]
+}
+
+func TestWorkerSendDue(t *testing.T) {
+	outbox := &fakeOutbox{due: []db.EmailOutbox{
+		{ID: 1, Recipient: "a@example.com"},
+		{ID: 2, Recipient: "down@example.com", Attempts: 0},
+		{ID: 3, Recipient: "down@example.com", Attempts: 7},
+	}}
+	w := NewWorker(outbox, fakeSender{"down@example.com": errors.New("connection refused")})
+	w.Batch = 2
+
+	sent, err := w.SendDue(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sent != 1 || len(outbox.sent) != 1 || outbox.sent[0] != 1 {
+		t.Errorf("sent %d %v, want only email 1", sent, outbox.sent)
+	}
+	if len(outbox.failed) != 2 {
+		t.Fatalf("failed %v, want emails 2 and 3", outbox.failed)
+	}
+	if f := outbox.failed[0]; f.ID != 2 || f.Status != db.EmailStatusPending || f.LastError.String != "connection refused" {
+		t.Errorf("first failure %+v, want it rescheduled", f)
+	}
+	if f := outbox.failed[1]; f.ID != 3 || f.Status != db.EmailStatusFailed {
+		t.Errorf("last attempt %+v, want it given up", f)
+	}
+}
+
+func TestRetryDelay(t *testing.T) {
+	for attempts, want := range map[int32]time.Duration{
+		1:  time.Minute,
+		2:  2 * time.Minute,
+		4:  8 * time.Minute,
+		8:  2 * time.Hour,
+		30: 2 * time.Hour,
+	} {
+		if got := retryDelay(attempts); got != want {
+			t.Errorf("retryDelay(%d) = %v, want %v", attempts, got, want)
+		}
+	}
+}
+
+type fakeQuerier struct {
+	published  map[int64][]db.PublishedSchedule
+	members    map[int64][]string
+	subMembers map[int64][]string
+	optedOut   []string
+	queued     []db.EnqueueEmailParams
+}
+
+func (f *fakeQuerier) ListPublishedSchedules(ctx context.Context, versionID int64) ([]db.PublishedSchedule, error) {
+	return f.published[versionID], nil
+}
+
+func (f *fakeQuerier) GetSubject(ctx context.Context, id int64) (db.Subject, error) {
+	return db.Subject{ID: id, SubjectCode: sql.NullString{String: "CT 40" + strconv.Itoa(int(id)), Valid: true}, Name: sql.NullString{String: "DBMS", Valid: true}}, nil
+}
+
+func (f *fakeQuerier) GetRoom(ctx context.Context, id int32) (db.Room, error) {
+	return db.Room{ID: id, RoomCode: sql.NullString{String: "R" + strconv.Itoa(int(id)), Valid: true}}, nil
+}
+
+func (f *fakeQuerier) GetStudentSection(ctx context.Context, id int32) (db.StudentSection, error) {
+	return db.StudentSection{ID: id, Name: sql.NullString{String: "BCT-" + strconv.Itoa(int(id)), Valid: true}}, nil
+}
+
+func (f *fakeQuerier) GetTeacherByEmail(ctx context.Context, email string) (db.Teacher, error) {
+	return db.Teacher{}, sql.ErrNoRows
+}
+
+func (f *fakeQuerier) ListSectionMemberEmails(ctx context.Context, groupIds []int64) ([]string, error) {
+	var emails []string
+	for _, id := range groupIds {
+		emails = append(emails, f.members[id]...)
+	}
+	return emails, nil
+}
+
+func (f *fakeQuerier) ListSubGroupMemberEmails(ctx context.Context, subGroupIds []int64) ([]string, error) {
+	var emails []string
+	for _, id := range subGroupIds {
+		emails = append(emails, f.subMembers[id]...)
+	}
+	return emails, nil
+}
+
+func (f *fakeQuerier) ListOptedOutEmails(ctx context.Context, emails []string) ([]string, error) {
+	return f.optedOut, nil
+}
+
+func (f *fakeQuerier) EnqueueEmail(ctx context.Context, arg db.EnqueueEmailParams) (db.EmailOutbox, error) {
+	f.queued = append(f.queued, arg)
+	return db.EmailOutbox{Recipient: arg.Recipient}, nil
+}
+
+func (f *fakeQuerier) recipients() string {
+	var to []string
+	for _, e := range f.queued {
+		to = append(to, e.Recipient)
+	}
+	return strings.Join(to, ",")
+}
+
+func TestRoutinePublished(t *testing.T) {
+	lecture := db.PublishedSchedule{
+		ScheduleID:   1,
+		GroupID:      sql.NullInt64{Int64: 1, Valid: true},
+		RoomID:       sql.NullInt64{Int64: 101, Valid: true},
+		SubjectID:    sql.NullInt64{Int64: 1, Valid: true},
+		TeacherEmail: sql.NullString{String: "Hari@example.com", Valid: true},
+		Year:         2081,
+		Term:         1,
+		DayOfWeek:    0,
+		StartMinute:  17*60 + 15,
+		EndMinute:    18*60 + 45,
+	}
+	lab := lecture
+	lab.ScheduleID = 2
+	lab.SubjectID = sql.NullInt64{Int64: 2, Valid: true}
+	lab.SubGroupID = sql.NullInt64{Int64: 7, Valid: true}
+	lab.TeacherEmail = sql.NullString{String: "gita@example.com", Valid: true}
+	lab.DayOfWeek = 1
+
+	previous := db.RoutineVersion{ID: 1, Year: 2081, Term: 1}
+	current := db.RoutineVersion{ID: 2, Year: 2081, Term: 1}
+	newQuerier := func(now ...db.PublishedSchedule) *fakeQuerier {
+		return &fakeQuerier{
+			published:  map[int64][]db.PublishedSchedule{1: {lecture, lab}, 2: now},
+			members:    map[int64][]string{1: {"ram@example.com", "hari@example.com", "sita@example.com"}},
+			subMembers: map[int64][]string{7: {"ram@example.com"}},
+			optedOut:   []string{"sita@example.com"},
+		}
+	}
+
+	moved := lecture
+	moved.RoomID = sql.NullInt64{Int64: 102, Valid: true}
+	q := newQuerier(moved, lab)
+	n, err := RoutinePublished(context.Background(), q, previous, current)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 2 || q.recipients() != "hari@example.com,ram@example.com" {
+		t.Errorf("queued %d to %s, want hari and ram once each", n, q.recipients())
+	}
+	if len(q.queued) > 0 {
+		e := q.queued[0]
+		if e.Subject != "Class moved: CT 401 DBMS" {
+			t.Errorf("subject %q", e.Subject)
+		}
+		if !strings.Contains(e.Body, "room R101") || !strings.Contains(e.Body, "room R102") {
+			t.Errorf("body does not show the old and new rooms:\n%s", e.Body)
+		}
+	}
+
+	// the lab is only for sub-group 7, so sita and hari are not told
+	q = newQuerier(lecture)
+	if _, err := RoutinePublished(context.Background(), q, previous, current); err != nil {
+		t.Fatal(err)
+	}
+	if q.recipients() != "gita@example.com,ram@example.com" || q.queued[0].Subject != "Class cancelled: CT 402 DBMS" {
+		t.Errorf("cancelling the lab queued %+v, want a cancellation to gita and ram", q.queued)
+	}
+
+	// ram is told about both changes in one email
+	q = newQuerier(moved)
+	if _, err := RoutinePublished(context.Background(), q, previous, current); err != nil {
+		t.Fatal(err)
+	}
+	for _, e := range q.queued {
+		if e.Recipient == "ram@example.com" && (e.Subject != "2 of your classes have changed" ||
+			!strings.Contains(e.Body, "CT 401") || !strings.Contains(e.Body, "CT 402")) {
+			t.Errorf("ram got %q:\n%s", e.Subject, e.Body)
+		}
+	}
+
+	same := lecture
+	same.TeacherEmail.String = "hari@example.com"
+	q = newQuerier(same, lab)
+	if n, _ := RoutinePublished(context.Background(), q, previous, current); n != 0 {
+		t.Errorf("a version that moved nothing queued %d emails", n)
+	}
+}
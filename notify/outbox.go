@@ -0,0 +1,109 @@
+package notify
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"time"
+
+	db "github.com/nirajan1111/routiney/db/sqlc"
+)
+
+// Outbox is the subset of db.Querier the worker uses.
+type Outbox interface {
+	ClaimDueEmails(ctx context.Context, maxEmails int32) ([]db.EmailOutbox, error)
+	MarkEmailSent(ctx context.Context, id int64) error
+	MarkEmailFailed(ctx context.Context, arg db.MarkEmailFailedParams) error
+}
+
+// Worker sends the emails waiting in the outbox.
+type Worker struct {
+	outbox Outbox
+	sender Sender
+	// Interval is how often Run looks for due emails.
+	Interval time.Duration
+	// Batch is the most emails claimed at a time.
+	Batch int32
+	// MaxAttempts is how many times an email is tried before it is marked
+	// failed. A failed email is only sent again when an admin retries it.
+	MaxAttempts int32
+}
+
+// NewWorker returns a worker checking the outbox every 30 seconds and
+// trying each email up to 8 times, over about two hours.
+func NewWorker(outbox Outbox, sender Sender) *Worker {
+	return &Worker{
+		outbox:      outbox,
+		sender:      sender,
+		Interval:    30 * time.Second,
+		Batch:       20,
+		MaxAttempts: 8,
+	}
+}
+
+// Run sends due emails until ctx is cancelled.
+func (w *Worker) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.Interval)
+	defer ticker.Stop()
+	for {
+		if _, err := w.SendDue(ctx); err != nil && ctx.Err() == nil {
+			log.Printf("notify: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// SendDue sends the emails that are due and returns how many went out. An
+// email that fails is rescheduled rather than reported; the error is only
+// for failures to read or update the outbox.
+func (w *Worker) SendDue(ctx context.Context) (int, error) {
+	sent := 0
+	for {
+		emails, err := w.outbox.ClaimDueEmails(ctx, w.Batch)
+		if err != nil {
+			return sent, err
+		}
+		for _, e := range emails {
+			err := w.sender.Send(ctx, Message{To: e.Recipient, Subject: e.Subject, Body: e.Body})
+			if err == nil {
+				sent++
+				if err := w.outbox.MarkEmailSent(ctx, e.ID); err != nil {
+					return sent, err
+				}
+				continue
+			}
+			arg := db.MarkEmailFailedParams{
+				ID:            e.ID,
+				Status:        db.EmailStatusPending,
+				LastError:     sql.NullString{String: err.Error(), Valid: true},
+				NextAttemptAt: time.Now().Add(retryDelay(e.Attempts + 1)),
+			}
+			if e.Attempts+1 >= w.MaxAttempts {
+				arg.Status = db.EmailStatusFailed
+			}
+			if err := w.outbox.MarkEmailFailed(ctx, arg); err != nil {
+				return sent, err
+			}
+		}
+		if int32(len(emails)) < w.Batch {
+			return sent, nil
+		}
+	}
+}
+
+// retryDelay is the wait after the given number of failed attempts: a
+// minute after the first, doubling up to two hours.
+func retryDelay(attempts int32) time.Duration {
+	d := time.Minute
+	for i := int32(1); i < attempts && d < 2*time.Hour; i++ {
+		d *= 2
+	}
+	if d > 2*time.Hour {
+		d = 2 * time.Hour
+	}
+	return d
+}
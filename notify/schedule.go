@@ -0,0 +1,268 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"text/template"
+
+	db "github.com/nirajan1111/routiney/db/sqlc"
+	"github.com/nirajan1111/routiney/timeslot"
+)
+
+// Querier is the subset of db.Querier RoutinePublished uses. Both *db.Store
+// and the *db.Queries of a transaction satisfy it.
+type Querier interface {
+	ListPublishedSchedules(ctx context.Context, versionID int64) ([]db.PublishedSchedule, error)
+	GetSubject(ctx context.Context, id int64) (db.Subject, error)
+	GetRoom(ctx context.Context, id int32) (db.Room, error)
+	GetStudentSection(ctx context.Context, id int32) (db.StudentSection, error)
+	GetTeacherByEmail(ctx context.Context, email string) (db.Teacher, error)
+	ListSectionMemberEmails(ctx context.Context, groupIds []int64) ([]string, error)
+	ListSubGroupMemberEmails(ctx context.Context, subGroupIds []int64) ([]string, error)
+	ListOptedOutEmails(ctx context.Context, emails []string) ([]string, error)
+	EnqueueEmail(ctx context.Context, arg db.EnqueueEmailParams) (db.EmailOutbox, error)
+}
+
+// class describes one side of a change for the templates.
+type class struct {
+	Slot    string
+	Room    string
+	Teacher string
+	Section string
+}
+
+type change struct {
+	Subject   string
+	Cancelled bool
+	Before    class
+	After     class
+}
+
+// email is everything one person is told about a newly published routine.
+type email struct {
+	Year    int32
+	Term    int16
+	Changes []change
+}
+
+var (
+	subjectTemplate = template.Must(template.New("subject").Parse(
+		`{{if eq (len .Changes) 1}}{{with index .Changes 0}}{{if .Cancelled}}Class cancelled{{else}}Class moved{{end}}: {{.Subject}}{{end}}` +
+			`{{else}}{{len .Changes}} of your classes have changed{{end}}`))
+
+	bodyTemplate = template.Must(template.New("body").Parse(`The routine of {{.Year}}, term {{.Term}} has been published with changes
+to classes you teach or attend.
+{{range .Changes}}
+{{if .Cancelled -}}
+{{.Subject}} for {{.Before.Section}} has been removed from the routine.
+It was on {{.Before.Slot}} in room {{.Before.Room}}, taught by {{.Before.Teacher}}.
+{{- else -}}
+{{.Subject}} for {{.After.Section}} has been changed.
+Was: {{.Before.Slot}}, room {{.Before.Room}}, {{.Before.Teacher}}{{if ne .Before.Section .After.Section}}, {{.Before.Section}}{{end}}
+Now: {{.After.Slot}}, room {{.After.Room}}, {{.After.Teacher}}{{if ne .Before.Section .After.Section}}, {{.After.Section}}{{end}}
+{{- end}}
+{{end}}
+You are receiving this because you teach or attend these classes. To stop
+these emails, turn off routine change notifications in your account.
+`))
+)
+
+// RoutinePublished queues emails about the classes that were moved or
+// cancelled between the previously live version of a term's routine and
+// the one now live, to their teachers and students, before and after the
+// change. Draft edits are never reported: only what was published is.
+//
+// Classes are matched by schedule id, which a restore keeps. A class of a
+// sub-group is reported to that sub-group's students, any other class to
+// every student of its sections. Each person gets one email listing all of
+// their changes, and addresses that opted out are skipped. New classes are
+// not reported. It returns the number of emails queued.
+func RoutinePublished(ctx context.Context, q Querier, previous, current db.RoutineVersion) (int, error) {
+	if previous.ID == current.ID {
+		return 0, nil
+	}
+	before, err := q.ListPublishedSchedules(ctx, previous.ID)
+	if err != nil {
+		return 0, err
+	}
+	after, err := q.ListPublishedSchedules(ctx, current.ID)
+	if err != nil {
+		return 0, err
+	}
+	live := make(map[int64]db.PublishedSchedule, len(after))
+	for _, s := range after {
+		live[s.ScheduleID] = s
+	}
+
+	changes := make(map[string][]change)
+	for _, b := range before {
+		a, kept := live[b.ScheduleID]
+		if kept && !moved(b, a) {
+			continue
+		}
+		c := change{Cancelled: !kept}
+		if subject, err := q.GetSubject(ctx, b.SubjectID.Int64); err == nil {
+			c.Subject = strings.TrimSpace(subject.SubjectCode.String + " " + subject.Name.String)
+		} else if !errors.Is(err, sql.ErrNoRows) {
+			return 0, err
+		}
+		if c.Subject == "" {
+			c.Subject = "A class"
+		}
+		if c.Before, err = describe(ctx, q, b); err != nil {
+			return 0, err
+		}
+		sides := []db.PublishedSchedule{b}
+		if kept {
+			if c.After, err = describe(ctx, q, a); err != nil {
+				return 0, err
+			}
+			sides = append(sides, a)
+		}
+		to, err := recipients(ctx, q, sides)
+		if err != nil {
+			return 0, err
+		}
+		for _, address := range to {
+			changes[address] = append(changes[address], c)
+		}
+	}
+	if len(changes) == 0 {
+		return 0, nil
+	}
+
+	addresses := make([]string, 0, len(changes))
+	for address := range changes {
+		addresses = append(addresses, address)
+	}
+	sort.Strings(addresses)
+	optedOut, err := q.ListOptedOutEmails(ctx, addresses)
+	if err != nil {
+		return 0, err
+	}
+	skip := make(map[string]bool, len(optedOut))
+	for _, address := range optedOut {
+		skip[strings.ToLower(address)] = true
+	}
+
+	queued := 0
+	for _, address := range addresses {
+		if skip[address] {
+			continue
+		}
+		e := email{Year: current.Year, Term: current.Term, Changes: changes[address]}
+		var subject, body bytes.Buffer
+		if err := subjectTemplate.Execute(&subject, e); err != nil {
+			return 0, err
+		}
+		if err := bodyTemplate.Execute(&body, e); err != nil {
+			return 0, err
+		}
+		if _, err := q.EnqueueEmail(ctx, db.EnqueueEmailParams{
+			Recipient: address,
+			Subject:   subject.String(),
+			Body:      body.String(),
+		}); err != nil {
+			return 0, err
+		}
+		queued++
+	}
+	return queued, nil
+}
+
+// moved reports whether anything people act on changed: when and where the
+// class is, who teaches it and who attends.
+func moved(before, after db.PublishedSchedule) bool {
+	return before.DayOfWeek != after.DayOfWeek ||
+		before.StartMinute != after.StartMinute ||
+		before.EndMinute != after.EndMinute ||
+		before.RoomID != after.RoomID ||
+		!strings.EqualFold(before.TeacherEmail.String, after.TeacherEmail.String) ||
+		before.GroupID != after.GroupID ||
+		before.SubGroupID != after.SubGroupID ||
+		fmt.Sprint(before.CombinedGroupIds) != fmt.Sprint(after.CombinedGroupIds)
+}
+
+func describe(ctx context.Context, q Querier, s db.PublishedSchedule) (class, error) {
+	slot := timeslot.TimeSlot{Day: timeslot.Day(s.DayOfWeek), Start: s.StartMinute, End: s.EndMinute}
+	c := class{
+		Slot:    fmt.Sprintf("%s %s-%s", slot.Day, timeslot.FormatClock(slot.Start), timeslot.FormatClock(slot.End)),
+		Teacher: s.TeacherEmail.String,
+	}
+	room, err := q.GetRoom(ctx, int32(s.RoomID.Int64))
+	switch {
+	case err == nil:
+		c.Room = room.RoomCode.String
+	case !errors.Is(err, sql.ErrNoRows):
+		return c, err
+	}
+	teacher, err := q.GetTeacherByEmail(ctx, s.TeacherEmail.String)
+	switch {
+	case err == nil && teacher.Name.Valid:
+		c.Teacher = teacher.Name.String
+	case err != nil && !errors.Is(err, sql.ErrNoRows):
+		return c, err
+	}
+	var names []string
+	for _, id := range append([]int64{s.GroupID.Int64}, s.CombinedGroupIds...) {
+		section, err := q.GetStudentSection(ctx, int32(id))
+		if errors.Is(err, sql.ErrNoRows) {
+			continue
+		}
+		if err != nil {
+			return c, err
+		}
+		names = append(names, section.Name.String)
+	}
+	c.Section = strings.Join(names, ", ")
+	return c, nil
+}
+
+// recipients returns the teachers and students of the sides of a change,
+// lower cased and without duplicates.
+func recipients(ctx context.Context, q Querier, sides []db.PublishedSchedule) ([]string, error) {
+	var groups, subGroups []int64
+	var addresses []string
+	for _, s := range sides {
+		if s.TeacherEmail.Valid {
+			addresses = append(addresses, s.TeacherEmail.String)
+		}
+		// a sub-group class is never combined with other sections
+		if s.SubGroupID.Valid {
+			subGroups = append(subGroups, s.SubGroupID.Int64)
+			continue
+		}
+		groups = append(groups, s.GroupID.Int64)
+		groups = append(groups, s.CombinedGroupIds...)
+	}
+	if len(groups) > 0 {
+		students, err := q.ListSectionMemberEmails(ctx, groups)
+		if err != nil {
+			return nil, err
+		}
+		addresses = append(addresses, students...)
+	}
+	if len(subGroups) > 0 {
+		students, err := q.ListSubGroupMemberEmails(ctx, subGroups)
+		if err != nil {
+			return nil, err
+		}
+		addresses = append(addresses, students...)
+	}
+
+	seen := make(map[string]bool)
+	unique := make([]string, 0, len(addresses))
+	for _, a := range addresses {
+		a = strings.ToLower(strings.TrimSpace(a))
+		if a != "" && !seen[a] {
+			seen[a] = true
+			unique = append(unique, a)
+		}
+	}
+	return unique, nil
+}
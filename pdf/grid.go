@@ -0,0 +1,258 @@
+package pdf
+
+import (
+	"sort"
+	"strconv"
+
+	"github.com/nirajan1111/routiney/timeslot"
+)
+
+// Entry is one class on a routine grid. Lines are printed top to bottom,
+// the first one in bold.
+type Entry struct {
+	Slot  timeslot.TimeSlot
+	Lines []string
+}
+
+// Grid is a weekly routine drawn as days (rows) by periods (columns).
+type Grid struct {
+	Title    string
+	Subtitle string
+	Days     []timeslot.Day
+	Periods  []timeslot.Period
+	Entries  []Entry
+	// Notes are printed under the grid, e.g. the holidays of the year.
+	Notes []string
+}
+
+const (
+	margin      = 36.0
+	titleSize   = 16.0
+	subSize     = 10.0
+	headerSize  = 9.0
+	cellSize    = 8.0
+	lineHeight  = 10.0
+	cellPadding = 4.0
+	dayColumn   = 70.0
+	headerRow   = 32.0
+	maxNotes    = 6
+)
+
+// columns returns the periods to draw: the grid's own periods plus one for
+// every entry that falls outside all of them, ordered by start time.
+func (g Grid) columns() []timeslot.Period {
+	periods := append([]timeslot.Period(nil), g.Periods...)
+	for _, e := range g.Entries {
+		covered := false
+		for _, p := range periods {
+			if e.Slot.Start < p.End && p.Start < e.Slot.End {
+				covered = true
+				break
+			}
+		}
+		if !covered {
+			periods = append(periods, timeslot.Period{Start: e.Slot.Start, End: e.Slot.End})
+		}
+	}
+	sort.SliceStable(periods, func(i, j int) bool { return periods[i].Start < periods[j].Start })
+	return periods
+}
+
+// rows returns the grid's days plus any day an entry falls on.
+func (g Grid) rows() []timeslot.Day {
+	seen := make(map[timeslot.Day]bool)
+	days := append([]timeslot.Day(nil), g.Days...)
+	for _, d := range days {
+		seen[d] = true
+	}
+	for _, e := range g.Entries {
+		if !seen[e.Slot.Day] {
+			seen[e.Slot.Day] = true
+			days = append(days, e.Slot.Day)
+		}
+	}
+	sort.Slice(days, func(i, j int) bool { return days[i] < days[j] })
+	return days
+}
+
+// AddGrid draws g on a new landscape A4 page. An entry spanning several
+// periods is drawn as one merged cell; entries sharing a cell are stacked.
+// Cells that only partly overlap, such as parallel sub-group sessions of
+// different lengths, split the row's height between them.
+func (d *Document) AddGrid(g Grid) {
+	page := d.AddPage(A4Landscape)
+	days, periods := g.rows(), g.columns()
+
+	y := margin + titleSize
+	page.Text(margin, y, HelveticaBold, titleSize, Fit(HelveticaBold, titleSize, page.Size.Width-2*margin, g.Title))
+	if g.Subtitle != "" {
+		y += subSize + 6
+		page.Text(margin, y, Helvetica, subSize, Fit(Helvetica, subSize, page.Size.Width-2*margin, g.Subtitle))
+	}
+	top := y + 14
+
+	notes := g.Notes
+	if len(notes) > maxNotes {
+		notes = append(notes[:maxNotes-1:maxNotes-1], "and "+strconv.Itoa(len(g.Notes)-maxNotes+1)+" more")
+	}
+	width := page.Size.Width - 2*margin
+	height := page.Size.Height - margin - top
+	if len(notes) > 0 {
+		height -= float64(len(notes))*lineHeight + 8
+		for i, note := range notes {
+			page.Text(margin, top+height+8+float64(i+1)*lineHeight-2, Helvetica, cellSize, Fit(Helvetica, cellSize, width, note))
+		}
+	}
+	if len(periods) == 0 || len(days) == 0 {
+		page.Text(margin, top+lineHeight, Helvetica, subSize, "No classes scheduled.")
+		return
+	}
+	colWidth := (width - dayColumn) / float64(len(periods))
+	rowHeight := (height - headerRow) / float64(len(days))
+
+	// header row
+	page.FillRect(margin, top, width, headerRow, 0.85)
+	page.Text(margin+cellPadding, top+headerRow/2+headerSize/2, HelveticaBold, headerSize, "Day")
+	for i, p := range periods {
+		x := margin + dayColumn + float64(i)*colWidth
+		label := timeslot.FormatClock(p.Start) + " - " + timeslot.FormatClock(p.End)
+		if p.Name != "" {
+			page.Text(x+cellPadding, top+headerSize+4, HelveticaBold, headerSize, Fit(HelveticaBold, headerSize, colWidth-2*cellPadding, "Period "+p.Name))
+			page.Text(x+cellPadding, top+2*headerSize+8, Helvetica, headerSize, Fit(Helvetica, headerSize, colWidth-2*cellPadding, label))
+		} else {
+			page.Text(x+cellPadding, top+headerRow/2+headerSize/2, Helvetica, headerSize, Fit(Helvetica, headerSize, colWidth-2*cellPadding, label))
+		}
+	}
+
+	// day labels and entries
+	for r, day := range days {
+		rowTop := top + headerRow + float64(r)*rowHeight
+		page.FillRect(margin, rowTop, dayColumn, rowHeight, 0.93)
+		page.Text(margin+cellPadding, rowTop+rowHeight/2+headerSize/2, HelveticaBold, headerSize, day.Weekday().String())
+
+		occupied := make([]bool, len(periods))
+		for _, c := range layoutRow(g.Entries, day, periods) {
+			for i := c.first; i <= c.last; i++ {
+				occupied[i] = true
+			}
+			x := margin + dayColumn + float64(c.first)*colWidth
+			w := float64(c.last-c.first+1) * colWidth
+			h := rowHeight / float64(c.lanes)
+			y := rowTop + float64(c.lane)*h
+			page.Rect(x, y, w, h, 0.5)
+			drawLines(page, x, y, w, h, c.lines)
+		}
+		for i, used := range occupied {
+			if !used {
+				page.Rect(margin+dayColumn+float64(i)*colWidth, rowTop, colWidth, rowHeight, 0.5)
+			}
+		}
+	}
+
+	page.Rect(margin, top, width, headerRow+rowHeight*float64(len(days)), 1)
+	page.Line(margin+dayColumn, top, margin+dayColumn, top+headerRow+rowHeight*float64(len(days)), 1)
+	page.Line(margin, top+headerRow, margin+width, top+headerRow, 1)
+}
+
+// cell is a merged cell of a row, from column first to last. Cells that
+// overlap are drawn one above the other: the row is split into lanes and
+// the cell takes the lane-th of them.
+type cell struct {
+	first, last int
+	lane, lanes int
+	lines       []string
+}
+
+// layoutRow places the entries falling on day into cells. Entries covering
+// exactly the same columns share a cell; a cell overlapping others gets its
+// own lane among them.
+func layoutRow(entries []Entry, day timeslot.Day, periods []timeslot.Period) []cell {
+	var cells []*cell
+	for _, e := range entries {
+		if e.Slot.Day != day {
+			continue
+		}
+		first, last := -1, -1
+		for i, p := range periods {
+			if e.Slot.Start < p.End && p.Start < e.Slot.End {
+				if first < 0 {
+					first = i
+				}
+				last = i
+			}
+		}
+		var c *cell
+		for _, other := range cells {
+			if other.first == first && other.last == last {
+				c = other
+				break
+			}
+		}
+		if c == nil {
+			c = &cell{first: first, last: last}
+			cells = append(cells, c)
+		} else {
+			c.lines = append(c.lines, "")
+		}
+		c.lines = append(c.lines, e.Lines...)
+	}
+	sort.SliceStable(cells, func(i, j int) bool { return cells[i].first < cells[j].first })
+
+	// cells overlapping each other, directly or through a third, form a
+	// group; each takes the first lane free at its first column and the
+	// group is as tall as its lanes
+	res := make([]cell, 0, len(cells))
+	for i := 0; i < len(cells); {
+		group := []*cell{cells[i]}
+		end := cells[i].last
+		for i++; i < len(cells) && cells[i].first <= end; i++ {
+			group = append(group, cells[i])
+			end = max(end, cells[i].last)
+		}
+		var laneEnds []int
+		for _, c := range group {
+			c.lane = len(laneEnds)
+			for l, last := range laneEnds {
+				if last < c.first {
+					c.lane = l
+					break
+				}
+			}
+			if c.lane == len(laneEnds) {
+				laneEnds = append(laneEnds, c.last)
+			} else {
+				laneEnds[c.lane] = c.last
+			}
+		}
+		for _, c := range group {
+			c.lanes = len(laneEnds)
+			res = append(res, *c)
+		}
+	}
+	return res
+}
+
+// drawLines prints lines inside a cell, bolding the first line of every
+// entry (blank lines separate entries) and dropping what does not fit.
+func drawLines(page *Page, x, y, w, h float64, lines []string) {
+	maxLines := int((h - cellPadding) / lineHeight)
+	bold := true
+	n := 0
+	for _, line := range lines {
+		if n >= maxLines {
+			break
+		}
+		if line == "" {
+			bold = true
+			n++
+			continue
+		}
+		font := Helvetica
+		if bold {
+			font = HelveticaBold
+			bold = false
+		}
+		page.Text(x+cellPadding, y+cellPadding+float64(n+1)*lineHeight-2, font, cellSize, Fit(font, cellSize, w-2*cellPadding, line))
+		n++
+	}
+}
@@ -0,0 +1,54 @@
+package pdf
+
+import "strings"
+
+// Glyph widths of printable ASCII (32 to 126) in thousandths of the font
+// size, from the Adobe font metrics of the standard fonts.
+var widths = [...][95]int{
+	Helvetica: {
+		278, 278, 355, 556, 556, 889, 667, 191, 333, 333, 389, 584, 278, 333, 278, 278,
+		556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 278, 278, 584, 584, 584, 556,
+		1015, 667, 667, 722, 722, 667, 611, 778, 722, 278, 500, 667, 556, 833, 722, 778,
+		667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 278, 278, 278, 469, 556,
+		333, 556, 556, 500, 556, 556, 278, 556, 556, 222, 222, 500, 222, 833, 556, 556,
+		556, 556, 333, 500, 278, 556, 500, 722, 500, 500, 500, 334, 260, 334, 584,
+	},
+	HelveticaBold: {
+		278, 333, 474, 556, 556, 889, 722, 238, 333, 333, 389, 584, 278, 333, 278, 278,
+		556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 333, 333, 584, 584, 584, 611,
+		975, 722, 722, 722, 722, 667, 611, 778, 722, 278, 556, 722, 611, 833, 722, 778,
+		667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 333, 278, 333, 584, 556,
+		333, 556, 611, 556, 611, 556, 333, 611, 611, 278, 278, 556, 278, 889, 611, 611,
+		611, 611, 389, 556, 333, 611, 556, 778, 556, 556, 500, 389, 280, 389, 584,
+	},
+}
+
+// TextWidth is the width of s in points when set in font at size.
+func TextWidth(font Font, size float64, s string) float64 {
+	total := 0
+	for _, r := range s {
+		if r >= 32 && r < 127 {
+			total += widths[font][r-32]
+		} else {
+			// Latin-1 letters are close enough to an average glyph
+			total += 556
+		}
+	}
+	return float64(total) * size / 1000
+}
+
+// Fit shortens s with an ellipsis until it is at most width points wide.
+func Fit(font Font, size, width float64, s string) string {
+	if TextWidth(font, size, s) <= width {
+		return s
+	}
+	runes := []rune(strings.TrimSpace(s))
+	for len(runes) > 0 {
+		runes = runes[:len(runes)-1]
+		cut := strings.TrimSpace(string(runes)) + "..."
+		if TextWidth(font, size, cut) <= width {
+			return cut
+		}
+	}
+	return ""
+}
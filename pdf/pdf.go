@@ -0,0 +1,156 @@
+// Package pdf is a small PDF writer for printable routines. It draws text
+// in the standard Helvetica fonts, lines and filled rectangles, which is all
+// a timetable needs, and has no dependencies outside the standard library.
+package pdf
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Page sizes in points (1/72 inch).
+var (
+	A4          = Size{595, 842}
+	A4Landscape = Size{842, 595}
+)
+
+// Size is a page size in points.
+type Size struct {
+	Width, Height float64
+}
+
+// Font is one of the standard fonts every PDF reader has built in.
+type Font int
+
+const (
+	Helvetica Font = iota
+	HelveticaBold
+)
+
+var fontNames = [...]string{"Helvetica", "Helvetica-Bold"}
+
+// Document is a PDF being built page by page.
+type Document struct {
+	pages []*Page
+}
+
+// New creates an empty document.
+func New() *Document {
+	return &Document{}
+}
+
+// AddPage appends a blank page and returns it for drawing.
+func (d *Document) AddPage(size Size) *Page {
+	p := &Page{Size: size}
+	d.pages = append(d.pages, p)
+	return p
+}
+
+// Page is a single page. The origin is the top left corner and y grows
+// downwards, unlike PDF's own coordinates.
+type Page struct {
+	Size    Size
+	content bytes.Buffer
+}
+
+func (p *Page) y(y float64) float64 {
+	return p.Size.Height - y
+}
+
+// Text draws s with its baseline at (x, y).
+func (p *Page) Text(x, y float64, font Font, size float64, s string) {
+	fmt.Fprintf(&p.content, "BT /F%d %.2f Tf %.2f %.2f Td (%s) Tj ET\n", font+1, size, x, p.y(y), escape(s))
+}
+
+// Line strokes a line from (x1, y1) to (x2, y2).
+func (p *Page) Line(x1, y1, x2, y2, width float64) {
+	fmt.Fprintf(&p.content, "%.2f w %.2f %.2f m %.2f %.2f l S\n", width, x1, p.y(y1), x2, p.y(y2))
+}
+
+// Rect strokes a rectangle whose top left corner is (x, y).
+func (p *Page) Rect(x, y, w, h, width float64) {
+	fmt.Fprintf(&p.content, "%.2f w %.2f %.2f %.2f %.2f re S\n", width, x, p.y(y+h), w, h)
+}
+
+// FillRect fills a rectangle with a shade of gray, 0 being black and 1
+// white.
+func (p *Page) FillRect(x, y, w, h, gray float64) {
+	fmt.Fprintf(&p.content, "q %.3f g %.2f %.2f %.2f %.2f re f Q\n", gray, x, p.y(y+h), w, h)
+}
+
+// WriteTo writes the finished document to w.
+func (d *Document) WriteTo(w io.Writer) (int64, error) {
+	var buf bytes.Buffer
+	var offsets []int
+
+	object := func(body string) {
+		offsets = append(offsets, buf.Len())
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	buf.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+
+	// objects 1 and 2 are the catalog and the page tree, then one per font,
+	// then a page and its content stream for every page
+	pages := d.pages
+	if len(pages) == 0 {
+		pages = []*Page{{Size: A4}}
+	}
+	firstPage := 3 + len(fontNames)
+	kids := make([]string, len(pages))
+	for i := range pages {
+		kids[i] = fmt.Sprintf("%d 0 R", firstPage+2*i)
+	}
+
+	object("<< /Type /Catalog /Pages 2 0 R >>")
+	object(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(pages)))
+	var fonts strings.Builder
+	for i, name := range fontNames {
+		object(fmt.Sprintf("<< /Type /Font /Subtype /Type1 /BaseFont /%s /Encoding /WinAnsiEncoding >>", name))
+		fmt.Fprintf(&fonts, "/F%d %d 0 R ", i+1, 3+i)
+	}
+
+	for i, p := range pages {
+		object(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %.2f %.2f] /Resources << /Font << %s>> >> /Contents %d 0 R >>",
+			p.Size.Width, p.Size.Height, fonts.String(), firstPage+2*i+1))
+
+		var stream bytes.Buffer
+		zw := zlib.NewWriter(&stream)
+		zw.Write(p.content.Bytes())
+		zw.Close()
+		object(fmt.Sprintf("<< /Length %d /Filter /FlateDecode >>\nstream\n%s\nendstream", stream.Len(), stream.Bytes()))
+	}
+
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, off := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", off)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+
+	n, err := w.Write(buf.Bytes())
+	return int64(n), err
+}
+
+// escape encodes s for a PDF string literal in WinAnsiEncoding. Characters
+// outside Latin-1 have no glyph in the standard fonts and become '?'.
+func escape(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r == '(' || r == ')' || r == '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r >= 32 && r < 127:
+			b.WriteRune(r)
+		case r >= 160 && r <= 255:
+			fmt.Fprintf(&b, "\\%03o", r)
+		default:
+			b.WriteByte('?')
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,105 @@
+package pdf
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strconv"
+	"testing"
+
+	"github.com/nirajan1111/routiney/timeslot"
+)
+
+func TestWriteToXref(t *testing.T) {
+	doc := New()
+	slot, _ := timeslot.Parse("SUN-16:15-19:35")
+	doc.AddGrid(Grid{
+		Title:   "BCT 2078 A",
+		Days:    timeslot.DefaultDays,
+		Periods: timeslot.DefaultPeriods,
+		Entries: []Entry{{Slot: slot, Lines: []string{"CT401", "Computer Graphics (Lab)"}}},
+	})
+	doc.AddGrid(Grid{Title: "Empty"})
+
+	var buf bytes.Buffer
+	if _, err := doc.WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.Bytes()
+	if !bytes.HasPrefix(out, []byte("%PDF-1.4")) || !bytes.HasSuffix(out, []byte("%%EOF\n")) {
+		t.Fatal("missing PDF header or trailer")
+	}
+	if !bytes.Contains(out, []byte("/Count 2")) {
+		t.Error("expected two pages")
+	}
+
+	// every xref entry must point at the start of its object
+	m := regexp.MustCompile(`startxref\n(\d+)`).FindSubmatch(out)
+	start, _ := strconv.Atoi(string(m[1]))
+	entries := regexp.MustCompile(`(\d{10}) 00000 n `).FindAllSubmatch(out[start:], -1)
+	if len(entries) == 0 {
+		t.Fatal("empty xref table")
+	}
+	for i, e := range entries {
+		off, _ := strconv.Atoi(string(e[1]))
+		want := fmt.Sprintf("%d 0 obj", i+1)
+		if !bytes.HasPrefix(out[off:], []byte(want)) {
+			t.Errorf("xref entry %d points at %q", i+1, out[off:off+10])
+		}
+	}
+}
+
+func TestFit(t *testing.T) {
+	if got := Fit(Helvetica, 10, 1000, "short"); got != "short" {
+		t.Errorf("Fit shortened text that fits: %q", got)
+	}
+	got := Fit(Helvetica, 10, 60, "Computer Graphics")
+	if TextWidth(Helvetica, 10, got) > 60 || got[len(got)-3:] != "..." {
+		t.Errorf("Fit = %q", got)
+	}
+}
+
+func TestOverlappingSpans(t *testing.T) {
+	periods := []timeslot.Period{
+		{Name: "1", Start: 10 * 60, End: 11 * 60},
+		{Name: "2", Start: 11 * 60, End: 12 * 60},
+		{Name: "3", Start: 12 * 60, End: 13 * 60},
+	}
+	slot := func(s string) timeslot.TimeSlot {
+		ts, err := timeslot.Parse(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return ts
+	}
+	// two sub-groups in labs of different lengths that share period 2
+	entries := []Entry{
+		{Slot: slot("SUN-10:00-12:00"), Lines: []string{"Lab A"}},
+		{Slot: slot("SUN-11:00-13:00"), Lines: []string{"Lab B"}},
+		{Slot: slot("MON-10:00-11:00"), Lines: []string{"Theory"}},
+	}
+
+	cells := layoutRow(entries, timeslot.Sunday, periods)
+	if len(cells) != 2 || cells[0].lanes != 2 || cells[1].lanes != 2 || cells[0].lane == cells[1].lane {
+		t.Fatalf("overlapping cells %+v, want one lane each out of two", cells)
+	}
+	if cells := layoutRow(entries, timeslot.Monday, periods); len(cells) != 1 || cells[0].lanes != 1 {
+		t.Errorf("lone cell %+v, want the whole row", cells)
+	}
+
+	doc := New()
+	doc.AddGrid(Grid{Title: "BCT 2078 A", Days: []timeslot.Day{timeslot.Sunday, timeslot.Monday}, Periods: periods, Entries: entries})
+	content := doc.pages[0].content.String()
+	baseline := func(text string) float64 {
+		m := regexp.MustCompile(`([\d.]+) Td \(` + text + `\) Tj`).FindStringSubmatch(content)
+		if m == nil {
+			t.Fatalf("%q not drawn", text)
+		}
+		y, _ := strconv.ParseFloat(m[1], 64)
+		return y
+	}
+	a, b := baseline("Lab A"), baseline("Lab B")
+	if a-b < lineHeight {
+		t.Errorf("Lab A at y=%.2f and Lab B at y=%.2f are drawn over each other", a, b)
+	}
+}
@@ -0,0 +1,300 @@
+// Package rollover copies a term's routine into another term, so a new
+// academic year or semester starts from the last one instead of from scratch.
+//
+// Rows can be narrowed to a department, program or set of sections, and
+// teachers, rooms and sections can be swapped on the way. Every copy is
+// checked by the validation package against the target term; rows that
+// clash are skipped and reported while the rest are copied. The whole run is
+// one transaction, rolled back in dry-run mode.
+package rollover
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	db "github.com/nirajan1111/routiney/db/sqlc"
+	"github.com/nirajan1111/routiney/timeslot"
+	"github.com/nirajan1111/routiney/validation"
+)
+
+// ErrInvalid is returned for options that cannot be carried out, such as a
+// remap onto a room that does not exist or a target term that has not been
+// set up.
+var ErrInvalid = errors.New("invalid rollover")
+
+var errRollback = errors.New("rollback")
+
+// Options describes a rollover.
+type Options struct {
+	FromYear int32
+	ToYear   int32
+	// FromTerm and ToTerm default to the first term of the year.
+	FromTerm int16
+	ToTerm   int16
+
+	// Filters; empty means no filtering. A row must match all of them.
+	Department string
+	Program    string
+	GroupIDs   []int64
+
+	// Remaps applied to the copied rows.
+	TeacherMap map[string]string
+	RoomMap    map[int64]int64
+	GroupMap   map[int64]int64
+
+	DryRun bool
+}
+
+// Skipped is a row that could not be copied.
+type Skipped struct {
+	ScheduleID   int64                 `json:"schedule_id"`
+	GroupID      int64                 `json:"group_id"`
+	RoomID       int64                 `json:"room_id"`
+	TeacherEmail string                `json:"teacher_email"`
+	TimeSlot     string                `json:"time_slot"`
+	Conflicts    []validation.Conflict `json:"conflicts"`
+	// Reason is set when the row was skipped for something other than a
+	// conflict.
+	Reason string `json:"reason,omitempty"`
+}
+
+// Report is the outcome of a rollover.
+type Report struct {
+	FromYear  int32     `json:"from_year"`
+	FromTerm  int16     `json:"from_term"`
+	ToYear    int32     `json:"to_year"`
+	ToTerm    int16     `json:"to_term"`
+	DryRun    bool      `json:"dry_run"`
+	Matched   int       `json:"matched"`
+	Copied    int       `json:"copied"`
+	Committed bool      `json:"committed"`
+	Skipped   []Skipped `json:"skipped"`
+}
+
+// Run copies the schedules selected by opts.
+func Run(ctx context.Context, store *db.Store, opts Options) (Report, error) {
+	if opts.FromTerm == 0 {
+		opts.FromTerm = 1
+	}
+	if opts.ToTerm == 0 {
+		opts.ToTerm = 1
+	}
+	report := Report{
+		FromYear: opts.FromYear,
+		FromTerm: opts.FromTerm,
+		ToYear:   opts.ToYear,
+		ToTerm:   opts.ToTerm,
+		DryRun:   opts.DryRun,
+		Skipped:  []Skipped{},
+	}
+	if opts.FromYear == opts.ToYear && opts.FromTerm == opts.ToTerm {
+		return report, fmt.Errorf("%w: source and target are both term %d of %d", ErrInvalid, opts.FromTerm, opts.FromYear)
+	}
+
+	err := store.ExecTx(ctx, func(q *db.Queries) error {
+		if err := checkTargets(ctx, q, opts); err != nil {
+			return err
+		}
+		filter, err := newFilter(ctx, q, opts)
+		if err != nil {
+			return err
+		}
+
+		schedules, err := q.ListSchedulesByTerm(ctx, db.ListSchedulesByTermParams{Year: opts.FromYear, Term: opts.FromTerm})
+		if err != nil {
+			return err
+		}
+		v := validation.New(q)
+		for _, s := range schedules {
+			if !filter.match(s) {
+				continue
+			}
+			report.Matched++
+
+			arg := remap(s, opts)
+			if arg.SubGroupID.Valid && arg.GroupID != s.GroupID {
+				// the sub-group belongs to the old section; use the
+				// new section's sub-group of the same name
+				subGroup, err := q.GetSubGroup(ctx, arg.SubGroupID.Int64)
+				if err != nil {
+					return err
+				}
+				target, err := q.GetSubGroupByName(ctx, db.GetSubGroupByNameParams{GroupID: int32(arg.GroupID.Int64), Name: subGroup.Name})
+				if errors.Is(err, sql.ErrNoRows) {
+					report.Skipped = append(report.Skipped, Skipped{
+						ScheduleID:   s.ID,
+						GroupID:      arg.GroupID.Int64,
+						RoomID:       arg.RoomID.Int64,
+						TeacherEmail: arg.TeacherEmail.String,
+						TimeSlot:     arg.TimeSlot.String,
+						Conflicts:    []validation.Conflict{},
+						Reason:       fmt.Sprintf("section %d has no sub-group %s", arg.GroupID.Int64, subGroup.Name),
+					})
+					continue
+				}
+				if err != nil {
+					return err
+				}
+				arg.SubGroupID = sql.NullInt64{Int64: target.ID, Valid: true}
+			}
+			result, err := v.Validate(ctx, validation.FromCreateParams(arg))
+			if err != nil {
+				return err
+			}
+			if len(result.Conflicts) > 0 {
+				report.Skipped = append(report.Skipped, Skipped{
+					ScheduleID:   s.ID,
+					GroupID:      arg.GroupID.Int64,
+					RoomID:       arg.RoomID.Int64,
+					TeacherEmail: arg.TeacherEmail.String,
+					TimeSlot:     arg.TimeSlot.String,
+					Conflicts:    result.Conflicts,
+				})
+				continue
+			}
+			if _, err := q.CreateSchedule(ctx, arg); err != nil {
+				return fmt.Errorf("copying schedule %d: %w", s.ID, err)
+			}
+			report.Copied++
+		}
+		if opts.DryRun {
+			return errRollback
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, errRollback) {
+		return report, err
+	}
+	report.Committed = err == nil
+	return report, nil
+}
+
+// checkTargets makes sure the target term and every remap point at an
+// existing record, so a typo fails the run up front instead of aborting it
+// half way.
+func checkTargets(ctx context.Context, q *db.Queries, opts Options) error {
+	if _, err := q.GetAcademicTerm(ctx, db.GetAcademicTermParams{Year: opts.ToYear, Term: opts.ToTerm}); errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("%w: term %d of %d has not been set up", ErrInvalid, opts.ToTerm, opts.ToYear)
+	} else if err != nil {
+		return err
+	}
+	var missing []string
+	for _, email := range opts.TeacherMap {
+		if _, err := q.GetTeacherByEmail(ctx, email); errors.Is(err, sql.ErrNoRows) {
+			missing = append(missing, "teacher "+email)
+		} else if err != nil {
+			return err
+		}
+	}
+	for _, id := range opts.RoomMap {
+		if _, err := q.GetRoom(ctx, int32(id)); errors.Is(err, sql.ErrNoRows) {
+			missing = append(missing, fmt.Sprintf("room %d", id))
+		} else if err != nil {
+			return err
+		}
+	}
+	for _, id := range opts.GroupMap {
+		if _, err := q.GetStudentSection(ctx, int32(id)); errors.Is(err, sql.ErrNoRows) {
+			missing = append(missing, fmt.Sprintf("student section %d", id))
+		} else if err != nil {
+			return err
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("%w: remap target not found: %s", ErrInvalid, strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+type filter struct {
+	groups map[int64]bool // nil when not filtering by section
+}
+
+// newFilter resolves the department, program and section filters to the
+// set of source group IDs they allow.
+func newFilter(ctx context.Context, q *db.Queries, opts Options) (filter, error) {
+	if opts.Department == "" && opts.Program == "" && len(opts.GroupIDs) == 0 {
+		return filter{}, nil
+	}
+	var wanted map[int64]bool
+	if len(opts.GroupIDs) > 0 {
+		wanted = make(map[int64]bool, len(opts.GroupIDs))
+		for _, id := range opts.GroupIDs {
+			wanted[id] = true
+		}
+	}
+
+	sections, err := q.ListAllStudentSections(ctx)
+	if err != nil {
+		return filter{}, err
+	}
+	groups := make(map[int64]bool)
+	for _, section := range sections {
+		id := int64(section.ID)
+		if wanted != nil && !wanted[id] {
+			continue
+		}
+		if opts.Department != "" && !strings.EqualFold(section.Department.String, opts.Department) {
+			continue
+		}
+		if opts.Program != "" && !strings.EqualFold(section.Program.String, opts.Program) {
+			continue
+		}
+		groups[id] = true
+	}
+	return filter{groups: groups}, nil
+}
+
+// match reports whether s is for one of the filtered sections. A combined
+// class matches when any of its sections does.
+func (f filter) match(s db.Schedule) bool {
+	if f.groups == nil || f.groups[s.GroupID.Int64] {
+		return true
+	}
+	for _, id := range s.CombinedGroupIds {
+		if f.groups[id] {
+			return true
+		}
+	}
+	return false
+}
+
+// remap builds the target term's copy of s.
+func remap(s db.Schedule, opts Options) db.CreateScheduleParams {
+	arg := db.CreateScheduleParams{
+		GroupID:      s.GroupID,
+		RoomID:       s.RoomID,
+		SubjectID:    s.SubjectID,
+		TeacherEmail: s.TeacherEmail,
+		TimeSlot:     s.TimeSlot,
+		Year:         opts.ToYear,
+		Term:         opts.ToTerm,
+		DayOfWeek:    s.DayOfWeek,
+		StartMinute:  s.StartMinute,
+		EndMinute:    s.EndMinute,
+		SessionType:  s.SessionType,
+		SubGroupID:   s.SubGroupID,
+	}
+	if to, ok := opts.TeacherMap[s.TeacherEmail.String]; ok && s.TeacherEmail.Valid {
+		arg.TeacherEmail = sql.NullString{String: to, Valid: true}
+	}
+	if to, ok := opts.RoomMap[s.RoomID.Int64]; ok && s.RoomID.Valid {
+		arg.RoomID = sql.NullInt64{Int64: to, Valid: true}
+	}
+	if to, ok := opts.GroupMap[s.GroupID.Int64]; ok && s.GroupID.Valid {
+		arg.GroupID = sql.NullInt64{Int64: to, Valid: true}
+	}
+	for _, id := range s.CombinedGroupIds {
+		if to, ok := opts.GroupMap[id]; ok {
+			id = to
+		}
+		arg.CombinedGroupIds = append(arg.CombinedGroupIds, id)
+	}
+	// normalize legacy spellings while we are at it
+	slot := timeslot.TimeSlot{Day: timeslot.Day(s.DayOfWeek), Start: s.StartMinute, End: s.EndMinute}
+	arg.TimeSlot = sql.NullString{String: slot.String(), Valid: true}
+	return arg
+}
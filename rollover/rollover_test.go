@@ -0,0 +1,38 @@
+package rollover
+
+import (
+	"database/sql"
+	"testing"
+
+	db "github.com/nirajan1111/routiney/db/sqlc"
+)
+
+func TestRemap(t *testing.T) {
+	s := db.Schedule{
+		ID:           1,
+		GroupID:      sql.NullInt64{Int64: 3, Valid: true},
+		RoomID:       sql.NullInt64{Int64: 10, Valid: true},
+		SubjectID:    sql.NullInt64{Int64: 5, Valid: true},
+		TeacherEmail: sql.NullString{String: "old@example.com", Valid: true},
+		TimeSlot:     sql.NullString{String: "sun-16:15-17:55", Valid: true},
+		Year:         2081,
+		DayOfWeek:    0,
+		StartMinute:  16*60 + 15,
+		EndMinute:    17*60 + 55,
+	}
+	arg := remap(s, Options{
+		ToYear:     2082,
+		TeacherMap: map[string]string{"old@example.com": "new@example.com"},
+		RoomMap:    map[int64]int64{10: 12},
+	})
+
+	if arg.Year != 2082 {
+		t.Errorf("year = %d", arg.Year)
+	}
+	if arg.TeacherEmail.String != "new@example.com" || arg.RoomID.Int64 != 12 || arg.GroupID.Int64 != 3 {
+		t.Errorf("remap = %+v", arg)
+	}
+	if arg.TimeSlot.String != "SUN-16:15-17:55" {
+		t.Errorf("time slot = %q", arg.TimeSlot.String)
+	}
+}
@@ -0,0 +1,601 @@
+// Package scheduler builds weekly timetables from teaching requirements.
+//
+// Hard constraints (a room, teacher or group is never booked twice in
+// overlapping slots) are never violated: a lesson that cannot be placed is
+// reported as unplaced instead. Soft constraints are scored, lower is
+// better, and guide the search towards compact, balanced routines.
+package scheduler
+
+import (
+	"sort"
+	"strconv"
+
+	"github.com/nirajan1111/routiney/timeslot"
+)
+
+// Room is a room lessons may be placed in. Capacity is its seats, zero
+// when unknown. Type and Features are matched against a requirement's.
+type Room struct {
+	ID         int64
+	Code       string
+	Department string
+	Capacity   int
+	Type       string
+	Features   []string
+}
+
+// Requirement asks for Periods weekly lessons of a subject for a group.
+// One of Teachers is picked and then kept for every lesson of the
+// requirement. Size is the group's headcount, zero when unknown; lessons
+// are only placed in rooms that seat it. RoomType, when set, and Features
+// restrict the rooms to those of that type with every feature.
+type Requirement struct {
+	GroupID    int64
+	Department string
+	SubjectID  int64
+	Periods    int
+	Teachers   []string
+	Size       int
+	RoomType   string
+	Features   []string
+}
+
+// Booking is an already scheduled lesson the generator has to work around.
+type Booking struct {
+	GroupID int64
+	// CombinedGroupIDs are the other sections attending a combined class.
+	CombinedGroupIDs []int64
+	RoomID           int64
+	TeacherEmail     string
+	Slot             timeslot.TimeSlot
+}
+
+// Availability is a teacher's standing weekly availability. Unavailable
+// slots are a hard constraint; preferred and disliked ones are scored.
+type Availability struct {
+	Unavailable []timeslot.TimeSlot
+	Preferred   []timeslot.TimeSlot
+	Disliked    []timeslot.TimeSlot
+}
+
+// Problem is the input of Solve.
+type Problem struct {
+	Slots        []timeslot.TimeSlot
+	Rooms        []Room
+	Requirements []Requirement
+	Existing     []Booking
+	// Availability is keyed by teacher email.
+	Availability map[string]Availability
+	// MaxPeriods caps the weekly periods of a teacher, Existing ones
+	// included; an existing booking counts once for every slot it covers.
+	// Teachers missing from it are not capped.
+	MaxPeriods map[string]int
+}
+
+// Assignment is a single placed lesson.
+type Assignment struct {
+	GroupID      int64
+	SubjectID    int64
+	RoomID       int64
+	TeacherEmail string
+	Slot         timeslot.TimeSlot
+}
+
+// Unplaced records how many lessons of a requirement could not be placed.
+type Unplaced struct {
+	Requirement Requirement
+	Missing     int
+}
+
+// Score breaks down the soft constraint penalties of a solution.
+type Score struct {
+	// Gaps counts free periods between the first and last lesson of a day,
+	// for groups and teachers.
+	Gaps int
+	// Imbalance sums, per group, the difference between its busiest and
+	// quietest working day.
+	Imbalance int
+	// Repeats counts extra lessons of the same subject on the same day.
+	Repeats int
+	Total   int
+}
+
+// Solution is the output of Solve.
+type Solution struct {
+	Assignments []Assignment
+	Unplaced    []Unplaced
+	Score       Score
+	// Complete is true when every requested lesson was placed.
+	Complete bool
+}
+
+// Options tune the search.
+type Options struct {
+	// MaxSteps bounds the backtracking search before falling back to a
+	// greedy placement. Zero means DefaultMaxSteps.
+	MaxSteps int
+	// Branching is how many of the best candidates are tried per lesson
+	// while backtracking. Zero means DefaultBranching.
+	Branching int
+}
+
+const (
+	DefaultMaxSteps  = 5000
+	DefaultBranching = 4
+
+	gapWeight        = 3
+	imbalanceWeight  = 2
+	repeatWeight     = 4
+	preferenceWeight = 2
+)
+
+type lesson struct {
+	req   int
+	group int64
+	subj  int64
+}
+
+type candidate struct {
+	slot    int
+	teacher string
+	room    int64
+	cost    int
+}
+
+type solver struct {
+	problem Problem
+	opts    Options
+	lessons []lesson
+
+	// slotIndex is the position of each grid slot within its day, used to
+	// count gaps.
+	slotIndex []int
+	dayOf     []timeslot.Day
+
+	groupBusy   map[int64][]timeslot.TimeSlot
+	teacherBusy map[string][]timeslot.TimeSlot
+	roomBusy    map[int64][]timeslot.TimeSlot
+	// periods each teacher already teaches in Existing bookings, which
+	// may span several grid slots
+	teacherPeriods map[string]int
+
+	// placed lesson slots per group/teacher, as grid slot indexes
+	groupSlots   map[int64][]int
+	teacherSlots map[string][]int
+	subjectDays  map[[2]int64]map[timeslot.Day]int
+	groupRooms   map[int64]map[int64]int
+	reqTeacher   map[int]string
+
+	assigned []int
+	chosen   []candidate
+	steps    int
+}
+
+// Solve places every lesson of the problem's requirements in its slots.
+func Solve(problem Problem, opts Options) Solution {
+	if opts.MaxSteps <= 0 {
+		opts.MaxSteps = DefaultMaxSteps
+	}
+	if opts.Branching <= 0 {
+		opts.Branching = DefaultBranching
+	}
+	s := newSolver(problem, opts)
+
+	if !s.backtrack(0) {
+		s = newSolver(problem, opts)
+		s.greedy()
+	}
+	return s.solution()
+}
+
+func newSolver(problem Problem, opts Options) *solver {
+	s := &solver{
+		problem:        problem,
+		opts:           opts,
+		groupBusy:      map[int64][]timeslot.TimeSlot{},
+		teacherBusy:    map[string][]timeslot.TimeSlot{},
+		roomBusy:       map[int64][]timeslot.TimeSlot{},
+		teacherPeriods: map[string]int{},
+		groupSlots:     map[int64][]int{},
+		teacherSlots:   map[string][]int{},
+		subjectDays:    map[[2]int64]map[timeslot.Day]int{},
+		groupRooms:     map[int64]map[int64]int{},
+		reqTeacher:     map[int]string{},
+	}
+
+	s.slotIndex = make([]int, len(problem.Slots))
+	s.dayOf = make([]timeslot.Day, len(problem.Slots))
+	order := make([]int, len(problem.Slots))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		x, y := problem.Slots[order[a]], problem.Slots[order[b]]
+		if x.Day != y.Day {
+			return x.Day < y.Day
+		}
+		return x.Start < y.Start
+	})
+	perDay := map[timeslot.Day]int{}
+	for _, i := range order {
+		day := problem.Slots[i].Day
+		s.slotIndex[i] = perDay[day]
+		s.dayOf[i] = day
+		perDay[day]++
+	}
+
+	for _, b := range problem.Existing {
+		s.groupBusy[b.GroupID] = append(s.groupBusy[b.GroupID], b.Slot)
+		for _, id := range b.CombinedGroupIDs {
+			s.groupBusy[id] = append(s.groupBusy[id], b.Slot)
+		}
+		s.teacherBusy[b.TeacherEmail] = append(s.teacherBusy[b.TeacherEmail], b.Slot)
+		s.teacherPeriods[b.TeacherEmail] += s.periods(b.Slot)
+		s.roomBusy[b.RoomID] = append(s.roomBusy[b.RoomID], b.Slot)
+	}
+
+	for i, req := range problem.Requirements {
+		for n := 0; n < req.Periods; n++ {
+			s.lessons = append(s.lessons, lesson{req: i, group: req.GroupID, subj: req.SubjectID})
+		}
+	}
+	// Most constrained lessons first: fewest teachers, then the groups
+	// with the heaviest load.
+	load := map[int64]int{}
+	for _, l := range s.lessons {
+		load[l.group]++
+	}
+	sort.SliceStable(s.lessons, func(a, b int) bool {
+		ra, rb := problem.Requirements[s.lessons[a].req], problem.Requirements[s.lessons[b].req]
+		if len(ra.Teachers) != len(rb.Teachers) {
+			return len(ra.Teachers) < len(rb.Teachers)
+		}
+		if load[ra.GroupID] != load[rb.GroupID] {
+			return load[ra.GroupID] > load[rb.GroupID]
+		}
+		return s.lessons[a].req < s.lessons[b].req
+	})
+	s.assigned = make([]int, len(s.lessons))
+	s.chosen = make([]candidate, len(s.lessons))
+	for i := range s.assigned {
+		s.assigned[i] = -1
+	}
+	return s
+}
+
+// periods is how many grid slots a booking covers, at least one for a
+// booking that falls outside the grid.
+func (s *solver) periods(slot timeslot.TimeSlot) int {
+	n := 0
+	for _, g := range s.problem.Slots {
+		if g.Overlaps(slot) {
+			n++
+		}
+	}
+	return max(n, 1)
+}
+
+func overlapsAny(busy []timeslot.TimeSlot, slot timeslot.TimeSlot) bool {
+	for _, b := range busy {
+		if b.Overlaps(slot) {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *solver) candidates(li int) []candidate {
+	l := s.lessons[li]
+	req := s.problem.Requirements[l.req]
+	teachers := req.Teachers
+	if t, ok := s.reqTeacher[l.req]; ok {
+		teachers = []string{t}
+	}
+
+	var out []candidate
+	for si, slot := range s.problem.Slots {
+		if overlapsAny(s.groupBusy[l.group], slot) {
+			continue
+		}
+		for _, teacher := range teachers {
+			if overlapsAny(s.teacherBusy[teacher], slot) || overlapsAny(s.problem.Availability[teacher].Unavailable, slot) {
+				continue
+			}
+			if max, ok := s.problem.MaxPeriods[teacher]; ok && s.teacherPeriods[teacher]+len(s.teacherSlots[teacher]) >= max {
+				continue
+			}
+			for _, room := range s.problem.Rooms {
+				if overlapsAny(s.roomBusy[room.ID], slot) {
+					continue
+				}
+				if room.Capacity > 0 && req.Size > room.Capacity {
+					continue
+				}
+				if !suits(room, req) {
+					continue
+				}
+				c := candidate{slot: si, teacher: teacher, room: room.ID}
+				c.cost = s.cost(l, req, c, room)
+				out = append(out, c)
+			}
+		}
+	}
+	sort.SliceStable(out, func(a, b int) bool { return out[a].cost < out[b].cost })
+	return out
+}
+
+// suits reports whether room is of the type and has the features req asks
+// for.
+func suits(room Room, req Requirement) bool {
+	if req.RoomType != "" && room.Type != req.RoomType {
+		return false
+	}
+	for _, want := range req.Features {
+		found := false
+		for _, f := range room.Features {
+			if f == want {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// cost estimates how much placing lesson l at c worsens the soft score.
+func (s *solver) cost(l lesson, req Requirement, c candidate, room Room) int {
+	day := s.dayOf[c.slot]
+	cost := 0
+
+	cost += gapWeight * (gapsWith(s.groupSlots[l.group], s.slotIndex, s.dayOf, c.slot, day) - gapsOn(s.groupSlots[l.group], s.slotIndex, s.dayOf, day))
+	cost += gapWeight * (gapsWith(s.teacherSlots[c.teacher], s.slotIndex, s.dayOf, c.slot, day) - gapsOn(s.teacherSlots[c.teacher], s.slotIndex, s.dayOf, day))
+
+	// spread a group's lessons over the week
+	for _, si := range s.groupSlots[l.group] {
+		if s.dayOf[si] == day {
+			cost += imbalanceWeight
+		}
+	}
+	cost += repeatWeight * s.subjectDays[[2]int64{l.group, l.subj}][day]
+
+	// keep a group in its usual rooms, and in its own department
+	if rooms := s.groupRooms[l.group]; len(rooms) > 0 && rooms[room.ID] == 0 {
+		cost++
+	}
+	if req.Department != "" && room.Department != "" && room.Department != req.Department {
+		cost++
+	}
+
+	// honour the teacher's preferences where possible
+	slot := s.problem.Slots[c.slot]
+	if avail, ok := s.problem.Availability[c.teacher]; ok {
+		if overlapsAny(avail.Disliked, slot) {
+			cost += preferenceWeight
+		}
+		if overlapsAny(avail.Preferred, slot) {
+			cost--
+		}
+	}
+	return cost
+}
+
+func gapsOn(slots []int, index []int, dayOf []timeslot.Day, day timeslot.Day) int {
+	return gapsWith(slots, index, dayOf, -1, day)
+}
+
+func gapsWith(slots []int, index []int, dayOf []timeslot.Day, extra int, day timeslot.Day) int {
+	lo, hi, n := -1, -1, 0
+	seen := map[int]bool{}
+	add := func(si int) {
+		if si < 0 || dayOf[si] != day || seen[index[si]] {
+			return
+		}
+		seen[index[si]] = true
+		idx := index[si]
+		if lo == -1 || idx < lo {
+			lo = idx
+		}
+		if idx > hi {
+			hi = idx
+		}
+		n++
+	}
+	for _, si := range slots {
+		add(si)
+	}
+	add(extra)
+	if n == 0 {
+		return 0
+	}
+	return hi - lo + 1 - n
+}
+
+func (s *solver) apply(li int, c candidate) {
+	l := s.lessons[li]
+	slot := s.problem.Slots[c.slot]
+	s.groupBusy[l.group] = append(s.groupBusy[l.group], slot)
+	s.teacherBusy[c.teacher] = append(s.teacherBusy[c.teacher], slot)
+	s.roomBusy[c.room] = append(s.roomBusy[c.room], slot)
+	s.groupSlots[l.group] = append(s.groupSlots[l.group], c.slot)
+	s.teacherSlots[c.teacher] = append(s.teacherSlots[c.teacher], c.slot)
+
+	key := [2]int64{l.group, l.subj}
+	if s.subjectDays[key] == nil {
+		s.subjectDays[key] = map[timeslot.Day]int{}
+	}
+	s.subjectDays[key][s.dayOf[c.slot]]++
+	if s.groupRooms[l.group] == nil {
+		s.groupRooms[l.group] = map[int64]int{}
+	}
+	s.groupRooms[l.group][c.room]++
+	if _, ok := s.reqTeacher[l.req]; !ok {
+		s.reqTeacher[l.req] = c.teacher
+	}
+	s.assigned[li] = c.slot
+	s.chosen[li] = c
+}
+
+func (s *solver) undo(li int, c candidate, ownsTeacher bool) {
+	l := s.lessons[li]
+	s.groupBusy[l.group] = s.groupBusy[l.group][:len(s.groupBusy[l.group])-1]
+	s.teacherBusy[c.teacher] = s.teacherBusy[c.teacher][:len(s.teacherBusy[c.teacher])-1]
+	s.roomBusy[c.room] = s.roomBusy[c.room][:len(s.roomBusy[c.room])-1]
+	s.groupSlots[l.group] = s.groupSlots[l.group][:len(s.groupSlots[l.group])-1]
+	s.teacherSlots[c.teacher] = s.teacherSlots[c.teacher][:len(s.teacherSlots[c.teacher])-1]
+	s.subjectDays[[2]int64{l.group, l.subj}][s.dayOf[c.slot]]--
+	s.groupRooms[l.group][c.room]--
+	if ownsTeacher {
+		delete(s.reqTeacher, l.req)
+	}
+	s.assigned[li] = -1
+}
+
+func (s *solver) backtrack(li int) bool {
+	if li == len(s.lessons) {
+		return true
+	}
+	s.steps++
+	if s.steps > s.opts.MaxSteps {
+		return false
+	}
+	cands := s.candidates(li)
+	if len(cands) > s.opts.Branching {
+		cands = cands[:s.opts.Branching]
+	}
+	for _, c := range cands {
+		_, hadTeacher := s.reqTeacher[s.lessons[li].req]
+		s.apply(li, c)
+		if s.backtrack(li + 1) {
+			return true
+		}
+		s.undo(li, c, !hadTeacher)
+		if s.steps > s.opts.MaxSteps {
+			return false
+		}
+	}
+	return false
+}
+
+func (s *solver) greedy() {
+	for li := range s.lessons {
+		if cands := s.candidates(li); len(cands) > 0 {
+			s.apply(li, cands[0])
+		}
+	}
+}
+
+func (s *solver) solution() Solution {
+	var sol Solution
+	missing := map[int]int{}
+	for li, l := range s.lessons {
+		if s.assigned[li] < 0 {
+			missing[l.req]++
+			continue
+		}
+		c := s.chosen[li]
+		sol.Assignments = append(sol.Assignments, Assignment{
+			GroupID:      l.group,
+			SubjectID:    l.subj,
+			RoomID:       c.room,
+			TeacherEmail: c.teacher,
+			Slot:         s.problem.Slots[c.slot],
+		})
+	}
+	for i, req := range s.problem.Requirements {
+		if missing[i] > 0 {
+			sol.Unplaced = append(sol.Unplaced, Unplaced{Requirement: req, Missing: missing[i]})
+		}
+	}
+	sort.SliceStable(sol.Assignments, func(a, b int) bool {
+		x, y := sol.Assignments[a], sol.Assignments[b]
+		if x.GroupID != y.GroupID {
+			return x.GroupID < y.GroupID
+		}
+		if x.Slot.Day != y.Slot.Day {
+			return x.Slot.Day < y.Slot.Day
+		}
+		return x.Slot.Start < y.Slot.Start
+	})
+	sol.Complete = len(sol.Unplaced) == 0
+	sol.Score = Evaluate(s.problem.Slots, sol.Assignments)
+	return sol
+}
+
+// Evaluate scores a set of assignments against the soft constraints.
+// Days with no slots in the grid are ignored for balance.
+func Evaluate(grid []timeslot.TimeSlot, assignments []Assignment) Score {
+	position := func(slot timeslot.TimeSlot) int {
+		idx := 0
+		for _, g := range grid {
+			if g.Day == slot.Day && g.Start < slot.Start {
+				idx++
+			}
+		}
+		return idx
+	}
+	workingDays := map[timeslot.Day]bool{}
+	for _, g := range grid {
+		workingDays[g.Day] = true
+	}
+
+	type dayKey struct {
+		owner string
+		day   timeslot.Day
+	}
+	positions := map[dayKey][]int{}
+	perDay := map[int64]map[timeslot.Day]int{}
+	subjectDay := map[[3]int64]int{}
+	for _, a := range assignments {
+		p := position(a.Slot)
+		gk := dayKey{owner: "g" + strconv.FormatInt(a.GroupID, 10), day: a.Slot.Day}
+		tk := dayKey{owner: "t" + a.TeacherEmail, day: a.Slot.Day}
+		positions[gk] = append(positions[gk], p)
+		positions[tk] = append(positions[tk], p)
+		if perDay[a.GroupID] == nil {
+			perDay[a.GroupID] = map[timeslot.Day]int{}
+		}
+		perDay[a.GroupID][a.Slot.Day]++
+		subjectDay[[3]int64{a.GroupID, a.SubjectID, int64(a.Slot.Day)}]++
+	}
+
+	var score Score
+	for _, ps := range positions {
+		lo, hi := ps[0], ps[0]
+		seen := map[int]bool{}
+		for _, p := range ps {
+			seen[p] = true
+			if p < lo {
+				lo = p
+			}
+			if p > hi {
+				hi = p
+			}
+		}
+		score.Gaps += hi - lo + 1 - len(seen)
+	}
+	for _, days := range perDay {
+		lo, hi := -1, 0
+		for day := range workingDays {
+			n := days[day]
+			if lo == -1 || n < lo {
+				lo = n
+			}
+			if n > hi {
+				hi = n
+			}
+		}
+		if lo >= 0 {
+			score.Imbalance += hi - lo
+		}
+	}
+	for _, n := range subjectDay {
+		if n > 1 {
+			score.Repeats += n - 1
+		}
+	}
+	score.Total = gapWeight*score.Gaps + imbalanceWeight*score.Imbalance + repeatWeight*score.Repeats
+	return score
+}
@@ -0,0 +1,184 @@
+package scheduler
+
+import (
+	"testing"
+
+	"github.com/nirajan1111/routiney/timeslot"
+)
+
+func TestSolveAvoidsDoubleBooking(t *testing.T) {
+	existing, _ := timeslot.Parse("SUN-16:15-17:55")
+	problem := Problem{
+		Slots: timeslot.DefaultGrid(),
+		Rooms: []Room{{ID: 1, Code: "A101"}, {ID: 2, Code: "A102"}},
+		Requirements: []Requirement{
+			{GroupID: 1, SubjectID: 10, Periods: 3, Teachers: []string{"ram@example.com"}},
+			{GroupID: 2, SubjectID: 10, Periods: 3, Teachers: []string{"ram@example.com"}},
+			{GroupID: 1, SubjectID: 11, Periods: 2, Teachers: []string{"sita@example.com", "hari@example.com"}},
+		},
+		Existing: []Booking{{GroupID: 3, RoomID: 1, TeacherEmail: "ram@example.com", Slot: existing}},
+	}
+
+	sol := Solve(problem, Options{})
+	if !sol.Complete {
+		t.Fatalf("expected a complete solution, unplaced: %+v", sol.Unplaced)
+	}
+	if len(sol.Assignments) != 8 {
+		t.Fatalf("expected 8 assignments, got %d", len(sol.Assignments))
+	}
+
+	for i, a := range sol.Assignments {
+		if a.TeacherEmail == "ram@example.com" && a.Slot.Overlaps(existing) {
+			t.Errorf("assignment %d double books the teacher against an existing lesson", i)
+		}
+		for _, b := range sol.Assignments[i+1:] {
+			if !a.Slot.Overlaps(b.Slot) {
+				continue
+			}
+			if a.GroupID == b.GroupID || a.RoomID == b.RoomID || a.TeacherEmail == b.TeacherEmail {
+				t.Errorf("overlapping assignments share a resource: %+v %+v", a, b)
+			}
+		}
+	}
+
+	teachers := map[int64]string{}
+	for _, a := range sol.Assignments {
+		if a.SubjectID != 11 {
+			continue
+		}
+		if prev, ok := teachers[a.GroupID]; ok && prev != a.TeacherEmail {
+			t.Errorf("requirement split between %s and %s", prev, a.TeacherEmail)
+		}
+		teachers[a.GroupID] = a.TeacherEmail
+	}
+}
+
+func TestSolveReportsUnplaced(t *testing.T) {
+	slot, _ := timeslot.Parse("SUN-16:15-17:55")
+	problem := Problem{
+		Slots:        []timeslot.TimeSlot{slot},
+		Rooms:        []Room{{ID: 1}},
+		Requirements: []Requirement{{GroupID: 1, SubjectID: 10, Periods: 2, Teachers: []string{"ram@example.com"}}},
+	}
+
+	sol := Solve(problem, Options{})
+	if sol.Complete {
+		t.Fatal("expected an incomplete solution")
+	}
+	if len(sol.Assignments) != 1 || len(sol.Unplaced) != 1 || sol.Unplaced[0].Missing != 1 {
+		t.Fatalf("unexpected solution: %+v", sol)
+	}
+}
+
+func TestSolveRespectsAvailability(t *testing.T) {
+	sunday, _ := timeslot.Parse("SUN-16:15-17:55")
+	monday, _ := timeslot.Parse("MON-16:15-17:55")
+	tuesday, _ := timeslot.Parse("TUE-16:15-17:55")
+	problem := Problem{
+		Slots: []timeslot.TimeSlot{sunday, monday, tuesday},
+		Rooms: []Room{{ID: 1}},
+		Requirements: []Requirement{
+			{GroupID: 1, SubjectID: 10, Periods: 1, Teachers: []string{"ram@example.com"}},
+		},
+		Availability: map[string]Availability{
+			"ram@example.com": {
+				Unavailable: []timeslot.TimeSlot{sunday},
+				Disliked:    []timeslot.TimeSlot{monday},
+			},
+		},
+	}
+
+	sol := Solve(problem, Options{})
+	if !sol.Complete || len(sol.Assignments) != 1 {
+		t.Fatalf("expected one assignment, got %+v", sol)
+	}
+	if got := sol.Assignments[0].Slot; got != tuesday {
+		t.Errorf("placed in %s, want %s", got, tuesday)
+	}
+}
+
+func TestSolveRespectsMaxPeriods(t *testing.T) {
+	problem := Problem{
+		Slots: timeslot.DefaultGrid(),
+		Rooms: []Room{{ID: 1}},
+		Requirements: []Requirement{
+			{GroupID: 1, SubjectID: 10, Periods: 3, Teachers: []string{"ram@example.com", "sita@example.com"}},
+			{GroupID: 2, SubjectID: 10, Periods: 3, Teachers: []string{"ram@example.com"}},
+		},
+		MaxPeriods: map[string]int{"ram@example.com": 4},
+	}
+
+	sol := Solve(problem, Options{})
+	load := map[string]int{}
+	for _, a := range sol.Assignments {
+		load[a.TeacherEmail]++
+	}
+	if load["ram@example.com"] > 4 {
+		t.Errorf("ram teaches %d periods, limit is 4", load["ram@example.com"])
+	}
+	if !sol.Complete {
+		t.Errorf("expected the other teacher to take group 1, unplaced: %+v", sol.Unplaced)
+	}
+}
+
+func TestSolveCountsSpanningBookings(t *testing.T) {
+	// an existing lab over both evening periods already takes two of
+	// ram's three periods
+	lab, _ := timeslot.Parse("SUN-16:15-19:35")
+	problem := Problem{
+		Slots: timeslot.DefaultGrid(),
+		Rooms: []Room{{ID: 1}, {ID: 2}},
+		Requirements: []Requirement{
+			{GroupID: 2, SubjectID: 10, Periods: 3, Teachers: []string{"ram@example.com"}},
+		},
+		Existing:   []Booking{{GroupID: 1, RoomID: 1, TeacherEmail: "ram@example.com", Slot: lab}},
+		MaxPeriods: map[string]int{"ram@example.com": 3},
+	}
+
+	sol := Solve(problem, Options{})
+	if len(sol.Assignments) != 1 {
+		t.Errorf("placed %d lessons for ram, want 1 next to the two period lab", len(sol.Assignments))
+	}
+}
+
+func TestSolveRespectsRoomCapacity(t *testing.T) {
+	slot, _ := timeslot.Parse("SUN-16:15-17:55")
+	problem := Problem{
+		Slots: []timeslot.TimeSlot{slot},
+		Rooms: []Room{{ID: 1, Capacity: 30}, {ID: 2, Capacity: 60}},
+		Requirements: []Requirement{
+			{GroupID: 1, SubjectID: 10, Periods: 1, Teachers: []string{"ram@example.com"}, Size: 48},
+		},
+	}
+
+	sol := Solve(problem, Options{})
+	if !sol.Complete || len(sol.Assignments) != 1 {
+		t.Fatalf("expected one assignment, got %+v", sol)
+	}
+	if sol.Assignments[0].RoomID != 2 {
+		t.Errorf("48 students placed in room %d", sol.Assignments[0].RoomID)
+	}
+}
+
+func TestSolveRespectsRoomRequirement(t *testing.T) {
+	slot, _ := timeslot.Parse("SUN-16:15-17:55")
+	problem := Problem{
+		Slots: []timeslot.TimeSlot{slot},
+		Rooms: []Room{
+			{ID: 1, Type: "lecture_hall", Features: []string{"projector"}},
+			{ID: 2, Type: "computer_lab"},
+			{ID: 3, Type: "computer_lab", Features: []string{"computers", "projector"}},
+		},
+		Requirements: []Requirement{
+			{GroupID: 1, SubjectID: 10, Periods: 1, Teachers: []string{"ram@example.com"}, RoomType: "computer_lab", Features: []string{"projector"}},
+		},
+	}
+
+	sol := Solve(problem, Options{})
+	if !sol.Complete || len(sol.Assignments) != 1 {
+		t.Fatalf("expected one assignment, got %+v", sol)
+	}
+	if sol.Assignments[0].RoomID != 3 {
+		t.Errorf("lesson placed in room %d", sol.Assignments[0].RoomID)
+	}
+}
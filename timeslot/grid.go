@@ -0,0 +1,150 @@
+package timeslot
+
+import "fmt"
+
+// Period is a named period within a day. No class can be scheduled in a
+// break.
+type Period struct {
+	Name  string
+	Start int32
+	End   int32
+	Break bool
+}
+
+// DefaultDays are the working days used when no grid has been configured.
+var DefaultDays = []Day{Sunday, Monday, Tuesday, Wednesday, Thursday, Friday}
+
+// DefaultPeriods are the evening periods used by the routine tables.
+var DefaultPeriods = []Period{
+	{Name: "1", Start: 16*60 + 15, End: 17*60 + 55},
+	{Name: "2", Start: 17*60 + 55, End: 19*60 + 35},
+}
+
+// Grid expands days and periods into the weekly list of slots, ordered by
+// day and then by start time.
+func Grid(days []Day, periods []Period) []TimeSlot {
+	slots := make([]TimeSlot, 0, len(days)*len(periods))
+	for _, day := range days {
+		for _, period := range periods {
+			slots = append(slots, TimeSlot{Day: day, Start: period.Start, End: period.End})
+		}
+	}
+	return slots
+}
+
+// DefaultGrid returns the slots of the default weekly grid.
+func DefaultGrid() []TimeSlot {
+	return Grid(DefaultDays, DefaultPeriods)
+}
+
+// Week is a weekly timetable grid: the working days and the periods of
+// each day, ordered by start time.
+type Week struct {
+	Days    []Day
+	Periods []Period
+}
+
+// DefaultWeek returns the grid used when none has been configured.
+func DefaultWeek() Week {
+	return Week{Days: DefaultDays, Periods: DefaultPeriods}
+}
+
+// Teaching returns the periods of the week that are not breaks.
+func (w Week) Teaching() []Period {
+	periods := make([]Period, 0, len(w.Periods))
+	for _, p := range w.Periods {
+		if !p.Break {
+			periods = append(periods, p)
+		}
+	}
+	return periods
+}
+
+// Slots returns the single-period slots of the week.
+func (w Week) Slots() []TimeSlot {
+	return Grid(w.Days, w.Teaching())
+}
+
+// HasDay reports whether day is a working day of the week.
+func (w Week) HasDay(day Day) bool {
+	for _, d := range w.Days {
+		if d == day {
+			return true
+		}
+	}
+	return false
+}
+
+// Span is like the package level Span over the week's teaching periods,
+// but also rejects days off and spans running through a break.
+func (w Week) Span(day Day, start int32, n int) (TimeSlot, error) {
+	if !w.HasDay(day) {
+		return TimeSlot{}, fmt.Errorf("%s is not a working day", day)
+	}
+	slot, err := Span(w.Teaching(), day, start, n)
+	if err != nil {
+		return TimeSlot{}, err
+	}
+	return slot, w.checkBreaks(slot)
+}
+
+// Check reports whether slot exists in the week: it must fall on a working
+// day, start and end on teaching period boundaries and not run through a
+// break.
+func (w Week) Check(slot TimeSlot) error {
+	if !w.HasDay(slot.Day) {
+		return fmt.Errorf("%s is not a working day", slot.Day)
+	}
+	var starts, ends bool
+	for _, p := range w.Teaching() {
+		starts = starts || p.Start == slot.Start
+		ends = ends || p.End == slot.End
+	}
+	if !starts || !ends {
+		return fmt.Errorf("%s-%s does not match the periods of the day", FormatClock(slot.Start), FormatClock(slot.End))
+	}
+	return w.checkBreaks(slot)
+}
+
+func (w Week) checkBreaks(slot TimeSlot) error {
+	for _, p := range w.Periods {
+		if p.Break && slot.Start < p.End && p.Start < slot.End {
+			return fmt.Errorf("%s-%s runs through the %s break", FormatClock(slot.Start), FormatClock(slot.End), p.Name)
+		}
+	}
+	return nil
+}
+
+// Span returns the slot of a session taking n consecutive periods on day,
+// starting with the period that begins at start. periods must be ordered
+// by start time.
+func Span(periods []Period, day Day, start int32, n int) (TimeSlot, error) {
+	if n < 1 {
+		return TimeSlot{}, fmt.Errorf("a session takes at least one period")
+	}
+	for i, p := range periods {
+		if p.Start != start {
+			continue
+		}
+		if i+n > len(periods) {
+			return TimeSlot{}, fmt.Errorf("only %d periods left in the day after %s", len(periods)-i, FormatClock(start))
+		}
+		return New(day, start, periods[i+n-1].End)
+	}
+	return TimeSlot{}, fmt.Errorf("no period starts at %s", FormatClock(start))
+}
+
+// Covers returns the index of the first period slot overlaps and the
+// number of periods it overlaps, or -1 and 0 when it overlaps none.
+func Covers(periods []Period, slot TimeSlot) (first, n int) {
+	first = -1
+	for i, p := range periods {
+		if slot.Start < p.End && p.Start < slot.End {
+			if first < 0 {
+				first = i
+			}
+			n++
+		}
+	}
+	return first, n
+}
@@ -0,0 +1,164 @@
+package timeslot
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Day is a day of the week as stored in schedules.day_of_week.
+// The numbering follows time.Weekday, so SUN is 0 and SAT is 6.
+type Day int16
+
+const (
+	Sunday Day = iota
+	Monday
+	Tuesday
+	Wednesday
+	Thursday
+	Friday
+	Saturday
+)
+
+var dayCodes = [...]string{"SUN", "MON", "TUE", "WED", "THU", "FRI", "SAT"}
+
+var dayNames = map[string]Day{
+	"SUN": Sunday, "SUNDAY": Sunday,
+	"MON": Monday, "MONDAY": Monday,
+	"TUE": Tuesday, "TUES": Tuesday, "TUESDAY": Tuesday,
+	"WED": Wednesday, "WEDNESDAY": Wednesday,
+	"THU": Thursday, "THUR": Thursday, "THURS": Thursday, "THURSDAY": Thursday,
+	"FRI": Friday, "FRIDAY": Friday,
+	"SAT": Saturday, "SATURDAY": Saturday,
+}
+
+// ParseDay accepts a three letter day code (SUN, MON, ...) or a full day
+// name in any case.
+func ParseDay(s string) (Day, error) {
+	day, ok := dayNames[strings.ToUpper(strings.TrimSpace(s))]
+	if !ok {
+		return 0, fmt.Errorf("invalid day %q", s)
+	}
+	return day, nil
+}
+
+// Valid reports whether d is between SUN and SAT.
+func (d Day) Valid() bool {
+	return d >= Sunday && d <= Saturday
+}
+
+// String returns the three letter code used in time slots.
+func (d Day) String() string {
+	if !d.Valid() {
+		return fmt.Sprintf("Day(%d)", int16(d))
+	}
+	return dayCodes[d]
+}
+
+// Weekday converts d to a time.Weekday.
+func (d Day) Weekday() time.Weekday {
+	return time.Weekday(d)
+}
+
+// ParseClock parses an "HH:MM" wall clock time into minutes since midnight.
+// "24:00" is accepted so that a slot may end at midnight.
+func ParseClock(s string) (int32, error) {
+	hh, mm, ok := strings.Cut(strings.TrimSpace(s), ":")
+	if !ok {
+		return 0, fmt.Errorf("invalid time %q: expected HH:MM", s)
+	}
+	hour, err := strconv.Atoi(hh)
+	if err != nil || len(hh) > 2 {
+		return 0, fmt.Errorf("invalid hour in %q", s)
+	}
+	minute, err := strconv.Atoi(mm)
+	if err != nil || len(mm) != 2 {
+		return 0, fmt.Errorf("invalid minute in %q", s)
+	}
+	if hour < 0 || minute < 0 || minute > 59 || hour > 24 || (hour == 24 && minute != 0) {
+		return 0, fmt.Errorf("time %q out of range", s)
+	}
+	return int32(hour*60 + minute), nil
+}
+
+// FormatClock formats minutes since midnight as "HH:MM".
+func FormatClock(minutes int32) string {
+	return fmt.Sprintf("%02d:%02d", minutes/60, minutes%60)
+}
+
+// TimeSlot is a weekly recurring interval on a single day. Start and End
+// are minutes since midnight and the interval is half open, so a slot
+// ending at 17:55 does not overlap one starting at 17:55.
+type TimeSlot struct {
+	Day   Day
+	Start int32
+	End   int32
+}
+
+// New builds a TimeSlot and validates it.
+func New(day Day, start, end int32) (TimeSlot, error) {
+	slot := TimeSlot{Day: day, Start: start, End: end}
+	if err := slot.Validate(); err != nil {
+		return TimeSlot{}, err
+	}
+	return slot, nil
+}
+
+// Parse parses a slot in the "DAY-HH:MM-HH:MM" format, e.g. "SUN-16:15-17:55".
+func Parse(s string) (TimeSlot, error) {
+	parts := strings.Split(strings.TrimSpace(s), "-")
+	if len(parts) != 3 {
+		return TimeSlot{}, fmt.Errorf("invalid time slot %q: expected DAY-HH:MM-HH:MM", s)
+	}
+	day, err := ParseDay(parts[0])
+	if err != nil {
+		return TimeSlot{}, fmt.Errorf("invalid time slot %q: %w", s, err)
+	}
+	start, err := ParseClock(parts[1])
+	if err != nil {
+		return TimeSlot{}, fmt.Errorf("invalid time slot %q: %w", s, err)
+	}
+	end, err := ParseClock(parts[2])
+	if err != nil {
+		return TimeSlot{}, fmt.Errorf("invalid time slot %q: %w", s, err)
+	}
+	return New(day, start, end)
+}
+
+// Validate checks that the day is known and that the slot has a positive
+// length within a single day.
+func (t TimeSlot) Validate() error {
+	if !t.Day.Valid() {
+		return fmt.Errorf("invalid day %d", t.Day)
+	}
+	if t.Start < 0 || t.End > 24*60 {
+		return fmt.Errorf("time slot must be within a single day")
+	}
+	if t.Start >= t.End {
+		return fmt.Errorf("time slot must end after it starts")
+	}
+	return nil
+}
+
+// String returns the canonical "DAY-HH:MM-HH:MM" form stored in
+// schedules.time_slot.
+func (t TimeSlot) String() string {
+	return t.Day.String() + "-" + FormatClock(t.Start) + "-" + FormatClock(t.End)
+}
+
+// Duration returns the length of the slot.
+func (t TimeSlot) Duration() time.Duration {
+	return time.Duration(t.End-t.Start) * time.Minute
+}
+
+// Overlaps reports whether the two slots share any time on the same day.
+func (t TimeSlot) Overlaps(other TimeSlot) bool {
+	return t.Day == other.Day && t.Start < other.End && other.Start < t.End
+}
+
+// Contains reports whether the wall clock time (minutes since midnight)
+// falls inside the slot.
+func (t TimeSlot) Contains(minute int32) bool {
+	return t.Start <= minute && minute < t.End
+}
@@ -0,0 +1,119 @@
+package timeslot
+
+import "testing"
+
+func TestParse(t *testing.T) {
+	slot, err := Parse("SUN-16:15-17:55")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if slot.Day != Sunday || slot.Start != 16*60+15 || slot.End != 17*60+55 {
+		t.Fatalf("unexpected slot: %+v", slot)
+	}
+	if slot.String() != "SUN-16:15-17:55" {
+		t.Fatalf("unexpected string: %s", slot.String())
+	}
+
+	normalized, err := Parse(" tuesday-9:00-10:40 ")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if normalized.String() != "TUE-09:00-10:40" {
+		t.Fatalf("unexpected string: %s", normalized.String())
+	}
+
+	for _, bad := range []string{"", "SUN", "SUN-16:15", "XYZ-16:15-17:55", "SUN-17:55-16:15", "SUN-16:15-16:15", "SUN-25:00-26:00", "SUN-16:5-17:55"} {
+		if _, err := Parse(bad); err == nil {
+			t.Errorf("expected error for %q", bad)
+		}
+	}
+}
+
+func TestOverlaps(t *testing.T) {
+	a, _ := Parse("SUN-16:15-17:55")
+	b, _ := Parse("SUN-17:00-18:00")
+	c, _ := Parse("SUN-17:55-19:35")
+	d, _ := Parse("MON-16:15-17:55")
+
+	if !a.Overlaps(b) || !b.Overlaps(a) {
+		t.Error("expected overlapping slots")
+	}
+	if a.Overlaps(c) {
+		t.Error("back to back slots must not overlap")
+	}
+	if a.Overlaps(d) {
+		t.Error("slots on different days must not overlap")
+	}
+}
+
+func TestSpan(t *testing.T) {
+	periods := []Period{
+		{Name: "1", Start: 9 * 60, End: 10 * 60},
+		{Name: "2", Start: 10 * 60, End: 11 * 60},
+		{Name: "3", Start: 11*60 + 30, End: 12*60 + 30},
+	}
+
+	slot, err := Span(periods, Monday, 10*60, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if slot.String() != "MON-10:00-12:30" {
+		t.Errorf("unexpected span: %s", slot)
+	}
+	if first, n := Covers(periods, slot); first != 1 || n != 2 {
+		t.Errorf("Covers = %d, %d", first, n)
+	}
+	if first, n := Covers(periods, TimeSlot{Day: Monday, Start: 13 * 60, End: 14 * 60}); first != -1 || n != 0 {
+		t.Errorf("Covers outside the grid = %d, %d", first, n)
+	}
+
+	for _, bad := range []struct {
+		start int32
+		n     int
+	}{{10 * 60, 3}, {10*60 + 15, 1}, {9 * 60, 0}} {
+		if _, err := Span(periods, Monday, bad.start, bad.n); err == nil {
+			t.Errorf("expected error for %d periods from %s", bad.n, FormatClock(bad.start))
+		}
+	}
+}
+
+func TestWeek(t *testing.T) {
+	week := Week{
+		Days: []Day{Sunday, Monday},
+		Periods: []Period{
+			{Name: "1", Start: 9 * 60, End: 10 * 60},
+			{Name: "2", Start: 10 * 60, End: 11 * 60},
+			{Name: "Lunch", Start: 11 * 60, End: 11*60 + 30, Break: true},
+			{Name: "3", Start: 11*60 + 30, End: 12*60 + 30},
+		},
+	}
+
+	if n := len(week.Slots()); n != 6 {
+		t.Errorf("expected 6 slots, got %d", n)
+	}
+	slot, err := week.Span(Monday, 9*60, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := week.Check(slot); err != nil {
+		t.Errorf("Check(%s): %v", slot, err)
+	}
+
+	for _, bad := range []string{
+		"TUE-09:00-10:00",
+		"SUN-09:15-10:00",
+		"SUN-10:00-12:30",
+		"MON-11:00-11:30",
+	} {
+		slot, err := Parse(bad)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := week.Check(slot); err == nil {
+			t.Errorf("expected %s to be rejected", bad)
+		}
+	}
+	if _, err := week.Span(Sunday, 10*60, 2); err == nil {
+		t.Error("expected a span through the break to be rejected")
+	}
+}
@@ -0,0 +1,56 @@
+package validation
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	db "github.com/nirajan1111/routiney/db/sqlc"
+	"github.com/nirajan1111/routiney/timeslot"
+)
+
+// GridQuerier reads timetable grids. Both *db.Store and the *db.Queries of a
+// transaction satisfy it.
+type GridQuerier interface {
+	GetGridForSection(ctx context.Context, groupID int32) (db.TimetableGrid, error)
+	ListGridPeriods(ctx context.Context, gridID int64) ([]db.GridPeriod, error)
+}
+
+// Week turns a stored grid and its periods into a timeslot.Week.
+func Week(grid db.TimetableGrid, periods []db.GridPeriod) timeslot.Week {
+	week := timeslot.Week{
+		Days:    make([]timeslot.Day, 0, len(grid.Days)),
+		Periods: make([]timeslot.Period, 0, len(periods)),
+	}
+	for _, d := range grid.Days {
+		week.Days = append(week.Days, timeslot.Day(d))
+	}
+	for _, p := range periods {
+		week.Periods = append(week.Periods, timeslot.Period{
+			Name:  p.Name,
+			Start: p.StartMinute,
+			End:   p.EndMinute,
+			Break: p.IsBreak,
+		})
+	}
+	return week
+}
+
+// GridFor returns the grid a section's classes must fit: the one set up for
+// its program, or the default grid. A zero groupID asks for the default
+// grid. When no grid has been set up at all the built-in evening grid is
+// used.
+func GridFor(ctx context.Context, q GridQuerier, groupID int64) (timeslot.Week, error) {
+	grid, err := q.GetGridForSection(ctx, int32(groupID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return timeslot.DefaultWeek(), nil
+	}
+	if err != nil {
+		return timeslot.Week{}, err
+	}
+	periods, err := q.ListGridPeriods(ctx, grid.ID)
+	if err != nil {
+		return timeslot.Week{}, err
+	}
+	return Week(grid, periods), nil
+}
@@ -0,0 +1,515 @@
+// Package validation checks a schedule against the rest of the routine
+// before it is written. Every path that creates or moves schedules (single
+// create and update, the generator's apply step, imports) goes through it, so
+// they all agree on what a conflict is.
+package validation
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/lib/pq"
+	db "github.com/nirajan1111/routiney/db/sqlc"
+	"github.com/nirajan1111/routiney/timeslot"
+)
+
+// Dimension names the resource two schedules clash on.
+type Dimension string
+
+const (
+	Room    Dimension = "room"
+	Teacher Dimension = "teacher"
+	Group   Dimension = "group"
+	// Unavailable is a clash with a slot the teacher marked unavailable,
+	// rather than with another schedule.
+	Unavailable Dimension = "teacher_unavailable"
+	// Workload means the schedule would take the teacher past their weekly
+	// maximum number of periods.
+	Workload Dimension = "teacher_workload"
+	// Capacity means the room has fewer seats than the section has
+	// students.
+	Capacity Dimension = "room_capacity"
+	// RoomRequirement means the room is not of the type, or lacks a feature,
+	// the subject needs for the session type.
+	RoomRequirement Dimension = "room_requirement"
+)
+
+// Querier is the subset of db.Querier the validator reads from. Both
+// *db.Store and the *db.Queries of a transaction satisfy it.
+type Querier interface {
+	ListScheduleConflicts(ctx context.Context, arg db.ListScheduleConflictsParams) ([]db.ListScheduleConflictsRow, error)
+	ListTeacherAvailabilityOverlaps(ctx context.Context, arg db.ListTeacherAvailabilityOverlapsParams) ([]db.TeacherAvailability, error)
+	GetTeacherWorkloadLimit(ctx context.Context, email string) (db.WorkloadLimit, error)
+	CountTeacherPeriods(ctx context.Context, arg db.CountTeacherPeriodsParams) (int64, error)
+	GetRoom(ctx context.Context, id int32) (db.Room, error)
+	GetStudentSectionSize(ctx context.Context, id int32) (int32, error)
+	GetSubGroup(ctx context.Context, id int64) (db.SubGroup, error)
+	GetSubjectRoomRequirement(ctx context.Context, arg db.GetSubjectRoomRequirementParams) (db.SubjectRoomRequirement, error)
+	ListRoomFeatures(ctx context.Context, roomID int32) ([]string, error)
+	GridQuerier
+}
+
+// Schedule is a schedule about to be written.
+type Schedule struct {
+	// ID is the schedule being updated, zero for a new one. It is never
+	// reported as conflicting with itself.
+	ID      int64
+	GroupID int64
+	// SubGroupID is the part of the group taught, zero for the whole
+	// group. Disjoint sub-groups may have classes at the same time.
+	SubGroupID int64
+	// CombinedGroupIDs are the other sections attending a combined class.
+	CombinedGroupIDs []int64
+	RoomID           int64
+	SubjectID        int64
+	TeacherEmail     string
+	Year             int32
+	// Term is the term of Year the class is in; zero means the first.
+	Term int16
+	// SessionType picks the subject's room requirement; empty means a
+	// lecture.
+	SessionType db.SessionType
+	Slot        timeslot.TimeSlot
+}
+
+// FromCreateParams builds the Schedule described by a create call.
+func FromCreateParams(arg db.CreateScheduleParams) Schedule {
+	return Schedule{
+		GroupID:          arg.GroupID.Int64,
+		SubGroupID:       arg.SubGroupID.Int64,
+		CombinedGroupIDs: arg.CombinedGroupIds,
+		RoomID:           arg.RoomID.Int64,
+		SubjectID:        arg.SubjectID.Int64,
+		TeacherEmail:     arg.TeacherEmail.String,
+		Year:             arg.Year,
+		Term:             arg.Term,
+		SessionType:      arg.SessionType,
+		Slot: timeslot.TimeSlot{
+			Day:   timeslot.Day(arg.DayOfWeek),
+			Start: arg.StartMinute,
+			End:   arg.EndMinute,
+		},
+	}
+}
+
+// FromSchedule builds the Schedule for a stored row, e.g. to re-check a
+// draft before it is published.
+func FromSchedule(s db.Schedule) Schedule {
+	return Schedule{
+		ID:               s.ID,
+		GroupID:          s.GroupID.Int64,
+		SubGroupID:       s.SubGroupID.Int64,
+		CombinedGroupIDs: s.CombinedGroupIds,
+		RoomID:           s.RoomID.Int64,
+		SubjectID:        s.SubjectID.Int64,
+		TeacherEmail:     s.TeacherEmail.String,
+		Year:             s.Year,
+		Term:             s.Term,
+		SessionType:      s.SessionType,
+		Slot: timeslot.TimeSlot{
+			Day:   timeslot.Day(s.DayOfWeek),
+			Start: s.StartMinute,
+			End:   s.EndMinute,
+		},
+	}
+}
+
+// Conflict is one existing schedule that clashes with the candidate, for
+// Unavailable the availability entry it falls in. For Workload, Limit is
+// the teacher's maximum periods per week; for Capacity it is the room's
+// seats and Size the section's headcount. For RoomRequirement, Requires
+// lists the room type and features the room is missing.
+type Conflict struct {
+	ScheduleID     int64     `json:"schedule_id,omitempty"`
+	AvailabilityID int64     `json:"availability_id,omitempty"`
+	Limit          int32     `json:"limit,omitempty"`
+	Size           int32     `json:"size,omitempty"`
+	Requires       string    `json:"requires,omitempty"`
+	Dimension      Dimension `json:"dimension"`
+	TimeSlot       string    `json:"time_slot"`
+}
+
+// Warning is a soft problem that does not stop the schedule from being
+// written.
+type Warning struct {
+	Kind           string `json:"kind"`
+	AvailabilityID int64  `json:"availability_id,omitempty"`
+	TimeSlot       string `json:"time_slot"`
+	Message        string `json:"message"`
+}
+
+// WarningDislikedSlot flags a class in a slot its teacher dislikes.
+const WarningDislikedSlot = "disliked_slot"
+
+// Result is the outcome of validating a schedule.
+type Result struct {
+	Conflicts []Conflict
+	Warnings  []Warning
+}
+
+// ConflictErr returns the conflicts as an error, or nil when there are none.
+func (r Result) ConflictErr() *ConflictError {
+	if len(r.Conflicts) == 0 {
+		return nil
+	}
+	return &ConflictError{Conflicts: r.Conflicts}
+}
+
+// ConflictError lists everything that stopped a schedule from being written.
+type ConflictError struct {
+	// Index is the position of the offending entry in a batch.
+	Index     int
+	Conflicts []Conflict
+}
+
+func (e *ConflictError) Error() string {
+	parts := make([]string, 0, len(e.Conflicts))
+	for _, c := range e.Conflicts {
+		switch c.Dimension {
+		case Unavailable:
+			parts = append(parts, fmt.Sprintf("teacher unavailable (%s)", c.TimeSlot))
+			continue
+		case Workload:
+			parts = append(parts, fmt.Sprintf("teacher already at the limit of %d periods per week", c.Limit))
+			continue
+		case Capacity:
+			parts = append(parts, fmt.Sprintf("room seats %d but the section has %d students", c.Limit, c.Size))
+			continue
+		case RoomRequirement:
+			parts = append(parts, fmt.Sprintf("room does not meet the subject's requirement (needs %s)", c.Requires))
+			continue
+		}
+		parts = append(parts, fmt.Sprintf("%s with schedule %d (%s)", c.Dimension, c.ScheduleID, c.TimeSlot))
+	}
+	return "schedule conflict: " + strings.Join(parts, ", ")
+}
+
+// Validator checks schedules against the ones already stored.
+type Validator struct {
+	q Querier
+}
+
+// New creates a Validator reading through q.
+func New(q Querier) *Validator {
+	return &Validator{q: q}
+}
+
+// Validate reports every stored schedule of the same term that overlaps s in
+// time and shares its room, teacher or one of its groups, counting the
+// sections of combined classes; classes for different sub-groups of a
+// group do not clash. A schedule clashing on more
+// than one dimension is listed once per dimension. Slots the teacher marked
+// unavailable are conflicts too, as are going over the teacher's weekly
+// maximum of periods, a room too small for the section and a room that does
+// not meet the subject's requirement; disliked slots only produce a warning.
+func (v *Validator) Validate(ctx context.Context, s Schedule) (Result, error) {
+	var res Result
+	if err := s.Slot.Validate(); err != nil {
+		return res, err
+	}
+	if s.Term == 0 {
+		s.Term = 1
+	}
+
+	rows, err := v.q.ListScheduleConflicts(ctx, db.ListScheduleConflictsParams{
+		Year:         s.Year,
+		Term:         s.Term,
+		DayOfWeek:    int16(s.Slot.Day),
+		StartMinute:  s.Slot.Start,
+		EndMinute:    s.Slot.End,
+		ExcludeID:    s.ID,
+		RoomID:       sql.NullInt64{Int64: s.RoomID, Valid: s.RoomID != 0},
+		TeacherEmail: sql.NullString{String: s.TeacherEmail, Valid: s.TeacherEmail != ""},
+		GroupIds:     s.groupIDs(),
+	})
+	if err != nil {
+		return res, err
+	}
+
+	for _, row := range rows {
+		if s.RoomID != 0 && row.RoomID.Valid && row.RoomID.Int64 == s.RoomID {
+			res.Conflicts = append(res.Conflicts, Conflict{ScheduleID: row.ID, Dimension: Room, TimeSlot: row.TimeSlot.String})
+		}
+		if s.TeacherEmail != "" && row.TeacherEmail.Valid && row.TeacherEmail.String == s.TeacherEmail {
+			res.Conflicts = append(res.Conflicts, Conflict{ScheduleID: row.ID, Dimension: Teacher, TimeSlot: row.TimeSlot.String})
+		}
+		if s.sharesGroup(row) {
+			res.Conflicts = append(res.Conflicts, Conflict{ScheduleID: row.ID, Dimension: Group, TimeSlot: row.TimeSlot.String})
+		}
+	}
+
+	for _, check := range []func(context.Context, Schedule, *Result) error{
+		v.checkAvailability,
+		v.checkWorkload,
+		v.checkCapacity,
+		v.checkRoomRequirement,
+	} {
+		if err := check(ctx, s, &res); err != nil {
+			return res, err
+		}
+	}
+	return res, nil
+}
+
+// groupIDs lists every section attending s.
+func (s Schedule) groupIDs() []int64 {
+	if s.GroupID == 0 {
+		return nil
+	}
+	return append([]int64{s.GroupID}, s.CombinedGroupIDs...)
+}
+
+// sharesGroup reports whether s and row have a section in common. Within
+// the section both are booked for, classes for different sub-groups do
+// not clash.
+func (s Schedule) sharesGroup(row db.ListScheduleConflictsRow) bool {
+	other := map[int64]bool{}
+	if row.GroupID.Valid {
+		other[row.GroupID.Int64] = true
+	}
+	for _, id := range row.CombinedGroupIds {
+		other[id] = true
+	}
+	for _, id := range s.groupIDs() {
+		if !other[id] {
+			continue
+		}
+		if id != s.GroupID || id != row.GroupID.Int64 || sharesStudents(s.SubGroupID, row.SubGroupID) {
+			return true
+		}
+	}
+	return false
+}
+
+// sharesStudents reports whether two classes of the same group have
+// students in common: always, unless both are for different sub-groups.
+func sharesStudents(subGroupID int64, other sql.NullInt64) bool {
+	return subGroupID == 0 || !other.Valid || other.Int64 == subGroupID
+}
+
+// checkAvailability reports slots the teacher marked unavailable as
+// conflicts and disliked ones as warnings.
+func (v *Validator) checkAvailability(ctx context.Context, s Schedule, res *Result) error {
+	if s.TeacherEmail == "" {
+		return nil
+	}
+	availability, err := v.q.ListTeacherAvailabilityOverlaps(ctx, db.ListTeacherAvailabilityOverlapsParams{
+		TeacherEmail: s.TeacherEmail,
+		DayOfWeek:    int16(s.Slot.Day),
+		StartMinute:  s.Slot.Start,
+		EndMinute:    s.Slot.End,
+	})
+	if err != nil {
+		return err
+	}
+	for _, a := range availability {
+		slot := timeslot.TimeSlot{Day: timeslot.Day(a.DayOfWeek), Start: a.StartMinute, End: a.EndMinute}.String()
+		switch a.Kind {
+		case db.AvailabilityKindUnavailable:
+			res.Conflicts = append(res.Conflicts, Conflict{AvailabilityID: a.ID, Dimension: Unavailable, TimeSlot: slot})
+		case db.AvailabilityKindDisliked:
+			res.Warnings = append(res.Warnings, Warning{
+				Kind:           WarningDislikedSlot,
+				AvailabilityID: a.ID,
+				TimeSlot:       slot,
+				Message:        fmt.Sprintf("%s prefers not to teach during %s", s.TeacherEmail, slot),
+			})
+		}
+	}
+	return nil
+}
+
+// checkWorkload reports a schedule that takes the teacher past their
+// weekly maximum of periods. A class spanning several periods of its
+// section's grid counts each of them, as schedule_periods does for the
+// classes already stored.
+func (v *Validator) checkWorkload(ctx context.Context, s Schedule, res *Result) error {
+	if s.TeacherEmail == "" {
+		return nil
+	}
+	limit, err := v.q.GetTeacherWorkloadLimit(ctx, s.TeacherEmail)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if !limit.MaxPeriods.Valid {
+		return nil
+	}
+	periods, err := v.q.CountTeacherPeriods(ctx, db.CountTeacherPeriodsParams{
+		TeacherEmail: sql.NullString{String: s.TeacherEmail, Valid: true},
+		Year:         s.Year,
+		Term:         s.Term,
+		ExcludeID:    s.ID,
+	})
+	if err != nil {
+		return err
+	}
+	week, err := GridFor(ctx, v.q, s.GroupID)
+	if err != nil {
+		return err
+	}
+	_, n := timeslot.Covers(week.Teaching(), s.Slot)
+	if periods+int64(max(n, 1)) > int64(limit.MaxPeriods.Int32) {
+		res.Conflicts = append(res.Conflicts, Conflict{Limit: limit.MaxPeriods.Int32, Dimension: Workload, TimeSlot: s.Slot.String()})
+	}
+	return nil
+}
+
+// checkCapacity reports a room with fewer seats than the section, or the
+// sub-group when it has a size of its own, has students. A combined class
+// needs seats for all of its sections together. Rooms or sections of
+// unknown size are not checked.
+func (v *Validator) checkCapacity(ctx context.Context, s Schedule, res *Result) error {
+	if s.RoomID == 0 || s.GroupID == 0 {
+		return nil
+	}
+	room, err := v.q.GetRoom(ctx, int32(s.RoomID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if !room.Capacity.Valid {
+		return nil
+	}
+	var size int32
+	if s.SubGroupID != 0 {
+		subGroup, err := v.q.GetSubGroup(ctx, s.SubGroupID)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return err
+		}
+		size = subGroup.Size.Int32
+	}
+	if size == 0 {
+		for _, id := range s.groupIDs() {
+			n, err := v.q.GetStudentSectionSize(ctx, int32(id))
+			if errors.Is(err, sql.ErrNoRows) {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+			size += n
+		}
+	}
+	if size > room.Capacity.Int32 {
+		res.Conflicts = append(res.Conflicts, Conflict{Limit: room.Capacity.Int32, Size: size, Dimension: Capacity, TimeSlot: s.Slot.String()})
+	}
+	return nil
+}
+
+// checkRoomRequirement reports a room that is not of the type, or lacks a
+// feature, the subject requires for the session type.
+func (v *Validator) checkRoomRequirement(ctx context.Context, s Schedule, res *Result) error {
+	if s.RoomID == 0 || s.SubjectID == 0 {
+		return nil
+	}
+	sessionType := s.SessionType
+	if sessionType == "" {
+		sessionType = db.SessionTypeLecture
+	}
+	requirement, err := v.q.GetSubjectRoomRequirement(ctx, db.GetSubjectRoomRequirementParams{
+		SubjectID:   s.SubjectID,
+		SessionType: sessionType,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	room, err := v.q.GetRoom(ctx, int32(s.RoomID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	var missing []string
+	if requirement.RoomType.Valid && room.RoomType != requirement.RoomType.RoomType {
+		missing = append(missing, string(requirement.RoomType.RoomType))
+	}
+	if len(requirement.Features) > 0 {
+		features, err := v.q.ListRoomFeatures(ctx, room.ID)
+		if err != nil {
+			return err
+		}
+		has := make(map[string]bool, len(features))
+		for _, f := range features {
+			has[f] = true
+		}
+		for _, f := range requirement.Features {
+			if !has[f] {
+				missing = append(missing, f)
+			}
+		}
+	}
+	if len(missing) > 0 {
+		res.Conflicts = append(res.Conflicts, Conflict{Requires: strings.Join(missing, ", "), Dimension: RoomRequirement, TimeSlot: s.Slot.String()})
+	}
+	return nil
+}
+
+// Problem is a stored schedule that does not pass validation.
+type Problem struct {
+	ScheduleID int64      `json:"schedule_id"`
+	TimeSlot   string     `json:"time_slot"`
+	Conflicts  []Conflict `json:"conflicts"`
+}
+
+// ValidateAll re-checks stored schedules against each other and everything
+// else in their year, returning one Problem per failing schedule.
+func (v *Validator) ValidateAll(ctx context.Context, schedules []db.Schedule) ([]Problem, error) {
+	problems := []Problem{}
+	for _, s := range schedules {
+		res, err := v.Validate(ctx, FromSchedule(s))
+		if err != nil {
+			return nil, fmt.Errorf("schedule %d: %w", s.ID, err)
+		}
+		if len(res.Conflicts) > 0 {
+			problems = append(problems, Problem{ScheduleID: s.ID, TimeSlot: s.TimeSlot.String, Conflicts: res.Conflicts})
+		}
+	}
+	return problems, nil
+}
+
+// CreateSchedules validates and inserts each entry in turn, stopping at the
+// first one that conflicts. Entries are checked against the ones inserted
+// before them, so q should belong to a transaction for the batch to be all or
+// nothing.
+func CreateSchedules(ctx context.Context, q *db.Queries, args []db.CreateScheduleParams) ([]db.Schedule, error) {
+	v := New(q)
+	schedules := make([]db.Schedule, 0, len(args))
+	for i, arg := range args {
+		res, err := v.Validate(ctx, FromCreateParams(arg))
+		if err != nil {
+			return nil, fmt.Errorf("entry %d: %w", i, err)
+		}
+		if conflictErr := res.ConflictErr(); conflictErr != nil {
+			conflictErr.Index = i
+			return nil, conflictErr
+		}
+
+		schedule, err := q.CreateSchedule(ctx, arg)
+		if err != nil {
+			return nil, err
+		}
+		schedules = append(schedules, schedule)
+	}
+	return schedules, nil
+}
+
+// IsExclusionViolation reports whether err comes from one of the schedules
+// exclusion constraints, i.e. a conflicting write slipped in between the
+// check and the insert.
+func IsExclusionViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "23P01"
+}
@@ -0,0 +1,386 @@
+package validation
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"testing"
+
+	db "github.com/nirajan1111/routiney/db/sqlc"
+	"github.com/nirajan1111/routiney/timeslot"
+)
+
+type fakeQuerier struct {
+	arg          db.ListScheduleConflictsParams
+	rows         []db.ListScheduleConflictsRow
+	availability []db.TeacherAvailability
+	limit        *db.WorkloadLimit
+	periods      int64
+	room         *db.Room
+	size         int32
+	requirement  *db.SubjectRoomRequirement
+	features     []string
+	subGroup     *db.SubGroup
+	grid         *db.TimetableGrid
+	gridPeriods  []db.GridPeriod
+}
+
+func (f *fakeQuerier) ListScheduleConflicts(ctx context.Context, arg db.ListScheduleConflictsParams) ([]db.ListScheduleConflictsRow, error) {
+	f.arg = arg
+	return f.rows, nil
+}
+
+func (f *fakeQuerier) ListTeacherAvailabilityOverlaps(ctx context.Context, arg db.ListTeacherAvailabilityOverlapsParams) ([]db.TeacherAvailability, error) {
+	return f.availability, nil
+}
+
+func (f *fakeQuerier) GetTeacherWorkloadLimit(ctx context.Context, email string) (db.WorkloadLimit, error) {
+	if f.limit == nil {
+		return db.WorkloadLimit{}, sql.ErrNoRows
+	}
+	return *f.limit, nil
+}
+
+func (f *fakeQuerier) CountTeacherPeriods(ctx context.Context, arg db.CountTeacherPeriodsParams) (int64, error) {
+	return f.periods, nil
+}
+
+func (f *fakeQuerier) GetGridForSection(ctx context.Context, groupID int32) (db.TimetableGrid, error) {
+	if f.grid == nil {
+		return db.TimetableGrid{}, sql.ErrNoRows
+	}
+	return *f.grid, nil
+}
+
+func (f *fakeQuerier) ListGridPeriods(ctx context.Context, gridID int64) ([]db.GridPeriod, error) {
+	return f.gridPeriods, nil
+}
+
+func (f *fakeQuerier) GetRoom(ctx context.Context, id int32) (db.Room, error) {
+	if f.room == nil {
+		return db.Room{}, sql.ErrNoRows
+	}
+	return *f.room, nil
+}
+
+func (f *fakeQuerier) GetStudentSectionSize(ctx context.Context, id int32) (int32, error) {
+	return f.size, nil
+}
+
+func (f *fakeQuerier) GetSubGroup(ctx context.Context, id int64) (db.SubGroup, error) {
+	if f.subGroup == nil {
+		return db.SubGroup{}, sql.ErrNoRows
+	}
+	return *f.subGroup, nil
+}
+
+func (f *fakeQuerier) GetSubjectRoomRequirement(ctx context.Context, arg db.GetSubjectRoomRequirementParams) (db.SubjectRoomRequirement, error) {
+	if f.requirement == nil || f.requirement.SessionType != arg.SessionType {
+		return db.SubjectRoomRequirement{}, sql.ErrNoRows
+	}
+	return *f.requirement, nil
+}
+
+func (f *fakeQuerier) ListRoomFeatures(ctx context.Context, roomID int32) ([]string, error) {
+	return f.features, nil
+}
+
+func TestValidateReportsDimensions(t *testing.T) {
+	q := &fakeQuerier{rows: []db.ListScheduleConflictsRow{
+		{
+			ID:           7,
+			TimeSlot:     sql.NullString{String: "SUN-16:15-17:55", Valid: true},
+			RoomID:       sql.NullInt64{Int64: 1, Valid: true},
+			TeacherEmail: sql.NullString{String: "ram@example.com", Valid: true},
+			GroupID:      sql.NullInt64{Int64: 9, Valid: true},
+		},
+		{
+			ID:       8,
+			TimeSlot: sql.NullString{String: "SUN-17:00-18:00", Valid: true},
+			RoomID:   sql.NullInt64{Int64: 2, Valid: true},
+			GroupID:  sql.NullInt64{Int64: 3, Valid: true},
+		},
+	}}
+	slot, _ := timeslot.Parse("SUN-16:15-17:55")
+
+	res, err := New(q).Validate(context.Background(), Schedule{
+		ID:           5,
+		GroupID:      3,
+		RoomID:       1,
+		TeacherEmail: "ram@example.com",
+		Year:         2081,
+		Term:         2,
+		Slot:         slot,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if q.arg.Year != 2081 || q.arg.Term != 2 || q.arg.ExcludeID != 5 {
+		t.Errorf("query not scoped to term and own id: %+v", q.arg)
+	}
+
+	want := []Conflict{
+		{ScheduleID: 7, Dimension: Room, TimeSlot: "SUN-16:15-17:55"},
+		{ScheduleID: 7, Dimension: Teacher, TimeSlot: "SUN-16:15-17:55"},
+		{ScheduleID: 8, Dimension: Group, TimeSlot: "SUN-17:00-18:00"},
+	}
+	if len(res.Conflicts) != len(want) {
+		t.Fatalf("got %+v, want %+v", res.Conflicts, want)
+	}
+	for i := range want {
+		if res.Conflicts[i] != want[i] {
+			t.Errorf("conflict %d = %+v, want %+v", i, res.Conflicts[i], want[i])
+		}
+	}
+	if res.ConflictErr() == nil {
+		t.Error("expected a conflict error")
+	}
+}
+
+func TestValidateAvailability(t *testing.T) {
+	q := &fakeQuerier{availability: []db.TeacherAvailability{
+		{ID: 1, Kind: db.AvailabilityKindUnavailable, DayOfWeek: 0, StartMinute: 16 * 60, EndMinute: 17 * 60},
+		{ID: 2, Kind: db.AvailabilityKindDisliked, DayOfWeek: 0, StartMinute: 17 * 60, EndMinute: 18 * 60},
+		{ID: 3, Kind: db.AvailabilityKindPreferred, DayOfWeek: 0, StartMinute: 16 * 60, EndMinute: 18 * 60},
+	}}
+	slot, _ := timeslot.Parse("SUN-16:15-17:55")
+
+	res, err := New(q).Validate(context.Background(), Schedule{TeacherEmail: "ram@example.com", Year: 2081, Slot: slot})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Conflicts) != 1 || res.Conflicts[0].Dimension != Unavailable || res.Conflicts[0].TimeSlot != "SUN-16:00-17:00" {
+		t.Errorf("conflicts = %+v", res.Conflicts)
+	}
+	if len(res.Warnings) != 1 || res.Warnings[0].AvailabilityID != 2 {
+		t.Errorf("warnings = %+v", res.Warnings)
+	}
+}
+
+func TestValidateWorkloadLimit(t *testing.T) {
+	q := &fakeQuerier{
+		limit:   &db.WorkloadLimit{MaxPeriods: sql.NullInt32{Int32: 12, Valid: true}},
+		periods: 11,
+	}
+	slot, _ := timeslot.Parse("SUN-16:15-17:55")
+	s := Schedule{TeacherEmail: "ram@example.com", Year: 2081, Slot: slot}
+
+	res, err := New(q).Validate(context.Background(), s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Conflicts) != 0 {
+		t.Fatalf("twelfth period rejected: %+v", res.Conflicts)
+	}
+
+	q.periods = 12
+	res, err = New(q).Validate(context.Background(), s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Conflicts) != 1 || res.Conflicts[0].Dimension != Workload || res.Conflicts[0].Limit != 12 {
+		t.Errorf("conflicts = %+v", res.Conflicts)
+	}
+}
+
+func TestValidateWorkloadSpanningSession(t *testing.T) {
+	q := &fakeQuerier{
+		limit:   &db.WorkloadLimit{MaxPeriods: sql.NullInt32{Int32: 12, Valid: true}},
+		periods: 10,
+		grid:    &db.TimetableGrid{ID: 1, Days: []int32{0, 1, 2, 3, 4, 5}},
+		gridPeriods: []db.GridPeriod{
+			{Name: "1", StartMinute: 7 * 60, EndMinute: 8 * 60},
+			{Name: "2", StartMinute: 8 * 60, EndMinute: 9 * 60},
+			{Name: "3", StartMinute: 9 * 60, EndMinute: 10 * 60},
+		},
+	}
+	single, _ := timeslot.Parse("SUN-07:00-08:00")
+	res, err := New(q).Validate(context.Background(), Schedule{TeacherEmail: "ram@example.com", Year: 2081, Slot: single})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Conflicts) != 0 {
+		t.Fatalf("eleventh period rejected: %+v", res.Conflicts)
+	}
+
+	// a three period lab is periods 11 to 13, one over the limit
+	lab, _ := timeslot.Parse("SUN-07:00-10:00")
+	res, err = New(q).Validate(context.Background(), Schedule{TeacherEmail: "ram@example.com", Year: 2081, Slot: lab})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Conflicts) != 1 || res.Conflicts[0].Dimension != Workload {
+		t.Errorf("conflicts = %+v, want the lab over the workload limit", res.Conflicts)
+	}
+}
+
+func TestValidateRoomCapacity(t *testing.T) {
+	q := &fakeQuerier{
+		room: &db.Room{ID: 1, Capacity: sql.NullInt32{Int32: 30, Valid: true}},
+		size: 48,
+	}
+	slot, _ := timeslot.Parse("SUN-16:15-17:55")
+	s := Schedule{GroupID: 3, RoomID: 1, Year: 2081, Slot: slot}
+
+	res, err := New(q).Validate(context.Background(), s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Conflict{Limit: 30, Size: 48, Dimension: Capacity, TimeSlot: "SUN-16:15-17:55"}
+	if len(res.Conflicts) != 1 || res.Conflicts[0] != want {
+		t.Errorf("conflicts = %+v, want %+v", res.Conflicts, want)
+	}
+
+	q.room.Capacity = sql.NullInt32{}
+	res, err = New(q).Validate(context.Background(), s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Conflicts) != 0 {
+		t.Errorf("room of unknown capacity rejected: %+v", res.Conflicts)
+	}
+}
+
+func TestValidateRoomRequirement(t *testing.T) {
+	q := &fakeQuerier{
+		room: &db.Room{ID: 1, RoomType: db.RoomTypeLectureHall},
+		requirement: &db.SubjectRoomRequirement{
+			SubjectID:   4,
+			SessionType: db.SessionTypeLab,
+			RoomType:    db.NullRoomType{RoomType: db.RoomTypeComputerLab, Valid: true},
+			Features:    []string{"computers", "projector"},
+		},
+		features: []string{"projector"},
+	}
+	slot, _ := timeslot.Parse("SUN-16:15-17:55")
+	s := Schedule{RoomID: 1, SubjectID: 4, Year: 2081, SessionType: db.SessionTypeLab, Slot: slot}
+
+	res, err := New(q).Validate(context.Background(), s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Conflict{Requires: "computer_lab, computers", Dimension: RoomRequirement, TimeSlot: "SUN-16:15-17:55"}
+	if len(res.Conflicts) != 1 || res.Conflicts[0] != want {
+		t.Errorf("conflicts = %+v, want %+v", res.Conflicts, want)
+	}
+
+	q.room.RoomType = db.RoomTypeComputerLab
+	q.features = []string{"computers", "projector"}
+	res, err = New(q).Validate(context.Background(), s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Conflicts) != 0 {
+		t.Errorf("suitable room rejected: %+v", res.Conflicts)
+	}
+
+	s.SessionType = ""
+	q.room.RoomType = db.RoomTypeLectureHall
+	res, err = New(q).Validate(context.Background(), s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Conflicts) != 0 {
+		t.Errorf("lab requirement applied to a lecture: %+v", res.Conflicts)
+	}
+}
+
+func TestValidateSubGroups(t *testing.T) {
+	slot, _ := timeslot.Parse("SUN-16:15-17:55")
+	q := &fakeQuerier{rows: []db.ListScheduleConflictsRow{
+		{
+			ID:         7,
+			TimeSlot:   sql.NullString{String: "SUN-16:15-17:55", Valid: true},
+			GroupID:    sql.NullInt64{Int64: 3, Valid: true},
+			SubGroupID: sql.NullInt64{Int64: 1, Valid: true},
+		},
+	}}
+
+	tests := []struct {
+		subGroupID int64
+		clash      bool
+	}{
+		{subGroupID: 2, clash: false},
+		{subGroupID: 1, clash: true},
+		{subGroupID: 0, clash: true},
+	}
+	for _, tt := range tests {
+		res, err := New(q).Validate(context.Background(), Schedule{GroupID: 3, SubGroupID: tt.subGroupID, Year: 2081, Slot: slot})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := len(res.Conflicts) == 1 && res.Conflicts[0].Dimension == Group; got != tt.clash {
+			t.Errorf("sub-group %d: conflicts = %+v", tt.subGroupID, res.Conflicts)
+		}
+	}
+
+	// a whole-section class already there blocks every sub-group
+	q.rows[0].SubGroupID = sql.NullInt64{}
+	res, err := New(q).Validate(context.Background(), Schedule{GroupID: 3, SubGroupID: 2, Year: 2081, Slot: slot})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Conflicts) != 1 {
+		t.Errorf("sub-group class next to a whole-section one: %+v", res.Conflicts)
+	}
+}
+
+func TestValidateCombinedClass(t *testing.T) {
+	slot, _ := timeslot.Parse("SUN-16:15-17:55")
+	q := &fakeQuerier{rows: []db.ListScheduleConflictsRow{
+		{
+			ID:         7,
+			TimeSlot:   sql.NullString{String: "SUN-16:15-17:55", Valid: true},
+			GroupID:    sql.NullInt64{Int64: 3, Valid: true},
+			SubGroupID: sql.NullInt64{Int64: 1, Valid: true},
+		},
+		{
+			ID:               8,
+			TimeSlot:         sql.NullString{String: "SUN-16:15-17:55", Valid: true},
+			GroupID:          sql.NullInt64{Int64: 5, Valid: true},
+			CombinedGroupIds: []int64{6},
+		},
+	}}
+
+	tests := []struct {
+		name  string
+		s     Schedule
+		clash []int64
+	}{
+		{name: "joins a section's own class", s: Schedule{GroupID: 4, CombinedGroupIDs: []int64{6}}, clash: []int64{8}},
+		{name: "combines a section busy with a sub-group", s: Schedule{GroupID: 4, CombinedGroupIDs: []int64{3}}, clash: []int64{7}},
+		{name: "other sub-group of the host section", s: Schedule{GroupID: 3, SubGroupID: 2}, clash: nil},
+		{name: "unrelated sections", s: Schedule{GroupID: 4, CombinedGroupIDs: []int64{9}}, clash: nil},
+	}
+	for _, tt := range tests {
+		tt.s.Year, tt.s.Slot = 2081, slot
+		res, err := New(q).Validate(context.Background(), tt.s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got []int64
+		for _, c := range res.Conflicts {
+			got = append(got, c.ScheduleID)
+		}
+		if fmt.Sprint(got) != fmt.Sprint(tt.clash) {
+			t.Errorf("%s: clashes with %v, want %v", tt.name, got, tt.clash)
+		}
+	}
+	if ids := q.arg.GroupIds; len(ids) != 2 || ids[0] != 4 || ids[1] != 9 {
+		t.Errorf("queried groups %v, want the host section and the combined ones", ids)
+	}
+
+	// the room has to seat every section together
+	q = &fakeQuerier{
+		room: &db.Room{ID: 1, Capacity: sql.NullInt32{Int32: 80, Valid: true}},
+		size: 48,
+	}
+	res, err := New(q).Validate(context.Background(), Schedule{GroupID: 3, CombinedGroupIDs: []int64{4}, RoomID: 1, Year: 2081, Slot: slot})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Conflict{Limit: 80, Size: 96, Dimension: Capacity, TimeSlot: "SUN-16:15-17:55"}
+	if len(res.Conflicts) != 1 || res.Conflicts[0] != want {
+		t.Errorf("conflicts = %+v, want %+v", res.Conflicts, want)
+	}
+}